@@ -15,12 +15,14 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
 
 	"github.com/dundee/gdu/v5/build"
 	"github.com/dundee/gdu/v5/internal/common"
 	"github.com/dundee/gdu/v5/pkg/analyze"
 	"github.com/dundee/gdu/v5/pkg/device"
 	gfs "github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/theme"
 	"github.com/dundee/gdu/v5/pkg/timefilter"
 	"github.com/dundee/gdu/v5/report"
 	"github.com/dundee/gdu/v5/stdout"
@@ -33,6 +35,7 @@ type UI interface {
 	AnalyzePath(path string, parentDir gfs.Item) error
 	ReadAnalysis(input io.Reader) error
 	ReadFromStorage(storagePath, path string) error
+	ShowAnalysis(dir gfs.Item) error
 	SetIgnoreDirPaths(paths []string)
 	SetIgnoreDirPatterns(paths []string) error
 	SetIgnoreFromFile(ignoreFile string) error
@@ -48,24 +51,38 @@ type UI interface {
 
 // Flags define flags accepted by Run
 type Flags struct {
-	Style              Style    `yaml:"style"`
-	Sorting            Sorting  `yaml:"sorting"`
+	Style         Style                `yaml:"style"`
+	Themes        map[string]yaml.Node `yaml:"themes"`
+	Sorting       Sorting              `yaml:"sorting"`
+	Bookmarks     map[string]string    `yaml:"bookmarks"`
+	Keymap        map[string]Keys      `yaml:"keymap"`
+	CustomActions []CustomAction       `yaml:"custom-actions"`
+
 	CfgFile            string   `yaml:"-"`
 	LogFile            string   `yaml:"log-file"`
 	InputFile          string   `yaml:"input-file"`
+	InputFormat        string   `yaml:"input-format"`
 	OutputFile         string   `yaml:"output-file"`
+	OutputFormat       string   `yaml:"output-format"`
+	MinSize            string   `yaml:"min-size"`
+	Theme              string   `yaml:"theme"`
 	IgnoreFromFile     string   `yaml:"ignore-from-file"`
 	StoragePath        string   `yaml:"storage-path"`
+	StorageBackend     string   `yaml:"storage-backend"`
 	IgnoreDirs         []string `yaml:"ignore-dirs"`
 	IgnoreDirPatterns  []string `yaml:"ignore-dir-patterns"`
 	MaxCores           int      `yaml:"max-cores"`
 	Top                int      `yaml:"top"`
+	MaxDepth           int      `yaml:"max-depth"`
 	SequentialScanning bool     `yaml:"sequential-scanning"`
 	ShowDisks          bool     `yaml:"-"`
 	ShowApparentSize   bool     `yaml:"show-apparent-size"`
 	ShowRelativeSize   bool     `yaml:"show-relative-size"`
 	ShowAnnexedSize    bool     `yaml:"show-annexed-size"`
 	ShowVersion        bool     `yaml:"-"`
+	Diff               bool     `yaml:"-"`
+	Merge              bool     `yaml:"-"`
+	MergeOverlay       bool     `yaml:"-"`
 	ShowItemCount      bool     `yaml:"show-item-count"`
 	ShowMTime          bool     `yaml:"show-mtime"`
 	NoColor            bool     `yaml:"no-color"`
@@ -82,6 +99,14 @@ type Flags struct {
 	ConstGC            bool     `yaml:"const-gc"`
 	UseStorage         bool     `yaml:"use-storage"`
 	ReadFromStorage    bool     `yaml:"read-from-storage"`
+	StorageList        bool     `yaml:"-"`
+	StorageInfo        bool     `yaml:"-"`
+	StorageGC          bool     `yaml:"-"`
+	StorageDelete      string   `yaml:"-"`
+	StorageVerify      bool     `yaml:"-"`
+	SnapshotName       string   `yaml:"-"`
+	SnapshotList       bool     `yaml:"-"`
+	SnapshotPrune      int      `yaml:"-"`
 	Summarize          bool     `yaml:"summarize"`
 	UseSIPrefix        bool     `yaml:"use-si-prefix"`
 	NoPrefix           bool     `yaml:"no-prefix"`
@@ -91,12 +116,14 @@ type Flags struct {
 	ChangeCwd          bool     `yaml:"change-cwd"`
 	DeleteInBackground bool     `yaml:"delete-in-background"`
 	DeleteInParallel   bool     `yaml:"delete-in-parallel"`
+	DeleteToTrash      bool     `yaml:"delete-to-trash"`
 	Since              string   `yaml:"since"`
 	Until              string   `yaml:"until"`
 	MaxAge             string   `yaml:"max-age"`
 	MinAge             string   `yaml:"min-age"`
 	ArchiveBrowsing    bool     `yaml:"archive-browsing"`
 	CollapsePath       bool     `yaml:"collapse-path"`
+	DirsOnly           bool     `yaml:"dirs-only"`
 }
 
 // ShouldRunInNonInteractiveMode checks if the application should run in non-interactive mode
@@ -169,6 +196,8 @@ type App struct {
 	PathChecker func(string) (fs.FileInfo, error)
 	Args        []string
 	Istty       bool
+
+	atomicOutput *report.AtomicFile
 }
 
 func init() {
@@ -179,6 +208,9 @@ func init() {
 func (a *App) Run() error {
 	var ui UI
 
+	// partially written output must not replace the previous one
+	defer a.discardOutput()
+
 	if a.Flags.ShowVersion {
 		fmt.Fprintln(a.Writer, "Version:\t", build.Version)
 		fmt.Fprintln(a.Writer, "Built time:\t", build.Time)
@@ -191,6 +223,37 @@ func (a *App) Run() error {
 	if a.Flags.NoPrefix && a.Flags.UseSIPrefix {
 		return fmt.Errorf("--no-prefix and --si cannot be used at once")
 	}
+	if err := a.checkStorageBackend(); err != nil {
+		return err
+	}
+	if a.Flags.Diff {
+		return a.runDiff()
+	}
+	if a.isStorageMaintenance() {
+		return a.runStorageMaintenance()
+	}
+	if a.Flags.SnapshotList || a.Flags.SnapshotPrune > 0 {
+		return a.runSnapshots()
+	}
+	if a.Flags.SnapshotName != "" && !a.Flags.UseStorage {
+		return fmt.Errorf("--snapshot-name can be used only together with --use-storage")
+	}
+	if a.Flags.OutputFormat != "" && a.Flags.OutputFormat != report.FormatJSON && a.Flags.OutputFile == "" {
+		return fmt.Errorf("--output-format can be used only together with --output-file")
+	}
+	if a.Flags.Merge {
+		return a.runMerge()
+	}
+	if a.Flags.InputFormat != "" && a.Flags.InputFormat != report.InputFormatJSON {
+		if a.Flags.InputFile == "" {
+			return fmt.Errorf("--input-format can be used only together with --input-file")
+		}
+		if !report.IsListingFormat(a.Flags.InputFormat) {
+			return fmt.Errorf("unknown input format: %s", a.Flags.InputFormat)
+		}
+		// listed paths don't have to exist locally
+		a.Flags.NoDelete = true
+	}
 
 	path := a.getPath()
 	path, err := filepath.Abs(path)
@@ -204,7 +267,11 @@ func (a *App) Run() error {
 	}
 
 	if a.Flags.UseStorage {
-		ui.SetAnalyzer(analyze.CreateStoredAnalyzer(a.Flags.StoragePath))
+		analyzer := analyze.CreateStoredAnalyzer(a.Flags.StoragePath)
+		analyzer.SetStorageBackend(a.Flags.StorageBackend)
+		analyzer.SetScanOptions(a.getStorageScanOptions())
+		analyzer.SetSnapshotName(a.Flags.SnapshotName)
+		ui.SetAnalyzer(analyzer)
 	}
 	if a.Flags.SequentialScanning {
 		ui.SetAnalyzer(analyze.CreateSeqAnalyzer())
@@ -307,6 +374,13 @@ func (a *App) setTimeFilters(ui UI) error {
 	return nil
 }
 
+// discardOutput removes atomic output file unless it has been completely written
+func (a *App) discardOutput() {
+	if a.atomicOutput != nil {
+		_ = a.atomicOutput.Discard()
+	}
+}
+
 func (a *App) createUI() (UI, error) {
 	var ui UI
 	var err error
@@ -314,15 +388,23 @@ func (a *App) createUI() (UI, error) {
 	switch {
 	case a.Flags.OutputFile != "":
 		var output io.Writer
-		if a.Flags.OutputFile == "-" {
+		switch {
+		case a.Flags.OutputFile == "-":
 			output = os.Stdout
-		} else {
+		case strings.EqualFold(a.Flags.OutputFormat, report.FormatPrometheus):
+			// textfile collectors must never read partially written file
+			a.atomicOutput, err = report.CreateAtomicFile(a.Flags.OutputFile)
+			if err != nil {
+				return nil, fmt.Errorf("opening output file: %w", err)
+			}
+			output = a.atomicOutput
+		default:
 			output, err = os.OpenFile(a.Flags.OutputFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
 			if err != nil {
 				return nil, fmt.Errorf("opening output file: %w", err)
 			}
 		}
-		ui = report.CreateExportUI(
+		exportUI := report.CreateExportUI(
 			a.Writer,
 			output,
 			!a.Flags.NoColor && a.Istty,
@@ -330,6 +412,20 @@ func (a *App) createUI() (UI, error) {
 			a.Flags.ConstGC,
 			a.Flags.UseSIPrefix,
 		)
+		if err := exportUI.SetFormat(a.Flags.OutputFormat); err != nil {
+			return nil, err
+		}
+		minSize, err := common.ParseSize(a.Flags.MinSize)
+		if err != nil {
+			return nil, err
+		}
+		exportUI.SetMaxDepth(a.Flags.MaxDepth)
+		exportUI.SetMinSize(minSize)
+		exportUI.SetDirsOnly(a.Flags.DirsOnly)
+		exportUI.SetShowApparentSize(a.Flags.ShowApparentSize)
+		exportUI.SetDevicesInfoGetter(a.Getter)
+		exportUI.SetMetadata(a.getMetadata())
+		ui = exportUI
 	case a.Flags.ShouldRunInNonInteractiveMode(a.Istty):
 		fixedUnit := ""
 		if a.Flags.ShowInKiB {
@@ -352,9 +448,35 @@ func (a *App) createUI() (UI, error) {
 		if a.Flags.NoUnicode || runtime.GOOS == "windows" {
 			stdoutUI.UseOldProgressRunes()
 		}
+		// the output keeps its colours unless a theme is chosen explicitly
+		if a.Flags.Theme != "" {
+			th, err := theme.Get(a.Flags.Theme, a.Flags.Themes)
+			if err != nil {
+				return nil, fmt.Errorf("invalid theme: %w", err)
+			}
+			stdoutUI.SetTheme(th)
+		}
 		ui = stdoutUI
 	default:
-		opts := a.getOptions()
+		keymap, err := tui.ParseKeymap(a.getKeymapOverrides())
+		if err != nil {
+			return nil, fmt.Errorf("invalid keymap: %w", err)
+		}
+		if err := keymap.AddCustomActions(a.getCustomActions()); err != nil {
+			return nil, fmt.Errorf("invalid custom actions: %w", err)
+		}
+		th, err := theme.Get(a.Flags.Theme, a.Flags.Themes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid theme: %w", err)
+		}
+		// colours set in the style override colours of the theme
+		opts := []tui.Option{func(ui *tui.UI) {
+			ui.SetTheme(th)
+		}}
+		opts = append(opts, a.getOptions()...)
+		opts = append(opts, func(ui *tui.UI) {
+			ui.SetKeymap(keymap)
+		})
 
 		ui = tui.CreateUI(
 			a.TermApp,
@@ -368,12 +490,10 @@ func (a *App) createUI() (UI, error) {
 			opts...,
 		)
 
-		if !a.Flags.NoColor {
-			tview.Styles.TitleColor = tcell.NewRGBColor(27, 161, 227)
-		} else {
+		if a.Flags.NoColor {
 			tview.Styles.ContrastBackgroundColor = tcell.NewRGBColor(150, 150, 150)
+			tview.Styles.BorderColor = tcell.ColorDefault
 		}
-		tview.Styles.BorderColor = tcell.ColorDefault
 	}
 
 	return ui, nil
@@ -482,9 +602,74 @@ func (a *App) getOptions() []tui.Option {
 			ui.SetDeleteInParallel()
 		})
 	}
+	if a.Flags.DeleteToTrash {
+		opts = append(opts, func(ui *tui.UI) {
+			ui.SetDeleteToTrash()
+		})
+	}
+	opts = append(opts, func(ui *tui.UI) {
+		ui.SetBookmarks(a.Flags.Bookmarks, func(bookmarks map[string]string) error {
+			return SaveBookmarks(a.Flags.CfgFile, bookmarks)
+		})
+	})
+	opts = append(opts, func(ui *tui.UI) {
+		ui.SetDevicesInfoGetter(a.Getter)
+		ui.SetMetadata(a.getMetadata())
+	})
 	return opts
 }
 
+// getMetadata returns metadata of the analysis made with current flags
+func (a *App) getMetadata() *report.Metadata {
+	return report.NewMetadata(a.getAnalyzerName(), a.getScanOptions())
+}
+
+func (a *App) getAnalyzerName() string {
+	switch {
+	case a.Flags.UseStorage || a.Flags.ReadFromStorage:
+		return "stored"
+	case a.Flags.SequentialScanning:
+		return "sequential"
+	default:
+		return "parallel"
+	}
+}
+
+// getScanOptions returns options which affected the analysis
+func (a *App) getScanOptions() map[string]string {
+	options := make(map[string]string)
+
+	setString := func(name, value string) {
+		if value != "" {
+			options[name] = value
+		}
+	}
+	setBool := func(name string, value bool) {
+		if value {
+			options[name] = "true"
+		}
+	}
+
+	setString("input-file", a.Flags.InputFile)
+	if a.Flags.InputFormat != report.InputFormatJSON {
+		setString("input-format", a.Flags.InputFormat)
+	}
+	setString("ignore-dirs", strings.Join(a.Flags.IgnoreDirs, ","))
+	setString("ignore-dirs-pattern", strings.Join(a.Flags.IgnoreDirPatterns, ","))
+	setString("ignore-from", a.Flags.IgnoreFromFile)
+	setString("since", a.Flags.Since)
+	setString("until", a.Flags.Until)
+	setString("max-age", a.Flags.MaxAge)
+	setString("min-age", a.Flags.MinAge)
+	setBool("no-hidden", a.Flags.NoHidden)
+	setBool("no-cross", a.Flags.NoCross)
+	setBool("follow-symlinks", a.Flags.FollowSymlinks)
+	setBool("show-annexed-size", a.Flags.ShowAnnexedSize)
+	setBool("archive-browsing", a.Flags.ArchiveBrowsing)
+	setBool("read-from-storage", a.Flags.ReadFromStorage)
+	return options
+}
+
 func (a *App) setNoCross(path string) error {
 	if a.Flags.NoCross {
 		mounts, err := a.Getter.GetMounts()
@@ -498,6 +683,39 @@ func (a *App) setNoCross(path string) error {
 	return nil
 }
 
+// metadataSetter is implemented by UIs which show or export metadata of the analysis
+type metadataSetter interface {
+	SetMetadata(meta *report.Metadata)
+}
+
+func setMetadata(ui UI, meta *report.Metadata) {
+	if setter, ok := ui.(metadataSetter); ok {
+		setter.SetMetadata(meta)
+	}
+}
+
+func readSQLite(ui UI, path string) error {
+	dir, meta, err := report.ReadSQLiteWithMetadata(path)
+	if err != nil {
+		return err
+	}
+	dir.UpdateStats(make(gfs.HardLinkedItems, 10))
+	setMetadata(ui, meta)
+	return ui.ShowAnalysis(dir)
+}
+
+func readListing(ui UI, input io.Reader, format string) error {
+	dir, err := report.ReadListing(input, format)
+	if err != nil {
+		return err
+	}
+	setMetadata(ui, &report.Metadata{
+		Root:    dir.GetPath(),
+		Options: map[string]string{"input-format": format},
+	})
+	return ui.ShowAnalysis(dir)
+}
+
 func (a *App) runAction(ui UI, path string) error {
 	if a.Flags.Profiling {
 		go func() {
@@ -515,6 +733,10 @@ func (a *App) runAction(ui UI, path string) error {
 		if err := ui.ListDevices(a.Getter); err != nil {
 			return fmt.Errorf("loading mount points: %w", err)
 		}
+	case a.Flags.InputFile != "" && report.IsSQLite(a.Flags.InputFile):
+		if err := readSQLite(ui, a.Flags.InputFile); err != nil {
+			return fmt.Errorf("reading analysis: %w", err)
+		}
 	case a.Flags.InputFile != "":
 		var input io.Reader
 		var err error
@@ -527,7 +749,11 @@ func (a *App) runAction(ui UI, path string) error {
 			}
 		}
 
-		if err := ui.ReadAnalysis(input); err != nil {
+		if report.IsListingFormat(a.Flags.InputFormat) {
+			if err := readListing(ui, input, a.Flags.InputFormat); err != nil {
+				return fmt.Errorf("reading listing: %w", err)
+			}
+		} else if err := ui.ReadAnalysis(input); err != nil {
 			return fmt.Errorf("reading analysis: %w", err)
 		}
 	case a.Flags.ReadFromStorage:
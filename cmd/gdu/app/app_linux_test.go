@@ -107,6 +107,43 @@ func TestReadFromStorage(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestReadFromStorageWithFoldedExport(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	storagePath := t.TempDir()
+	defer func() {
+		os.Remove("output.folded")
+	}()
+
+	_, err := runApp(
+		&Flags{LogFile: "/dev/null", UseStorage: true, StoragePath: storagePath},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.Nil(t, err)
+
+	out, err := runApp(
+		&Flags{
+			LogFile:         "/dev/null",
+			ReadFromStorage: true,
+			StoragePath:     storagePath,
+			OutputFile:      "output.folded",
+			OutputFormat:    "folded",
+		},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+
+	data, err := os.ReadFile("output.folded")
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), ";nested;subnested;file 4096\n")
+}
+
 func TestReadFromStorageWithErr(t *testing.T) {
 	fin := testdir.CreateTestDir()
 	defer fin()
@@ -118,5 +155,5 @@ func TestReadFromStorageWithErr(t *testing.T) {
 		testdev.DevicesInfoGetterMock{},
 	)
 
-	assert.ErrorContains(t, err, "Key not found")
+	assert.ErrorContains(t, err, "no scan of")
 }
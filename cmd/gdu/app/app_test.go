@@ -3,6 +3,7 @@ package app
 import (
 	"bytes"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
@@ -12,8 +13,12 @@ import (
 	"github.com/dundee/gdu/v5/internal/testapp"
 	"github.com/dundee/gdu/v5/internal/testdev"
 	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/analyze"
 	"github.com/dundee/gdu/v5/pkg/device"
+	"github.com/dundee/gdu/v5/pkg/theme"
+	"github.com/dundee/gdu/v5/report"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
 )
 
 func init() {
@@ -254,92 +259,813 @@ func TestGuiDeleteInParallel(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestGuiDeleteToTrash(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", DeleteToTrash: true},
+		[]string{"test_dir"},
+		true,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+}
+
+func TestGuiWithKeymap(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", Keymap: map[string]Keys{"delete": {"ctrl+d"}, "move": {"d"}}},
+		[]string{"test_dir"},
+		true,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+}
+
+func TestGuiWithConflictingKeymap(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	_, err := runApp(
+		&Flags{LogFile: "/dev/null", Keymap: map[string]Keys{"delete": {"x"}}},
+		[]string{"test_dir"},
+		true,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.ErrorContains(t, err, `invalid keymap: conflicting key bindings: key "x" is bound to both "delete" and "move"`)
+}
+
+func TestGuiWithConflictingCustomActions(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	_, err := runApp(
+		&Flags{LogFile: "/dev/null", CustomActions: []CustomAction{{Key: "d", Label: "Df", Command: "df"}}},
+		[]string{"test_dir"},
+		true,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.ErrorContains(t, err, `invalid custom actions: conflicting key bindings: key "d" is bound to both "delete" and "Df"`)
+}
+
+func TestGuiWithTheme(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", Theme: "gruvbox", Style: Style{Header: HeaderColorStyle{BackgroundColor: "red"}}},
+		[]string{"test_dir"},
+		true,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+}
+
+func TestGuiWithUnknownTheme(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	_, err := runApp(
+		&Flags{LogFile: "/dev/null", Theme: "solarized"},
+		[]string{"test_dir"},
+		true,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.ErrorContains(t, err, `invalid theme: unknown theme "solarized"`)
+}
+
+func TestAnalyzePathWithTheme(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", Theme: "nord"},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Contains(t, out, "nested")
+	assert.Nil(t, err)
+}
+
 func TestAnalyzePathWithGuiBackgroundDeletion(t *testing.T) {
 	fin := testdir.CreateTestDir()
 	defer fin()
 
-	out, err := runApp(
-		&Flags{LogFile: "/dev/null", DeleteInBackground: true},
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", DeleteInBackground: true},
+		[]string{"test_dir"},
+		true,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+}
+
+func TestAnalyzePathWithDefaultSorting(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{
+			LogFile: "/dev/null",
+			Sorting: Sorting{
+				By:    "name",
+				Order: "asc",
+			},
+		},
+		[]string{"test_dir"},
+		true,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+}
+
+func TestAnalyzePathWithStyle(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{
+			LogFile: "/dev/null",
+			Style: Style{
+				SelectedRow: ColorStyle{
+					TextColor:       "black",
+					BackgroundColor: "red",
+				},
+				ProgressModal: ProgressModalOpts{
+					CurrentItemNameMaxLen: 10,
+				},
+				Footer: FooterColorStyle{
+					TextColor:       "black",
+					BackgroundColor: "red",
+					NumberColor:     "white",
+				},
+				Header: HeaderColorStyle{
+					TextColor:       "black",
+					BackgroundColor: "red",
+					Hidden:          true,
+				},
+				ResultRow: ResultRowColorStyle{
+					NumberColor:    "orange",
+					DirectoryColor: "blue",
+				},
+				UseOldSizeBar: true,
+			},
+		},
+		[]string{"test_dir"},
+		true,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+}
+
+func TestAnalyzePathNoUnicode(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	out, err := runApp(
+		&Flags{
+			LogFile:   "/dev/null",
+			NoUnicode: true,
+		},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Contains(t, out, "nested")
+	assert.Nil(t, err)
+}
+
+func TestAnalyzePathWithExport(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	defer func() {
+		os.Remove("output.json")
+	}()
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", OutputFile: "output.json"},
+		[]string{"test_dir"},
+		true,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.NotEmpty(t, out)
+	assert.Nil(t, err)
+}
+
+func TestAnalyzePathWithCSVExport(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	defer func() {
+		os.Remove("output.csv")
+	}()
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", OutputFile: "output.csv", OutputFormat: "csv", DirsOnly: true},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+
+	data, err := os.ReadFile("output.csv")
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "path,type,asize,usage")
+	assert.Contains(t, string(data), "test_dir/nested/subnested,Directory")
+	assert.NotContains(t, string(data), "file2")
+}
+
+func TestConvertAnalysisToCSV(t *testing.T) {
+	defer func() {
+		os.Remove("output.csv")
+	}()
+
+	out, err := runApp(
+		&Flags{
+			LogFile:      "/dev/null",
+			InputFile:    "../../../internal/testdata/test.json",
+			OutputFile:   "output.csv",
+			OutputFormat: "csv",
+		},
+		[]string{},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+
+	data, err := os.ReadFile("output.csv")
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "/home/gdu/main.go,File,3205,4096")
+}
+
+func TestOutputFormatWithoutOutputFile(t *testing.T) {
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", OutputFormat: "csv"},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.ErrorContains(t, err, "--output-format can be used only together with --output-file")
+}
+
+func TestWrongOutputFormat(t *testing.T) {
+	defer func() {
+		os.Remove("output.xxx")
+	}()
+
+	_, err := runApp(
+		&Flags{LogFile: "/dev/null", OutputFile: "output.xxx", OutputFormat: "xxx"},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.ErrorContains(t, err, "unknown output format: xxx")
+}
+
+func TestAnalyzePathWithHTMLExport(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	defer func() {
+		os.Remove("output.html")
+	}()
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", OutputFile: "output.html", OutputFormat: "html", MinSize: "1K"},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+
+	data, err := os.ReadFile("output.html")
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "<!DOCTYPE html>")
+	assert.Contains(t, string(data), `"n":"subnested"`)
+}
+
+func TestAnalyzePathWithPrometheusExport(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	defer func() {
+		os.Remove("output.prom")
+	}()
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", OutputFile: "output.prom", OutputFormat: "prometheus", MaxDepth: 1},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+
+	data, err := os.ReadFile("output.prom")
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "# TYPE gdu_directory_usage_bytes gauge")
+	assert.Contains(t, string(data), "/test_dir/nested\"}")
+	assert.NotContains(t, string(data), "subnested")
+}
+
+func TestPrometheusExportDiscardedOnError(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	for _, flags := range []*Flags{
+		{MinSize: "abc"},
+		{IgnoreDirPatterns: []string{"[[["}},
+		{InputFile: "missing.json"},
+	} {
+		dir := t.TempDir()
+		flags.LogFile = "/dev/null"
+		flags.OutputFile = filepath.Join(dir, "output.prom")
+		flags.OutputFormat = "prometheus"
+
+		_, err := runApp(flags, []string{"test_dir"}, false, testdev.DevicesInfoGetterMock{})
+		assert.NotNil(t, err)
+
+		entries, err := os.ReadDir(dir)
+		assert.Nil(t, err)
+		assert.Empty(t, entries)
+	}
+}
+
+func TestReadAnalysisWithFoldedExport(t *testing.T) {
+	defer func() {
+		os.Remove("output.folded")
+	}()
+
+	out, err := runApp(
+		&Flags{
+			LogFile:          "/dev/null",
+			InputFile:        "../../../internal/testdata/test.json",
+			OutputFile:       "output.folded",
+			OutputFormat:     "folded",
+			ShowApparentSize: true,
+			MinSize:          "2K",
+		},
+		[]string{},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+
+	data, err := os.ReadFile("output.folded")
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "/home/gdu;app;app.go 4638\n")
+	assert.NotContains(t, string(data), "app_linux_test.go")
+}
+
+func TestSQLiteExportAndImport(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	defer func() {
+		os.Remove("output.sqlite")
+	}()
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", OutputFile: "output.sqlite", OutputFormat: "sqlite"},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+
+	out, err = runApp(
+		&Flags{LogFile: "/dev/null", InputFile: "output.sqlite"},
+		[]string{},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.Contains(t, out, "nested")
+	assert.Nil(t, err)
+
+	out, err = runApp(
+		&Flags{LogFile: "/dev/null", InputFile: "output.sqlite"},
+		[]string{},
+		true,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+}
+
+func TestExportAndImportMetadata(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	output := filepath.Join(t.TempDir(), "output.json")
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", OutputFile: output, SequentialScanning: true, NoCross: true},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+
+	input, err := os.Open(output)
+	assert.Nil(t, err)
+	defer input.Close()
+
+	_, meta, err := report.ReadAnalysisWithMetadata(input)
+	assert.Nil(t, err)
+	assert.Equal(t, "sequential", meta.Analyzer)
+	assert.Equal(t, "true", meta.Options["no-cross"])
+	assert.True(t, strings.HasSuffix(meta.Root, "test_dir"))
+}
+
+func TestImportDuListingAndExport(t *testing.T) {
+	dir := t.TempDir()
+	listing := filepath.Join(dir, "listing.txt")
+	output := filepath.Join(dir, "output.json")
+	err := os.WriteFile(listing, []byte("5000\tsrv/a/file\n9096\tsrv/a\n13192\tsrv\n"), 0o600)
+	assert.Nil(t, err)
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", InputFile: listing, InputFormat: "du-b", OutputFile: output},
+		[]string{},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+
+	data, err := os.ReadFile(output)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `{"name":"file","asize":5000,"dsize":5000}`)
+
+	out, err = runApp(
+		&Flags{LogFile: "/dev/null", InputFile: output},
+		[]string{},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.Contains(t, out, "/a")
+	assert.Nil(t, err)
+}
+
+func TestInputFormatWithoutInputFile(t *testing.T) {
+	_, err := runApp(
+		&Flags{LogFile: "/dev/null", InputFormat: "find"},
+		[]string{},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.ErrorContains(t, err, "--input-format can be used only together with --input-file")
+
+	_, err = runApp(
+		&Flags{LogFile: "/dev/null", InputFile: "listing.txt", InputFormat: "xxx"},
+		[]string{},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.ErrorContains(t, err, "unknown input format: xxx")
+}
+
+func TestWrongMinSize(t *testing.T) {
+	defer func() {
+		os.Remove("output.html")
+	}()
+
+	_, err := runApp(
+		&Flags{LogFile: "/dev/null", OutputFile: "output.html", OutputFormat: "html", MinSize: "10X"},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.ErrorContains(t, err, "invalid size unit: 10X")
+}
+
+func TestDiff(t *testing.T) {
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", Diff: true},
+		[]string{"../../../internal/testdata/test.json", "../../../internal/testdata/test2.json"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Nil(t, err)
+	assert.Contains(t, out, "+8.0 KiB changed   /mnt/backup/home/gdu/app/app.go")
+	assert.Contains(t, out, "-4.0 KiB removed   /mnt/backup/home/gdu/app/app_linux_test.go")
+	assert.Contains(t, out, "+4.0 KiB added     /mnt/backup/home/gdu/app/diff.go")
+	assert.Contains(t, out, "total")
+	assert.NotContains(t, out, "main.go")
+}
+
+func TestDiffWithTop(t *testing.T) {
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", Diff: true, Top: 1},
+		[]string{"../../../internal/testdata/test.json", "../../../internal/testdata/test2.json"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(strings.Split(out, "\n")))
+	assert.Contains(t, out, "app.go")
+}
+
+func TestDiffInteractive(t *testing.T) {
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", Diff: true},
+		[]string{"../../../internal/testdata/test.json", "../../../internal/testdata/test2.json"},
+		true,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Empty(t, out)
+	assert.Nil(t, err)
+}
+
+func TestDiffFromStorage(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	storagePath := t.TempDir()
+	_, err := runApp(
+		&Flags{LogFile: "/dev/null", UseStorage: true, StoragePath: storagePath, NonInteractive: true},
 		[]string{"test_dir"},
-		true,
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.Nil(t, err)
+
+	err = os.WriteFile("test_dir/nested/file3", []byte("hello"), 0o600)
+	assert.Nil(t, err)
+	defer func() {
+		os.Remove("new.json")
+	}()
+	_, err = runApp(
+		&Flags{LogFile: "/dev/null", OutputFile: "new.json"},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.Nil(t, err)
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", Diff: true, ShowApparentSize: true},
+		[]string{storagePath, "new.json"},
+		false,
 		testdev.DevicesInfoGetterMock{},
 	)
 
-	assert.Empty(t, out)
 	assert.Nil(t, err)
+	assert.Contains(t, out, "+5 B added     ")
+	assert.Contains(t, out, "test_dir/nested/file3")
 }
 
-func TestAnalyzePathWithDefaultSorting(t *testing.T) {
+func TestDiffScansFromOneStorage(t *testing.T) {
 	fin := testdir.CreateTestDir()
 	defer fin()
 
+	storagePath := t.TempDir()
+	scan := func(noHidden bool, snapshot string) {
+		_, err := runApp(
+			&Flags{
+				LogFile: "/dev/null", UseStorage: true, StoragePath: storagePath,
+				NoHidden: noHidden, SnapshotName: snapshot, NonInteractive: true,
+			},
+			[]string{"test_dir"},
+			false,
+			testdev.DevicesInfoGetterMock{},
+		)
+		assert.Nil(t, err)
+	}
+
+	scan(false, "monday")
+	err := os.WriteFile("test_dir/nested/file3", []byte("hello"), 0o600)
+	assert.Nil(t, err)
+	scan(false, "tuesday")
+	// scan with different options is kept apart
+	scan(true, "")
+
+	storage := analyze.NewStorage(storagePath, "")
+	closeFn := storage.Open()
+	scans, err := storage.ListScans()
+	closeFn()
+	assert.Nil(t, err)
+	assert.Len(t, scans, 2)
+
 	out, err := runApp(
-		&Flags{
-			LogFile: "/dev/null",
-			Sorting: Sorting{
-				By:    "name",
-				Order: "asc",
+		&Flags{LogFile: "/dev/null", Diff: true, ShowApparentSize: true},
+		[]string{storagePath + "@monday", storagePath + "@tuesday"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.Nil(t, err)
+	assert.Contains(t, out, "+5 B changed   ")
+	assert.Contains(t, out, "test_dir/nested/\n")
+
+	out, err = runApp(
+		&Flags{LogFile: "/dev/null", Diff: true, ShowApparentSize: true},
+		[]string{storagePath + "@" + scans[1].ID, storagePath + "@" + scans[0].ID},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.Nil(t, err)
+	assert.Contains(t, out, "+0 B total")
+
+	_, err = runApp(
+		&Flags{LogFile: "/dev/null", Diff: true},
+		[]string{storagePath + "@friday", storagePath},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.ErrorContains(t, err, "no scan or snapshot friday in the storage")
+}
+
+func TestSnapshotListAndPrune(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	storagePath := t.TempDir()
+	for _, name := range []string{"monday", "tuesday"} {
+		_, err := runApp(
+			&Flags{
+				LogFile: "/dev/null", UseStorage: true, StoragePath: storagePath,
+				SnapshotName: name, NonInteractive: true,
 			},
-		},
-		[]string{"test_dir"},
-		true,
+			[]string{"test_dir"},
+			false,
+			testdev.DevicesInfoGetterMock{},
+		)
+		assert.Nil(t, err)
+	}
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", StoragePath: storagePath, SnapshotList: true},
+		[]string{},
+		false,
 		testdev.DevicesInfoGetterMock{},
 	)
+	assert.Nil(t, err)
+	assert.Contains(t, out, "test_dir (")
+	assert.Contains(t, out, "  monday ")
+	assert.Contains(t, out, "  tuesday ")
 
-	assert.Empty(t, out)
+	out, err = runApp(
+		&Flags{LogFile: "/dev/null", StoragePath: storagePath, SnapshotPrune: 1, SnapshotList: true},
+		[]string{},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
 	assert.Nil(t, err)
+	assert.Contains(t, out, "Removed snapshot monday of ")
+	assert.Contains(t, out, "  tuesday ")
+	assert.NotContains(t, out, "  monday ")
 }
 
-func TestAnalyzePathWithStyle(t *testing.T) {
+func TestStorageList(t *testing.T) {
 	fin := testdir.CreateTestDir()
 	defer fin()
 
+	storagePath := t.TempDir()
+	for _, noHidden := range []bool{false, true} {
+		_, err := runApp(
+			&Flags{
+				LogFile: "/dev/null", UseStorage: true, StoragePath: storagePath,
+				NoHidden: noHidden, NonInteractive: true,
+			},
+			[]string{"test_dir"},
+			false,
+			testdev.DevicesInfoGetterMock{},
+		)
+		assert.Nil(t, err)
+	}
+
 	out, err := runApp(
-		&Flags{
-			LogFile: "/dev/null",
-			Style: Style{
-				SelectedRow: ColorStyle{
-					TextColor:       "black",
-					BackgroundColor: "red",
-				},
-				ProgressModal: ProgressModalOpts{
-					CurrentItemNameMaxLen: 10,
-				},
-				Footer: FooterColorStyle{
-					TextColor:       "black",
-					BackgroundColor: "red",
-					NumberColor:     "white",
-				},
-				Header: HeaderColorStyle{
-					TextColor:       "black",
-					BackgroundColor: "red",
-					Hidden:          true,
-				},
-				ResultRow: ResultRowColorStyle{
-					NumberColor:    "orange",
-					DirectoryColor: "blue",
-				},
-				UseOldSizeBar: true,
+		&Flags{LogFile: "/dev/null", StoragePath: storagePath, StorageList: true},
+		[]string{},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.Nil(t, err)
+	lines := strings.Split(out, "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "5 items  ")
+	assert.True(t, strings.HasSuffix(lines[0], "test_dir"))
+	assert.True(t, strings.HasSuffix(lines[1], "test_dir (no-hidden=true)"))
+}
+
+func TestStorageMaintenance(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	storagePath := t.TempDir()
+	for _, noHidden := range []bool{false, true} {
+		_, err := runApp(
+			&Flags{
+				LogFile: "/dev/null", UseStorage: true, StoragePath: storagePath,
+				NoHidden: noHidden, NonInteractive: true,
 			},
-		},
-		[]string{"test_dir"},
-		true,
+			[]string{"test_dir"},
+			false,
+			testdev.DevicesInfoGetterMock{},
+		)
+		assert.Nil(t, err)
+	}
+
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", StoragePath: storagePath, StorageVerify: true, StorageInfo: true},
+		[]string{},
+		false,
 		testdev.DevicesInfoGetterMock{},
 	)
+	assert.Nil(t, err)
+	assert.Contains(t, out, "Verified 2 scans, no problems found")
+	assert.Contains(t, out, "Scans: 2")
 
-	assert.Empty(t, out)
+	out, err = runApp(
+		&Flags{LogFile: "/dev/null", StoragePath: storagePath, StorageDelete: "test_dir", StorageGC: true},
+		[]string{},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, strings.Count(out, "Deleted scan "))
+	assert.Contains(t, out, "Storage size reduced from ")
+
+	out, err = runApp(
+		&Flags{LogFile: "/dev/null", StoragePath: storagePath, StorageList: true},
+		[]string{},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
 	assert.Nil(t, err)
+	assert.Contains(t, out, "No scans in ")
+
+	_, err = runApp(
+		&Flags{LogFile: "/dev/null", StoragePath: storagePath, StorageDelete: "test_dir"},
+		[]string{},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.ErrorContains(t, err, "no scan of test_dir in the storage")
 }
 
-func TestAnalyzePathNoUnicode(t *testing.T) {
+func TestSnapshotListWithoutStorage(t *testing.T) {
+	_, err := runApp(
+		&Flags{LogFile: "/dev/null", StoragePath: t.TempDir(), SnapshotList: true},
+		[]string{},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.ErrorContains(t, err, "is not a storage directory")
+}
+
+func TestSnapshotNameWithoutStorage(t *testing.T) {
+	_, err := runApp(
+		&Flags{LogFile: "/dev/null", SnapshotName: "monday"},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.ErrorContains(t, err, "--snapshot-name can be used only together with --use-storage")
+}
+
+func TestUseStorageWithMemoryBackend(t *testing.T) {
 	fin := testdir.CreateTestDir()
 	defer fin()
 
+	storagePath := t.TempDir()
 	out, err := runApp(
-		&Flags{
-			LogFile:   "/dev/null",
-			NoUnicode: true,
-		},
+		&Flags{LogFile: "/dev/null", UseStorage: true, StoragePath: storagePath, StorageBackend: "memory"},
 		[]string{"test_dir"},
 		false,
 		testdev.DevicesInfoGetterMock{},
@@ -347,24 +1073,136 @@ func TestAnalyzePathNoUnicode(t *testing.T) {
 
 	assert.Contains(t, out, "nested")
 	assert.Nil(t, err)
+	assert.NoFileExists(t, filepath.Join(storagePath, "MANIFEST"))
 }
 
-func TestAnalyzePathWithExport(t *testing.T) {
+func TestUnknownStorageBackend(t *testing.T) {
+	_, err := runApp(
+		&Flags{LogFile: "/dev/null", UseStorage: true, StorageBackend: "xxx"},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.ErrorContains(t, err, "unknown storage backend: xxx (available: badger, memory)")
+}
+
+func TestReadFromMemoryBackend(t *testing.T) {
+	_, err := runApp(
+		&Flags{LogFile: "/dev/null", ReadFromStorage: true, StorageBackend: "memory"},
+		[]string{"test_dir"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+	assert.ErrorContains(t, err, "memory storage backend doesn't keep data between runs")
+}
+
+func TestDiffWithMissingSource(t *testing.T) {
+	_, err := runApp(
+		&Flags{LogFile: "/dev/null", Diff: true},
+		[]string{"../../../internal/testdata/test.json", "missing.json"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.ErrorContains(t, err, "reading missing.json")
+}
+
+func TestDiffWithWrongSource(t *testing.T) {
 	fin := testdir.CreateTestDir()
 	defer fin()
+
+	_, err := runApp(
+		&Flags{LogFile: "/dev/null", Diff: true},
+		[]string{"test_dir", "../../../internal/testdata/test.json"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.ErrorContains(t, err, "test_dir is not a storage directory")
+}
+
+func TestMerge(t *testing.T) {
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", Merge: true, NonInteractive: true, NoProgress: true},
+		[]string{"../../../internal/testdata/test.json", "../../../internal/testdata/test2.json"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Nil(t, err)
+	assert.Contains(t, out, "40.0 KiB /test2")
+	assert.Contains(t, out, "32.0 KiB /test")
+}
+
+func TestMergeOverlayWithExport(t *testing.T) {
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", Merge: true, MergeOverlay: true, OutputFile: "output.json", NoProgress: true},
+		[]string{"../../../internal/testdata/test.json", "../../../internal/testdata/test2.json"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
 	defer func() {
 		os.Remove("output.json")
 	}()
 
+	assert.Nil(t, err)
+	assert.Empty(t, out)
+
+	content, err := os.ReadFile("output.json")
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), `{"name":"/"}`)
+	assert.Contains(t, string(content), `{"name":"backup"}`)
+	assert.Contains(t, string(content), `{"name":"diff.go"`)
+}
+
+func TestMergeInputsOfSameName(t *testing.T) {
+	data, err := os.ReadFile("../../../internal/testdata/test.json")
+	assert.Nil(t, err)
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b"} {
+		assert.Nil(t, os.Mkdir(filepath.Join(dir, name), 0o755))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name, "export.json"), data, 0o600))
+	}
+
 	out, err := runApp(
-		&Flags{LogFile: "/dev/null", OutputFile: "output.json"},
-		[]string{"test_dir"},
-		true,
+		&Flags{LogFile: "/dev/null", Merge: true, NonInteractive: true, NoProgress: true},
+		[]string{
+			filepath.Join(dir, "a", "export.json"),
+			filepath.Join(dir, "b", "export.json"),
+			filepath.Join(dir, "a", "export.json"),
+		},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.Nil(t, err)
+	assert.Regexp(t, `(?m)32\.0 KiB /export-a$`, out)
+	assert.Regexp(t, `(?m)32\.0 KiB /export-b$`, out)
+	assert.Regexp(t, `(?m)32\.0 KiB /export-a-3$`, out)
+	assert.NotContains(t, out, "Conflict")
+}
+
+func TestMergeOverlayReportsConflicts(t *testing.T) {
+	out, err := runApp(
+		&Flags{LogFile: "/dev/null", Merge: true, MergeOverlay: true, NonInteractive: true, NoProgress: true},
+		[]string{"../../../internal/testdata/test.json", "../../../internal/testdata/test.json"},
+		false,
 		testdev.DevicesInfoGetterMock{},
 	)
 
-	assert.NotEmpty(t, out)
 	assert.Nil(t, err)
+	assert.Contains(t, out, "Conflict: ")
+}
+
+func TestMergeWithMissingInput(t *testing.T) {
+	_, err := runApp(
+		&Flags{LogFile: "/dev/null", Merge: true},
+		[]string{"../../../internal/testdata/test.json", "missing.json"},
+		false,
+		testdev.DevicesInfoGetterMock{},
+	)
+
+	assert.ErrorContains(t, err, "reading missing.json")
 }
 
 func TestAnalyzePathWithChdir(t *testing.T) {
@@ -570,6 +1408,97 @@ func TestMaxCoresLowEdge(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestSaveBookmarks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gdu.yaml")
+	err := os.WriteFile(path, []byte("# my config\nno-color: true\nbookmarks:\n  old: /old\nsorting:\n  by: name\n"), 0o600)
+	assert.Nil(t, err)
+
+	err = SaveBookmarks(path, map[string]string{"logs": "/var/log"})
+	assert.Nil(t, err)
+
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "# my config")
+	assert.NotContains(t, string(data), "/old")
+
+	flags := &Flags{}
+	assert.Nil(t, yaml.Unmarshal(data, flags))
+	assert.True(t, flags.NoColor)
+	assert.Equal(t, "name", flags.Sorting.By)
+	assert.Equal(t, map[string]string{"logs": "/var/log"}, flags.Bookmarks)
+}
+
+func TestReadKeymapFromConfig(t *testing.T) {
+	flags := &Flags{}
+	err := yaml.Unmarshal([]byte("keymap:\n  delete: ctrl+d\n  sort-by-size: [S, shift+z]\n"), flags)
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]Keys{"delete": {"ctrl+d"}, "sort-by-size": {"S", "shift+z"}}, flags.Keymap)
+}
+
+func TestReadCustomActionsFromConfig(t *testing.T) {
+	flags := &Flags{}
+	err := yaml.Unmarshal([]byte(`custom-actions:
+  - key: R
+    label: Open in ranger
+    command: ranger {path}
+    foreground: true
+    rescan: true
+`), flags)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []CustomAction{{
+		Key:        "R",
+		Label:      "Open in ranger",
+		Command:    "ranger {path}",
+		Foreground: true,
+		Rescan:     true,
+	}}, flags.CustomActions)
+}
+
+func TestReadThemesFromConfig(t *testing.T) {
+	flags := &Flags{}
+	err := yaml.Unmarshal([]byte(`theme: mine
+themes:
+  mine:
+    base: nord
+    result-row:
+      bar-color: "#ff8700"
+    size-rules:
+      - min-size: 50G
+        color: red
+`), flags)
+	assert.Nil(t, err)
+
+	th, err := theme.Get(flags.Theme, flags.Themes)
+	assert.Nil(t, err)
+	assert.Equal(t, "#ff8700", th.ResultRow.BarColor)
+	assert.Equal(t, "#88c0d0", th.Header.BackgroundColor)
+	assert.Equal(t, []theme.SizeRule{{MinSize: "50G", Color: "red"}}, th.SizeRules)
+}
+
+func TestSaveBookmarksToNewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gdu.yaml")
+
+	err := SaveBookmarks(path, map[string]string{"logs": "/var/log"})
+	assert.Nil(t, err)
+
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "bookmarks:\n    logs: /var/log\n", string(data))
+}
+
+func TestSaveBookmarksWithInvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gdu.yaml")
+	assert.Nil(t, os.WriteFile(path, []byte("- a\n- b\n"), 0o600))
+
+	err := SaveBookmarks(path, map[string]string{})
+	assert.ErrorContains(t, err, "doesn't contain mapping of options")
+
+	err = SaveBookmarks("", map[string]string{})
+	assert.ErrorContains(t, err, "path of the config file is not known")
+}
+
 // nolint: unparam // Why: it's used in linux tests
 func runApp(flags *Flags, args []string, istty bool, getter device.DevicesInfoGetter) (output string, err error) {
 	buff := bytes.NewBufferString("")
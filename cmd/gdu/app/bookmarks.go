@@ -0,0 +1,69 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+const bookmarksKey = "bookmarks"
+
+// SaveBookmarks stores bookmarks into the config file keeping the rest of the configuration untouched
+func SaveBookmarks(path string, bookmarks map[string]string) error {
+	if path == "" {
+		return errors.New("path of the config file is not known")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading config file %s: %w", path, err)
+	}
+
+	var doc yaml.Node
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+		}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("config file %s doesn't contain mapping of options", path)
+	}
+
+	value := &yaml.Node{}
+	if err := value.Encode(bookmarks); err != nil {
+		return fmt.Errorf("encoding bookmarks: %w", err)
+	}
+
+	replaced := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == bookmarksKey {
+			root.Content[i+1] = value
+			replaced = true
+		}
+	}
+	if !replaced {
+		root.Content = append(root.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: bookmarksKey},
+			value,
+		)
+	}
+
+	data, err = yaml.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("encoding config file: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("writing config file %s: %w", path, err)
+	}
+	return nil
+}
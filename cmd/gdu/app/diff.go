@@ -0,0 +1,150 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/diff"
+	gfs "github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/report"
+)
+
+// DiffUI is implemented by UIs able to show differences between two analyses
+type DiffUI interface {
+	ShowDiff(root *diff.Item) error
+	StartUILoop() error
+}
+
+func (a *App) runDiff() error {
+	if len(a.Args) != 2 {
+		return errors.New("diff requires exactly two analyses to compare")
+	}
+
+	tree := diff.NewTree()
+	if err := readDiffSource(a.Args[0], tree.AddOld); err != nil {
+		return fmt.Errorf("reading %s: %w", a.Args[0], err)
+	}
+	if err := readDiffSource(a.Args[1], tree.AddNew); err != nil {
+		return fmt.Errorf("reading %s: %w", a.Args[1], err)
+	}
+
+	ui, err := a.createUI()
+	if err != nil {
+		return err
+	}
+	diffUI, ok := ui.(DiffUI)
+	if !ok {
+		return errors.New("diff can't be shown in this mode")
+	}
+
+	if err := diffUI.ShowDiff(tree.Root(a.Flags.ShowApparentSize)); err != nil {
+		return err
+	}
+	return diffUI.StartUILoop()
+}
+
+// readDiffSource reads analysis from JSON file (or standard input if source is "-")
+// or from directory with persistent storage and passes it to the add function.
+// Scan or snapshot kept in the storage can be selected as storage_dir@scan_id_or_snapshot_name.
+func readDiffSource(source string, add func(gfs.Item)) error {
+	var input io.Reader = os.Stdin
+
+	if source != "-" {
+		info, err := os.Stat(source)
+		if err != nil {
+			if storagePath, selector, ok := splitStorageSelector(source); ok {
+				return readDiffStorage(storagePath, selector, add)
+			}
+			return err
+		}
+		if info.IsDir() {
+			return readDiffStorage(source, "", add)
+		}
+
+		f, err := os.Open(source)
+		if err != nil {
+			return fmt.Errorf("opening input file: %w", err)
+		}
+		defer f.Close()
+		input = f
+	}
+
+	dir, err := report.ReadAnalysis(input)
+	if err != nil {
+		return err
+	}
+	dir.UpdateStats(make(gfs.HardLinkedItems, 10))
+	add(dir)
+	return nil
+}
+
+// splitStorageSelector splits source into storage directory and selector of scan or snapshot
+func splitStorageSelector(source string) (storagePath, selector string, ok bool) {
+	i := strings.LastIndex(source, "@")
+	if i <= 0 || i == len(source)-1 {
+		return "", "", false
+	}
+	info, err := os.Stat(source[:i])
+	if err != nil || !info.IsDir() {
+		return "", "", false
+	}
+	return source[:i], source[i+1:], true
+}
+
+// readDiffStorage reads the scan or snapshot given by selector from the storage,
+// the most recent scan is read if the selector is empty
+func readDiffStorage(storagePath, selector string, add func(gfs.Item)) error {
+	storage, closeFn, err := openExistingStorage(storagePath)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	var dir gfs.Item
+	if selector == "" {
+		if err := storage.SelectScan(""); err != nil {
+			return err
+		}
+		dir, err = storage.GetDirForPath(storage.GetTopDir())
+	} else {
+		dir, err = readStorageSelection(storage, selector)
+	}
+	if err != nil {
+		return err
+	}
+	add(dir)
+	return nil
+}
+
+// readStorageSelection reads scan with given ID or snapshot with given name,
+// snapshots of more recent scans are preferred when more scans have snapshot of the same name
+func readStorageSelection(storage *analyze.Storage, selector string) (gfs.Item, error) {
+	scans, err := storage.ListScans()
+	if err != nil {
+		return nil, err
+	}
+	for _, scan := range scans {
+		if scan.ID == selector {
+			storage.SetScanID(scan.ID)
+			return storage.GetDirForPath(scan.Root)
+		}
+	}
+
+	for i := len(scans) - 1; i >= 0; i-- {
+		storage.SetScanID(scans[i].ID)
+		snapshots, err := storage.ListSnapshots()
+		if err != nil {
+			return nil, err
+		}
+		for _, snapshot := range snapshots {
+			if snapshot.Name == selector {
+				return storage.GetSnapshotDir(selector)
+			}
+		}
+	}
+	return nil, fmt.Errorf("no scan or snapshot %s in the storage", selector)
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"gopkg.in/yaml.v3"
+
+	"github.com/dundee/gdu/v5/tui"
+)
+
+// Keys is a list of keys bound to an action of the keymap,
+// it can be written as a single key or a list of keys in the config file
+type Keys []string
+
+// UnmarshalYAML reads a single key or a list of keys
+func (k *Keys) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*k = Keys{value.Value}
+		return nil
+	}
+	var keys []string
+	if err := value.Decode(&keys); err != nil {
+		return err
+	}
+	*k = keys
+	return nil
+}
+
+// getKeymapOverrides returns key bindings set in the config
+func (a *App) getKeymapOverrides() map[string][]string {
+	overrides := make(map[string][]string, len(a.Flags.Keymap))
+	for action, keys := range a.Flags.Keymap {
+		overrides[action] = keys
+	}
+	return overrides
+}
+
+// CustomAction defines command run on the selected item in interactive mode
+type CustomAction struct {
+	Key        string `yaml:"key"`
+	Label      string `yaml:"label"`
+	Command    string `yaml:"command"`
+	Foreground bool   `yaml:"foreground"`
+	Rescan     bool   `yaml:"rescan"`
+}
+
+func (a *App) getCustomActions() []tui.CustomAction {
+	actions := make([]tui.CustomAction, 0, len(a.Flags.CustomActions))
+	for _, action := range a.Flags.CustomActions {
+		actions = append(actions, tui.CustomAction(action))
+	}
+	return actions
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dundee/gdu/v5/report"
+)
+
+const mergedRootName = "merged"
+
+func (a *App) runMerge() error {
+	if len(a.Args) < 2 {
+		return errors.New("merge requires at least two analyses")
+	}
+
+	inputs := make([]report.MergeInput, 0, len(a.Args))
+	for _, source := range a.Args {
+		input, err := readMergeInput(source)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", source, err)
+		}
+		inputs = append(inputs, input)
+	}
+	makeMergeNamesUnique(inputs, a.Args)
+
+	root, conflicts := report.MergeAnalyses(inputs, mergedRootName, a.Flags.MergeOverlay)
+	for _, conflict := range conflicts {
+		log.Printf("Merge conflict: %s", conflict)
+		fmt.Fprintf(a.Writer, "Conflict: %s\n", conflict)
+	}
+
+	// merged paths don't have to exist locally
+	a.Flags.NoDelete = true
+
+	ui, err := a.createUI()
+	if err != nil {
+		return err
+	}
+	if err := ui.ShowAnalysis(root); err != nil {
+		return err
+	}
+	return ui.StartUILoop()
+}
+
+// readMergeInput reads analysis from JSON file (or standard input if source is "-")
+// and names it by the file name without extension
+func readMergeInput(source string) (report.MergeInput, error) {
+	var input io.Reader = os.Stdin
+	name := "stdin"
+
+	if source != "-" {
+		f, err := os.Open(source)
+		if err != nil {
+			return report.MergeInput{}, fmt.Errorf("opening input file: %w", err)
+		}
+		defer f.Close()
+		input = f
+
+		name = filepath.Base(source)
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+
+	dir, err := report.ReadAnalysis(input)
+	if err != nil {
+		return report.MergeInput{}, err
+	}
+	return report.MergeInput{Dir: dir, Name: name}, nil
+}
+
+// makeMergeNamesUnique suffixes names shared by more inputs with the name of the parent directory
+// of the input file (e.g. "export-a" and "export-b" for "a/export.json" and "b/export.json")
+// or with the input's order if that's not enough
+func makeMergeNamesUnique(inputs []report.MergeInput, sources []string) {
+	counts := make(map[string]int, len(inputs))
+	for _, input := range inputs {
+		counts[input.Name]++
+	}
+
+	used := make(map[string]bool, len(inputs))
+	for i := range inputs {
+		name := inputs[i].Name
+		if counts[name] > 1 && sources[i] != "-" {
+			if path, err := filepath.Abs(sources[i]); err == nil {
+				parent := filepath.Base(filepath.Dir(path))
+				if parent != string(filepath.Separator) {
+					name += "-" + parent
+				}
+			}
+		}
+		if used[name] {
+			name = fmt.Sprintf("%s-%d", name, i+1)
+		}
+		used[name] = true
+		inputs[i].Name = name
+	}
+}
@@ -0,0 +1,241 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/dundee/gdu/v5/internal/common"
+	"github.com/dundee/gdu/v5/pkg/analyze"
+)
+
+// getStorageScanOptions returns options which make the stored scan differ
+// from other scans of the same directory
+func (a *App) getStorageScanOptions() map[string]string {
+	options := a.getScanOptions()
+	delete(options, "read-from-storage")
+	return options
+}
+
+// checkStorageBackend checks that the selected backend exists
+// and keeps data between runs if the stored data are read
+func (a *App) checkStorageBackend() error {
+	if a.Flags.StorageBackend == "" {
+		a.Flags.StorageBackend = analyze.DefaultStorageBackend
+	}
+	if !slices.Contains(analyze.StorageBackends(), a.Flags.StorageBackend) {
+		return fmt.Errorf(
+			"unknown storage backend: %s (available: %s)",
+			a.Flags.StorageBackend, strings.Join(analyze.StorageBackends(), ", "),
+		)
+	}
+	readsStorage := a.Flags.ReadFromStorage || a.Flags.Diff || a.isStorageMaintenance() ||
+		a.Flags.SnapshotList || a.Flags.SnapshotPrune > 0
+	if a.Flags.StorageBackend == "memory" && readsStorage {
+		return fmt.Errorf("memory storage backend doesn't keep data between runs, it can be used only with --use-storage")
+	}
+	return nil
+}
+
+func (a *App) isStorageMaintenance() bool {
+	return a.Flags.StorageList || a.Flags.StorageInfo || a.Flags.StorageGC ||
+		a.Flags.StorageDelete != "" || a.Flags.StorageVerify
+}
+
+// runStorageMaintenance deletes scans, releases unused space, verifies stored scans
+// and prints information about the persistent storage
+func (a *App) runStorageMaintenance() error {
+	storage, closeFn, err := openExistingStorage(a.Flags.StoragePath)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	if a.Flags.StorageDelete != "" {
+		if err := a.deleteStoredScans(storage); err != nil {
+			return err
+		}
+	}
+	if a.Flags.StorageGC {
+		before, err := storage.GetInfo()
+		if err != nil {
+			return err
+		}
+		if err := storage.Compact(); err != nil {
+			return err
+		}
+		after, err := storage.GetInfo()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(
+			a.Writer, "Storage size reduced from %s B to %s B\n",
+			common.FormatNumber(before.DiskSize), common.FormatNumber(after.DiskSize),
+		)
+	}
+	if a.Flags.StorageVerify {
+		if err := a.verifyStorage(storage); err != nil {
+			return err
+		}
+	}
+	if a.Flags.StorageInfo {
+		info, err := storage.GetInfo()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(a.Writer, "Path:  %s\n", a.Flags.StoragePath)
+		fmt.Fprintf(a.Writer, "Size:  %s B\n", common.FormatNumber(info.DiskSize))
+		fmt.Fprintf(a.Writer, "Keys:  %s\n", common.FormatNumber(int64(info.KeyCount)))
+		fmt.Fprintf(a.Writer, "Scans: %d\n", len(info.Scans))
+		a.printScans(info.Scans)
+	} else if a.Flags.StorageList {
+		scans, err := storage.ListScans()
+		if err != nil {
+			return fmt.Errorf("listing scans: %w", err)
+		}
+		if len(scans) == 0 {
+			fmt.Fprintln(a.Writer, "No scans in", a.Flags.StoragePath)
+		}
+		a.printScans(scans)
+	}
+	return nil
+}
+
+// deleteStoredScans deletes scans of the root given by --storage-delete or the scan with given ID
+func (a *App) deleteStoredScans(storage *analyze.Storage) error {
+	scans, err := storage.ListScans()
+	if err != nil {
+		return fmt.Errorf("listing scans: %w", err)
+	}
+
+	root := a.Flags.StorageDelete
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+
+	deleted := 0
+	for _, scan := range scans {
+		if scan.ID != a.Flags.StorageDelete && scan.Root != root {
+			continue
+		}
+		if err := storage.DeleteScan(scan.ID); err != nil {
+			return fmt.Errorf("deleting scan %s: %w", scan.ID, err)
+		}
+		fmt.Fprintf(a.Writer, "Deleted scan %s of %s\n", scan.ID, scan.Root)
+		deleted++
+	}
+	if deleted == 0 {
+		return fmt.Errorf("no scan of %s in the storage", a.Flags.StorageDelete)
+	}
+	return nil
+}
+
+// verifyStorage checks that all directories of all scans can be loaded
+func (a *App) verifyStorage(storage *analyze.Storage) error {
+	scans, err := storage.ListScans()
+	if err != nil {
+		return fmt.Errorf("listing scans: %w", err)
+	}
+
+	count := 0
+	for _, scan := range scans {
+		for _, problem := range storage.VerifyScan(scan) {
+			fmt.Fprintf(a.Writer, "Scan %s of %s: %s\n", scan.ID, scan.Root, problem)
+			count++
+		}
+	}
+	if count > 0 {
+		return fmt.Errorf("storage verification found %d problems", count)
+	}
+	fmt.Fprintf(a.Writer, "Verified %d scans, no problems found\n", len(scans))
+	return nil
+}
+
+func (a *App) printScans(scans []analyze.ScanInfo) {
+	for _, scan := range scans {
+		fmt.Fprintf(
+			a.Writer, "%s  %s  %15s B  %10s items  %s%s\n",
+			scan.ID,
+			scan.Time.Format("2006-01-02 15:04:05"),
+			common.FormatNumber(scan.Usage),
+			common.FormatNumber(int64(scan.ItemCount)),
+			scan.Root,
+			formatScanOptions(scan.Options),
+		)
+	}
+}
+
+func formatScanOptions(options map[string]string) string {
+	if len(options) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(options))
+	for k, v := range options {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// runSnapshots lists or prunes snapshots of all scans kept in the persistent storage
+func (a *App) runSnapshots() error {
+	storage, closeFn, err := openExistingStorage(a.Flags.StoragePath)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	scans, err := storage.ListScans()
+	if err != nil {
+		return fmt.Errorf("listing scans: %w", err)
+	}
+
+	for _, scan := range scans {
+		storage.SetScanID(scan.ID)
+
+		if a.Flags.SnapshotPrune > 0 {
+			removed, err := storage.PruneSnapshots(a.Flags.SnapshotPrune)
+			if err != nil {
+				return fmt.Errorf("pruning snapshots: %w", err)
+			}
+			for _, snapshot := range removed {
+				fmt.Fprintf(a.Writer, "Removed snapshot %s of %s\n", snapshot.Name, scan.Root)
+			}
+		}
+
+		if !a.Flags.SnapshotList {
+			continue
+		}
+
+		snapshots, err := storage.ListSnapshots()
+		if err != nil {
+			return fmt.Errorf("listing snapshots: %w", err)
+		}
+		fmt.Fprintf(a.Writer, "%s (%s)\n", scan.Root, scan.ID)
+		for _, snapshot := range snapshots {
+			fmt.Fprintf(
+				a.Writer, "  %-24s %s\n",
+				snapshot.Name, snapshot.Time.Format("2006-01-02 15:04:05"),
+			)
+		}
+	}
+
+	if a.Flags.SnapshotList && len(scans) == 0 {
+		fmt.Fprintln(a.Writer, "No snapshots in", a.Flags.StoragePath)
+	}
+	return nil
+}
+
+// openExistingStorage opens persistent storage which has been already created by gdu
+func openExistingStorage(storagePath string) (*analyze.Storage, func(), error) {
+	// do not let badger create new storage in arbitrary directory
+	if _, err := os.Stat(filepath.Join(storagePath, "MANIFEST")); err != nil {
+		return nil, nil, fmt.Errorf("%s is not a storage directory", storagePath)
+	}
+
+	storage := analyze.NewStorage(storagePath, "")
+	return storage, storage.Open(), nil
+}
@@ -16,6 +16,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/dundee/gdu/v5/cmd/gdu/app"
+	"github.com/dundee/gdu/v5/pkg/analyze"
 	"github.com/dundee/gdu/v5/pkg/device"
 )
 
@@ -37,6 +38,77 @@ However HDDs work as well, but the performance gain is not so huge.
 	RunE:         runE,
 }
 
+var diffCmd = &cobra.Command{
+	Use:   "diff old_analysis new_analysis",
+	Short: "Compare two analyses of the same directory",
+	Long: `Compare two analyses of the same directory and show added, removed and changed items.
+
+Analysis can be a JSON file created with --output-file ("-" reads standard input)
+or a directory with persistent key-value storage created with --use-storage
+(its most recent scan is compared).
+Scan or snapshot kept in the storage can be selected as storage_dir@scan_id
+or storage_dir@snapshot_name, only directories are compared for snapshots.
+Items are matched by path relative to the analyzed directory,
+so analyses taken under different mount points can be compared.
+`,
+	Args:         subcommandArgs(cobra.ExactArgs(2)),
+	SilenceUsage: true,
+	RunE: func(command *cobra.Command, args []string) error {
+		if isDirOfSubcommand(command, args) {
+			return runE(command, []string{command.Name()})
+		}
+		af.Diff = true
+		return runE(command, args)
+	},
+}
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge analysis_file analysis_file...",
+	Short: "Merge several analyses into one tree",
+	Long: `Merge several JSON analyses created with --output-file ("-" reads standard input) into one tree.
+
+By default every analysis is placed under a synthetic root directory
+as a directory named by its file name without extension
+(with name of its parent directory appended if more analyses share the file name).
+With --overlay, analyses are overlaid by their paths under their deepest common directory.
+Paths claimed by more analyses are reported as conflicts and the item from the analysis given first is kept.
+
+The merged tree can be browsed interactively, printed or exported with --output-file.
+Deletion is disabled as the merged paths don't have to exist locally.
+`,
+	Args:         subcommandArgs(cobra.MinimumNArgs(2)),
+	SilenceUsage: true,
+	RunE: func(command *cobra.Command, args []string) error {
+		if isDirOfSubcommand(command, args) {
+			return runE(command, []string{command.Name()})
+		}
+		af.Merge = true
+		return runE(command, args)
+	},
+}
+
+// subcommandArgs validates arguments of subcommand
+// unless the subcommand stands for a directory of the same name (see isDirOfSubcommand)
+func subcommandArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(command *cobra.Command, args []string) error {
+		if isDirOfSubcommand(command, args) {
+			return nil
+		}
+		return validate(command, args)
+	}
+}
+
+// isDirOfSubcommand returns true if subcommand is given without arguments
+// and a directory of its name exists in the working directory,
+// so that e.g. `gdu diff` keeps analyzing the directory as before the subcommand was added
+func isDirOfSubcommand(command *cobra.Command, args []string) bool {
+	if len(args) > 0 {
+		return false
+	}
+	info, err := os.Stat(command.Name())
+	return err == nil && info.IsDir()
+}
+
 func getDefaultLogFile() string {
 	if runtime.GOOS == "windows" {
 		return "NUL"
@@ -64,7 +136,12 @@ func init() {
 	flags.StringVar(&af.CfgFile, "config-file", "", "Read config from file (default is $HOME/.gdu.yaml)")
 	flags.StringVarP(&af.LogFile, "log-file", "l", getDefaultLogFile(), "Path to a logfile")
 	flags.StringVarP(&af.OutputFile, "output-file", "o", "", "Export all info into file as JSON")
-	flags.StringVarP(&af.InputFile, "input-file", "f", "", "Import analysis from JSON file")
+	flags.StringVar(&af.OutputFormat, "output-format", "json", "Format of the exported file (json, csv, tsv, html, prometheus, folded, sqlite)")
+	flags.IntVar(&af.MaxDepth, "max-depth", 0, "Export only items up to given depth in flat and prometheus output formats (0 = no limit)")
+	flags.BoolVar(&af.DirsOnly, "dirs-only", false, "Export only directories in flat output formats")
+	flags.StringVar(&af.MinSize, "min-size", "", "Merge items smaller than given size (e.g. 10M, 1G) in the HTML and folded reports")
+	flags.StringVarP(&af.InputFile, "input-file", "f", "", "Import analysis from JSON file or SQLite database")
+	flags.StringVar(&af.InputFormat, "input-format", "json", "Format of the imported file (json, du-b, du-k, find)")
 	flags.IntVarP(&af.MaxCores, "max-cores", "m", runtime.NumCPU(), fmt.Sprintf("Set max cores that Gdu will use. %d cores available", runtime.NumCPU()))
 	flags.BoolVar(&af.SequentialScanning, "sequential", false, "Use sequential scanning (intended for rotating HDDs)")
 	flags.BoolVarP(&af.ShowVersion, "version", "v", false, "Print version")
@@ -90,7 +167,19 @@ func init() {
 
 	flags.BoolVar(&af.UseStorage, "use-storage", false, "Use persistent key-value storage for analysis data (experimental)")
 	flags.StringVar(&af.StoragePath, "storage-path", getDefaultStoragePath(), "Path to persistent key-value storage directory")
+	flags.StringVar(
+		&af.StorageBackend, "storage-backend", analyze.DefaultStorageBackend,
+		"Backend of persistent key-value storage ("+strings.Join(analyze.StorageBackends(), ", ")+")",
+	)
 	flags.BoolVarP(&af.ReadFromStorage, "read-from-storage", "r", false, "Read analysis data from persistent key-value storage")
+	flags.BoolVar(&af.StorageList, "storage-list", false, "List scans kept in persistent key-value storage")
+	flags.BoolVar(&af.StorageInfo, "storage-info", false, "Show size, number of keys and scans of persistent key-value storage")
+	flags.BoolVar(&af.StorageGC, "storage-gc", false, "Compact persistent key-value storage and release unused space")
+	flags.StringVar(&af.StorageDelete, "storage-delete", "", "Delete scans of given directory (or scan with given ID) from persistent key-value storage")
+	flags.BoolVar(&af.StorageVerify, "storage-verify", false, "Verify that all directories kept in persistent key-value storage can be loaded")
+	flags.StringVar(&af.SnapshotName, "snapshot-name", "", "Name of the snapshot recorded into persistent storage (default is time of the analysis)")
+	flags.BoolVar(&af.SnapshotList, "snapshot-list", false, "List snapshots kept in persistent key-value storage")
+	flags.IntVar(&af.SnapshotPrune, "snapshot-prune", 0, "Remove all but given number of the newest snapshots from persistent storage")
 	flags.BoolVar(&af.ArchiveBrowsing, "archive-browsing", false, "Enable browsing of zip/jar archives")
 	flags.BoolVar(&af.CollapsePath, "collapse-path", false, "Collapse single-child directory chains")
 
@@ -100,6 +189,7 @@ func init() {
 	flags.BoolVarP(&af.NoColor, "no-color", "c", false, "Do not use colorized output")
 	flags.BoolVarP(&af.ShowItemCount, "show-item-count", "C", false, "Show number of items in directory")
 	flags.BoolVarP(&af.ShowMTime, "show-mtime", "M", false, "Show latest mtime of items in directory")
+	flags.StringVar(&af.Theme, "theme", "", "Colour theme (default, light, gruvbox, nord or a theme defined in the config)")
 	flags.BoolVarP(&af.NonInteractive, "non-interactive", "n", false, "Do not run in interactive mode")
 	flags.BoolVarP(&af.NoProgress, "no-progress", "p", false, "Do not show progress in non-interactive mode")
 	flags.BoolVarP(&af.NoUnicode, "no-unicode", "u", false, "Do not use Unicode symbols (for size bar)")
@@ -122,8 +212,39 @@ func init() {
 	flags.StringVar(&af.MaxAge, "max-age", "", "Include files with mtime no older than DURATION (e.g., 7d, 2h30m, 1y2mo)")
 	flags.StringVar(&af.MinAge, "min-age", "", "Include files with mtime at least DURATION old (e.g., 30d, 1w)")
 
+	diffFlags := diffCmd.Flags()
+	diffFlags.StringVar(&af.CfgFile, "config-file", "", "Read config from file (default is $HOME/.gdu.yaml)")
+	diffFlags.StringVarP(&af.LogFile, "log-file", "l", getDefaultLogFile(), "Path to a logfile")
+	diffFlags.IntVarP(&af.Top, "top", "t", 0, "Show only top X biggest changes in non-interactive mode (default 20)")
+	diffFlags.BoolVarP(&af.NonInteractive, "non-interactive", "n", false, "Do not run in interactive mode")
+	diffFlags.BoolVarP(&af.ShowApparentSize, "show-apparent-size", "a", false, "Compare apparent sizes")
+	diffFlags.BoolVarP(&af.NoColor, "no-color", "c", false, "Do not use colorized output")
+	diffFlags.BoolVar(&af.UseSIPrefix, "si", false, "Show sizes with decimal SI prefixes (kB, MB, GB) instead of binary prefixes (KiB, MiB, GiB)")
+	diffFlags.BoolVar(&af.NoPrefix, "no-prefix", false, "Show sizes as raw numbers without any prefixes (SI or binary) in non-interactive mode")
+	diffFlags.BoolVar(&af.Mouse, "mouse", false, "Use mouse")
+	rootCmd.AddCommand(diffCmd)
+
+	mergeFlags := mergeCmd.Flags()
+	mergeFlags.StringVar(&af.CfgFile, "config-file", "", "Read config from file (default is $HOME/.gdu.yaml)")
+	mergeFlags.StringVarP(&af.LogFile, "log-file", "l", getDefaultLogFile(), "Path to a logfile")
+	mergeFlags.BoolVar(&af.MergeOverlay, "overlay", false, "Overlay analyses by their paths instead of placing them under a synthetic root")
+	mergeFlags.StringVarP(&af.OutputFile, "output-file", "o", "", "Export merged tree into file")
+	mergeFlags.StringVar(&af.OutputFormat, "output-format", "json", "Format of the exported file (json, csv, tsv, html, prometheus, folded, sqlite)")
+	mergeFlags.IntVar(&af.MaxDepth, "max-depth", 0, "Export only items up to given depth in flat and prometheus output formats (0 = no limit)")
+	mergeFlags.BoolVar(&af.DirsOnly, "dirs-only", false, "Export only directories in flat output formats")
+	mergeFlags.StringVar(&af.MinSize, "min-size", "", "Merge items smaller than given size (e.g. 10M, 1G) in the HTML and folded reports")
+	mergeFlags.BoolVarP(&af.NonInteractive, "non-interactive", "n", false, "Do not run in interactive mode")
+	mergeFlags.BoolVarP(&af.NoProgress, "no-progress", "p", false, "Do not show progress in non-interactive mode")
+	mergeFlags.BoolVarP(&af.Summarize, "summarize", "s", false, "Show only a total in non-interactive mode")
+	mergeFlags.IntVarP(&af.Top, "top", "t", 0, "Show only top X largest files in non-interactive mode")
+	mergeFlags.BoolVarP(&af.ShowApparentSize, "show-apparent-size", "a", false, "Show apparent size")
+	mergeFlags.BoolVarP(&af.NoColor, "no-color", "c", false, "Do not use colorized output")
+	mergeFlags.BoolVar(&af.UseSIPrefix, "si", false, "Show sizes with decimal SI prefixes (kB, MB, GB) instead of binary prefixes (KiB, MiB, GiB)")
+	mergeFlags.BoolVar(&af.NoPrefix, "no-prefix", false, "Show sizes as raw numbers without any prefixes (SI or binary) in non-interactive mode")
+	mergeFlags.BoolVar(&af.Mouse, "mouse", false, "Use mouse")
+	rootCmd.AddCommand(mergeCmd)
+
 	initConfig()
-	setDefaults()
 }
 
 func initConfig() {
@@ -137,30 +258,6 @@ func initConfig() {
 	configErr = yaml.Unmarshal(data, &af)
 }
 
-func setDefaults() {
-	if af.Style.Footer.BackgroundColor == "" {
-		af.Style.Footer.BackgroundColor = "#2479D0"
-	}
-	if af.Style.Footer.TextColor == "" {
-		af.Style.Footer.TextColor = "#000000"
-	}
-	if af.Style.Footer.NumberColor == "" {
-		af.Style.Footer.NumberColor = "#FFFFFF"
-	}
-	if af.Style.Header.BackgroundColor == "" {
-		af.Style.Header.BackgroundColor = "#2479D0"
-	}
-	if af.Style.Header.TextColor == "" {
-		af.Style.Header.TextColor = "#000000"
-	}
-	if af.Style.ResultRow.NumberColor == "" {
-		af.Style.ResultRow.NumberColor = "#e67100"
-	}
-	if af.Style.ResultRow.DirectoryColor == "" {
-		af.Style.ResultRow.DirectoryColor = "#3498db"
-	}
-}
-
 func setConfigFilePath() {
 	command := strings.Join(os.Args, " ")
 	if strings.Contains(command, "--config-file") {
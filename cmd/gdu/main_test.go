@@ -0,0 +1,29 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubcommandWithoutArgsAnalyzesDirOfSameName(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	assert.False(t, isDirOfSubcommand(diffCmd, []string{}))
+	assert.Error(t, diffCmd.Args(diffCmd, []string{}))
+	assert.Error(t, mergeCmd.Args(mergeCmd, []string{}))
+
+	assert.Nil(t, os.Mkdir("diff", 0o755))
+	assert.Nil(t, os.WriteFile("merge", []byte{}, 0o600))
+
+	assert.True(t, isDirOfSubcommand(diffCmd, []string{}))
+	assert.Nil(t, diffCmd.Args(diffCmd, []string{}))
+	assert.False(t, isDirOfSubcommand(diffCmd, []string{"a.json", "b.json"}))
+	assert.Nil(t, diffCmd.Args(diffCmd, []string{"a.json", "b.json"}))
+	assert.Error(t, diffCmd.Args(diffCmd, []string{"a.json"}))
+
+	// file of the same name is not analyzed
+	assert.False(t, isDirOfSubcommand(mergeCmd, []string{}))
+	assert.Error(t, mergeCmd.Args(mergeCmd, []string{}))
+}
@@ -0,0 +1,56 @@
+// Package common contains commong logic and interfaces used across Gdu
+// nolint: revive //Why: this is common package
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var sizeSuffixes = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   Ki,
+	"kb":  K,
+	"kib": Ki,
+	"m":   Mi,
+	"mb":  M,
+	"mib": Mi,
+	"g":   Gi,
+	"gb":  G,
+	"gib": Gi,
+	"t":   Ti,
+	"tb":  T,
+	"tib": Ti,
+	"p":   Pi,
+	"pb":  P,
+	"pib": Pi,
+}
+
+// ParseSize parses human readable size (e.g. 100, 10K, 1.5GiB, 2GB) into number of bytes.
+// Single letter suffixes use binary prefixes.
+func ParseSize(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	i := strings.IndexFunc(value, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i == -1 {
+		i = len(value)
+	}
+
+	number, err := strconv.ParseFloat(value[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size: %s", value)
+	}
+	multiplier, ok := sizeSuffixes[strings.ToLower(strings.TrimSpace(value[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit: %s", value)
+	}
+
+	return int64(number * multiplier), nil
+}
@@ -0,0 +1,36 @@
+package common_test
+
+import (
+	"testing"
+
+	"github.com/dundee/gdu/v5/internal/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSize(t *testing.T) {
+	for value, expected := range map[string]int64{
+		"":       0,
+		"100":    100,
+		"10B":    10,
+		"1k":     1024,
+		"1kB":    1000,
+		"1.5M":   1572864,
+		"2 GiB":  2147483648,
+		"1GB":    1000000000,
+		"1t":     1099511627776,
+		" 3pb ":  3000000000000000,
+		"0.5Kib": 512,
+	} {
+		size, err := common.ParseSize(value)
+		assert.Nil(t, err, value)
+		assert.Equal(t, expected, size, value)
+	}
+}
+
+func TestParseSizeWithErr(t *testing.T) {
+	_, err := common.ParseSize("xx")
+	assert.ErrorContains(t, err, "invalid size: xx")
+
+	_, err = common.ParseSize("10X")
+	assert.ErrorContains(t, err, "invalid size unit: 10X")
+}
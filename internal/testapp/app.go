@@ -98,6 +98,15 @@ func (app *MockedApp) GetUpdateDraws() []func() {
 	return app.updateDraws
 }
 
+// PopUpdateDraws returns queued update draws and clears the queue
+func (app *MockedApp) PopUpdateDraws() []func() {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	updateDraws := app.updateDraws
+	app.updateDraws = nil
+	return updateDraws
+}
+
 // SetBeforeDrawFunc does nothing
 func (app *MockedApp) SetBeforeDrawFunc(f func(screen tcell.Screen) bool) *tview.Application {
 	app.BeforeDraws = append(app.BeforeDraws, f)
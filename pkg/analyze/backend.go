@@ -0,0 +1,130 @@
+package analyze
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultStorageBackend is the name of the backend used when no other is selected
+const DefaultStorageBackend = "badger"
+
+// ErrKeyNotFound is returned by backends when the key is not stored
+var ErrKeyNotFound = errors.New("Key not found")
+
+// Backend is a key-value store the storage keeps its data in
+type Backend interface {
+	// Get returns value of the key or ErrKeyNotFound
+	Get(key []byte) ([]byte, error)
+	Set(key, value []byte) error
+	Delete(key []byte) error
+	DropPrefix(prefixes ...[]byte) error
+	// IteratePrefix calls fn for all keys with given prefix in the order of keys.
+	// The key and value are valid only during the call.
+	IteratePrefix(prefix []byte, fn func(key, value []byte) error) error
+	NewBatch() Batch
+	// Size returns number of bytes occupied by the stored data
+	Size() (int64, error)
+	Close() error
+}
+
+// Batch groups writes which are stored when the batch is flushed
+type Batch interface {
+	Set(key, value []byte) error
+	Flush() error
+}
+
+// Compacter is implemented by backends able to release space of deleted data
+type Compacter interface {
+	Compact() error
+}
+
+// OpenBackendFn opens backend keeping its data in given directory
+type OpenBackendFn func(path string) (Backend, error)
+
+var (
+	backends = map[string]OpenBackendFn{
+		"badger": openBadgerBackend,
+		"memory": openMemoryBackend,
+	}
+	backendsM sync.RWMutex
+)
+
+// RegisterStorageBackend makes backend available under given name
+func RegisterStorageBackend(name string, open OpenBackendFn) {
+	backendsM.Lock()
+	defer backendsM.Unlock()
+	backends[name] = open
+}
+
+// StorageBackends returns sorted names of available backends
+func StorageBackends() []string {
+	backendsM.RLock()
+	defer backendsM.RUnlock()
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sharedBackend is backend opened once per storage directory
+// and shared by all storages using the directory
+type sharedBackend struct {
+	backend Backend
+	key     string
+	refs    int
+}
+
+// badger doesn't allow to open the same directory twice
+var (
+	openedBackends  = make(map[string]*sharedBackend)
+	openedBackendsM sync.Mutex
+)
+
+func acquireBackend(name, storagePath string) (*sharedBackend, error) {
+	path, err := filepath.Abs(storagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	backendsM.RLock()
+	open, ok := backends[name]
+	backendsM.RUnlock()
+	if !ok {
+		return nil, errors.New("unknown storage backend: " + name)
+	}
+
+	openedBackendsM.Lock()
+	defer openedBackendsM.Unlock()
+
+	key := name + "\x00" + path
+	if shared, ok := openedBackends[key]; ok {
+		shared.refs++
+		return shared, nil
+	}
+
+	backend, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	shared := &sharedBackend{backend: backend, key: key, refs: 1}
+	openedBackends[key] = shared
+	return shared, nil
+}
+
+func (b *sharedBackend) release() {
+	openedBackendsM.Lock()
+	defer openedBackendsM.Unlock()
+
+	b.refs--
+	if b.refs > 0 {
+		return
+	}
+	delete(openedBackends, b.key)
+	b.backend.Close()
+}
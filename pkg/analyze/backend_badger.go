@@ -0,0 +1,121 @@
+package analyze
+
+import (
+	"io/fs"
+	"path/filepath"
+	"runtime"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/pkg/errors"
+)
+
+// badgerBackend keeps data in badger DB
+type badgerBackend struct {
+	db   *badger.DB
+	path string
+}
+
+func openBadgerBackend(path string) (Backend, error) {
+	options := badger.DefaultOptions(path)
+	options.Logger = nil
+	db, err := badger.Open(options)
+	if err != nil {
+		return nil, err
+	}
+	return &badgerBackend{db: db, path: path}, nil
+}
+
+func (b *badgerBackend) Get(key []byte) ([]byte, error) {
+	var value []byte
+	err := b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key)
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return ErrKeyNotFound
+		}
+		if err != nil {
+			return err
+		}
+		value, err = item.ValueCopy(nil)
+		return err
+	})
+	return value, err
+}
+
+func (b *badgerBackend) Set(key, value []byte) error {
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(key, value)
+	})
+}
+
+func (b *badgerBackend) Delete(key []byte) error {
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(key)
+	})
+}
+
+func (b *badgerBackend) DropPrefix(prefixes ...[]byte) error {
+	return b.db.DropPrefix(prefixes...)
+}
+
+func (b *badgerBackend) IteratePrefix(prefix []byte, fn func(key, value []byte) error) error {
+	return b.db.View(func(txn *badger.Txn) error {
+		options := badger.DefaultIteratorOptions
+		options.Prefix = prefix
+		it := txn.NewIterator(options)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			err := item.Value(func(val []byte) error {
+				return fn(item.Key(), val)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// NewBatch returns batch which writes the values in the background
+func (b *badgerBackend) NewBatch() Batch {
+	return b.db.NewWriteBatch()
+}
+
+// Size returns size of all files in the storage directory
+func (b *badgerBackend) Size() (int64, error) {
+	var size int64
+	err := filepath.WalkDir(b.path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+// Compact merges all levels of the LSM tree and rewrites value log files
+// so space of deleted and overwritten entries is released
+func (b *badgerBackend) Compact() error {
+	if err := b.db.Flatten(runtime.GOMAXPROCS(0)); err != nil {
+		return errors.Wrap(err, "flattening storage")
+	}
+	for {
+		err := b.db.RunValueLogGC(0.5)
+		if errors.Is(err, badger.ErrNoRewrite) {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "running value log GC")
+		}
+	}
+}
+
+func (b *badgerBackend) Close() error {
+	return b.db.Close()
+}
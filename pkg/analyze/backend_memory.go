@@ -0,0 +1,144 @@
+package analyze
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// memory backends are kept for the whole run of the program
+// so the data survive closing of the storage
+var (
+	memoryBackends  = make(map[string]*memoryBackend)
+	memoryBackendsM sync.Mutex
+)
+
+// memoryBackend keeps data in memory only
+type memoryBackend struct {
+	data map[string][]byte
+	m    sync.RWMutex
+}
+
+func openMemoryBackend(path string) (Backend, error) {
+	memoryBackendsM.Lock()
+	defer memoryBackendsM.Unlock()
+
+	if backend, ok := memoryBackends[path]; ok {
+		return backend, nil
+	}
+	backend := &memoryBackend{data: make(map[string][]byte)}
+	memoryBackends[path] = backend
+	return backend, nil
+}
+
+func (b *memoryBackend) Get(key []byte) ([]byte, error) {
+	b.m.RLock()
+	defer b.m.RUnlock()
+
+	value, ok := b.data[string(key)]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return bytes.Clone(value), nil
+}
+
+func (b *memoryBackend) Set(key, value []byte) error {
+	b.m.Lock()
+	defer b.m.Unlock()
+	b.data[string(key)] = bytes.Clone(value)
+	return nil
+}
+
+func (b *memoryBackend) Delete(key []byte) error {
+	b.m.Lock()
+	defer b.m.Unlock()
+	delete(b.data, string(key))
+	return nil
+}
+
+func (b *memoryBackend) DropPrefix(prefixes ...[]byte) error {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	for key := range b.data {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(key, string(prefix)) {
+				delete(b.data, key)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (b *memoryBackend) IteratePrefix(prefix []byte, fn func(key, value []byte) error) error {
+	b.m.RLock()
+	keys := make([]string, 0)
+	for key := range b.data {
+		if strings.HasPrefix(key, string(prefix)) {
+			keys = append(keys, key)
+		}
+	}
+	values := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		values[key] = b.data[key]
+	}
+	b.m.RUnlock()
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := fn([]byte(key), values[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *memoryBackend) NewBatch() Batch {
+	return &memoryBatch{backend: b, data: make(map[string][]byte)}
+}
+
+// Size returns length of all stored keys and values
+func (b *memoryBackend) Size() (int64, error) {
+	b.m.RLock()
+	defer b.m.RUnlock()
+
+	var size int64
+	for key, value := range b.data {
+		size += int64(len(key) + len(value))
+	}
+	return size, nil
+}
+
+// Close keeps the data so they can be read when the storage is opened again
+func (b *memoryBackend) Close() error {
+	return nil
+}
+
+// memoryBatch stores the values into the backend when flushed
+type memoryBatch struct {
+	backend *memoryBackend
+	data    map[string][]byte
+	m       sync.Mutex
+}
+
+func (b *memoryBatch) Set(key, value []byte) error {
+	b.m.Lock()
+	defer b.m.Unlock()
+	b.data[string(key)] = bytes.Clone(value)
+	return nil
+}
+
+func (b *memoryBatch) Flush() error {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	b.backend.m.Lock()
+	defer b.backend.m.Unlock()
+	for key, value := range b.data {
+		b.backend.data[key] = value
+	}
+	b.data = make(map[string][]byte)
+	return nil
+}
@@ -0,0 +1,108 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func TestBackends(t *testing.T) {
+	for _, name := range []string{"badger", "memory"} {
+		t.Run(name, func(t *testing.T) {
+			shared, err := acquireBackend(name, t.TempDir())
+			assert.NoError(t, err)
+			defer shared.release()
+			backend := shared.backend
+
+			_, err = backend.Get([]byte("a"))
+			assert.ErrorIs(t, err, ErrKeyNotFound)
+
+			assert.NoError(t, backend.Set([]byte("a/2"), []byte("2")))
+			assert.NoError(t, backend.Set([]byte("a/1"), []byte("1")))
+			assert.NoError(t, backend.Set([]byte("b/1"), []byte("3")))
+
+			value, err := backend.Get([]byte("a/1"))
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("1"), value)
+
+			keys := make([]string, 0)
+			err = backend.IteratePrefix([]byte("a/"), func(key, _ []byte) error {
+				keys = append(keys, string(key))
+				return nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"a/1", "a/2"}, keys)
+
+			batch := backend.NewBatch()
+			assert.NoError(t, batch.Set([]byte("c/1"), []byte("4")))
+			assert.NoError(t, batch.Flush())
+			value, err = backend.Get([]byte("c/1"))
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("4"), value)
+
+			assert.NoError(t, backend.Delete([]byte("b/1")))
+			assert.NoError(t, backend.DropPrefix([]byte("a/")))
+
+			keys = keys[:0]
+			err = backend.IteratePrefix(nil, func(key, _ []byte) error {
+				keys = append(keys, string(key))
+				return nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"c/1"}, keys)
+
+			size, err := backend.Size()
+			assert.NoError(t, err)
+			assert.Greater(t, size, int64(0))
+		})
+	}
+}
+
+func TestUnknownBackend(t *testing.T) {
+	_, err := acquireBackend("xxx", t.TempDir())
+	assert.ErrorContains(t, err, "unknown storage backend: xxx")
+}
+
+func TestStorageBackends(t *testing.T) {
+	assert.Equal(t, []string{"badger", "memory"}, StorageBackends())
+}
+
+func TestStoredAnalyzerWithMemoryBackend(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	storagePath := t.TempDir()
+	a := CreateStoredAnalyzer(storagePath)
+	a.SetStorageBackend("memory")
+	dir := a.AnalyzeDir(
+		"test_dir", func(_, _ string) bool { return false }, false,
+	).(*StoredDir)
+	a.GetDone().Wait()
+	dir.UpdateStats(make(fs.HardLinkedItems))
+
+	assert.Equal(t, 5, dir.ItemCount)
+	assert.Equal(t, "nested", dir.GetFiles()[0].GetName())
+
+	// data are kept after the storage is closed
+	storage := NewStorage(storagePath, "")
+	storage.SetBackend("memory")
+	closeFn := storage.Open()
+	defer closeFn()
+
+	assert.NoError(t, storage.SelectScan("test_dir"))
+	nested, err := storage.GetDirForPath("test_dir/nested")
+	assert.NoError(t, err)
+	names := make([]string, 0)
+	for _, file := range nested.(*StoredDir).GetFiles() {
+		names = append(names, file.GetName())
+	}
+	assert.ElementsMatch(t, []string{"subnested", "file2"}, names)
+
+	info, err := storage.GetInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, 5, info.Scans[0].ItemCount)
+	assert.NoError(t, storage.Compact())
+}
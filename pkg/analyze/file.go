@@ -1,6 +1,7 @@
 package analyze
 
 import (
+	"os"
 	"path/filepath"
 	"sync"
 	"time"
@@ -19,6 +20,18 @@ type File struct {
 	Flag   rune
 }
 
+// NewFile creates file item from the file info
+func NewFile(info os.FileInfo, parent fs.Item) *File {
+	file := &File{
+		Name:   info.Name(),
+		Flag:   getFlag(info),
+		Size:   info.Size(),
+		Parent: parent,
+	}
+	setPlatformSpecificAttrs(file, info)
+	return file
+}
+
 // GetName returns name of dir
 func (f *File) GetName() string {
 	return f.Name
@@ -249,6 +262,26 @@ func (f *Dir) RemoveFile(item fs.Item) {
 	}
 }
 
+// AddFileWithStats adds item to dir, updates size and item count
+func (f *Dir) AddFileWithStats(item fs.Item) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.AddFile(item)
+
+	cur := f
+	for {
+		cur.ItemCount += item.GetItemCount()
+		cur.Size += item.GetSize()
+		cur.Usage += item.GetUsage()
+
+		if cur.Parent == nil {
+			break
+		}
+		cur = cur.Parent.(*Dir)
+	}
+}
+
 // RLock read locks dir
 func (f *Dir) RLock() func() {
 	f.m.RLock()
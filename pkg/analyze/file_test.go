@@ -1,6 +1,8 @@
 package analyze
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -230,6 +232,44 @@ func TestUpdateStats(t *testing.T) {
 	assert.Equal(t, 42, dir.GetMtime().Minute())
 }
 
+func TestAddFileWithStats(t *testing.T) {
+	dir := &Dir{
+		File: &File{
+			Name:  "xxx",
+			Size:  5,
+			Usage: 12,
+		},
+		ItemCount: 2,
+	}
+	subdir := &Dir{
+		File: &File{
+			Name:   "yyy",
+			Size:   4,
+			Usage:  8,
+			Parent: dir,
+		},
+		ItemCount: 1,
+	}
+	dir.Files = fs.Files{subdir}
+
+	path := filepath.Join(t.TempDir(), "archive.zip")
+	assert.Nil(t, os.WriteFile(path, []byte("abc"), 0o644))
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+
+	file := NewFile(info, subdir)
+	subdir.AddFileWithStats(file)
+
+	assert.Equal(t, "archive.zip", file.GetName())
+	assert.Equal(t, int64(3), file.GetSize())
+	assert.Equal(t, 1, len(subdir.Files))
+	assert.Equal(t, 2, subdir.ItemCount)
+	assert.Equal(t, int64(7), subdir.Size)
+	assert.Equal(t, 3, dir.ItemCount)
+	assert.Equal(t, int64(8), dir.Size)
+	assert.Equal(t, int64(12)+file.GetUsage(), dir.Usage)
+}
+
 func TestGetMultiLinkedInode(t *testing.T) {
 	file := &File{
 		Name: "xxx",
@@ -0,0 +1,96 @@
+package analyze
+
+import (
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// StorageInfo describes content of the storage
+type StorageInfo struct {
+	DiskSize int64
+	KeyCount int
+	Scans    []ScanInfo
+}
+
+// GetInfo returns size of the stored data, number of stored keys and kept scans
+func (s *Storage) GetInfo() (StorageInfo, error) {
+	var (
+		info StorageInfo
+		err  error
+	)
+
+	info.DiskSize, err = s.getBackend().Size()
+	if err != nil {
+		return info, errors.Wrap(err, "reading size of storage")
+	}
+
+	err = s.getBackend().IteratePrefix(nil, func(_, _ []byte) error {
+		info.KeyCount++
+		return nil
+	})
+	if err != nil {
+		return info, err
+	}
+
+	info.Scans, err = s.ListScans()
+	return info, err
+}
+
+// Compact releases space of deleted and overwritten entries
+// if the backend supports it
+func (s *Storage) Compact() error {
+	if compacter, ok := s.getBackend().(Compacter); ok {
+		return compacter.Compact()
+	}
+	return nil
+}
+
+// DeleteScan removes scan with given ID together with its snapshots
+func (s *Storage) DeleteScan(id string) error {
+	err := s.getBackend().DropPrefix(
+		dirKeysPrefix(id),
+		snapshotKeysPrefix(id),
+		[]byte(snapshotUsageKeyPrefix+id+"\x00"),
+	)
+	if err != nil {
+		return err
+	}
+	return s.getBackend().Delete(scanKey(id))
+}
+
+// VerifyScan checks that all directories of the scan can be loaded
+// and returns the problems found
+func (s *Storage) VerifyScan(info ScanInfo) []error {
+	scanID := s.scanID
+	s.scanID = info.ID
+	defer func() {
+		s.scanID = scanID
+	}()
+
+	var problems []error
+	paths := []string{info.Root}
+	for len(paths) > 0 {
+		path := paths[len(paths)-1]
+		paths = paths[:len(paths)-1]
+
+		dir := &StoredDir{
+			Dir: &Dir{
+				File: &File{
+					Name: filepath.Base(path),
+				},
+				BasePath: filepath.Dir(path),
+			},
+		}
+		if err := s.LoadDir(dir); err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		for _, file := range dir.Files {
+			if file.IsDir() {
+				paths = append(paths, filepath.Join(path, file.GetName()))
+			}
+		}
+	}
+	return problems
+}
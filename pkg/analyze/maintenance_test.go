@@ -0,0 +1,94 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func createStoredScans(t *testing.T, storagePath string) {
+	t.Helper()
+	for _, options := range []map[string]string{nil, {"no-hidden": "true"}} {
+		a := CreateStoredAnalyzer(storagePath)
+		a.SetScanOptions(options)
+		dir := a.AnalyzeDir(
+			"test_dir", func(_, _ string) bool { return false }, false,
+		).(*StoredDir)
+		a.GetDone().Wait()
+		dir.UpdateStats(make(fs.HardLinkedItems))
+	}
+}
+
+func TestStorageGetInfo(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	storagePath := t.TempDir()
+	createStoredScans(t, storagePath)
+
+	storage := NewStorage(storagePath, "")
+	closeFn := storage.Open()
+	defer closeFn()
+
+	info, err := storage.GetInfo()
+	assert.NoError(t, err)
+	assert.Greater(t, info.DiskSize, int64(0))
+	// 2 scans, 3 dirs and 1 snapshot with 3 usages each
+	assert.Equal(t, 2*(1+3+1+3), info.KeyCount)
+	assert.Len(t, info.Scans, 2)
+	assert.Equal(t, 5, info.Scans[0].ItemCount)
+}
+
+func TestStorageDeleteScanAndCompact(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	storagePath := t.TempDir()
+	createStoredScans(t, storagePath)
+
+	storage := NewStorage(storagePath, "")
+	closeFn := storage.Open()
+	defer closeFn()
+
+	scans, err := storage.ListScans()
+	assert.NoError(t, err)
+
+	err = storage.DeleteScan(scans[0].ID)
+	assert.NoError(t, err)
+	assert.NoError(t, storage.Compact())
+
+	info, err := storage.GetInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, 1+3+1+3, info.KeyCount)
+	assert.Len(t, info.Scans, 1)
+	assert.Equal(t, scans[1].ID, info.Scans[0].ID)
+
+	assert.Empty(t, storage.VerifyScan(info.Scans[0]))
+}
+
+func TestStorageVerifyScan(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	storagePath := t.TempDir()
+	createStoredScans(t, storagePath)
+
+	storage := NewStorage(storagePath, "")
+	closeFn := storage.Open()
+	defer closeFn()
+
+	scans, err := storage.ListScans()
+	assert.NoError(t, err)
+	assert.Empty(t, storage.VerifyScan(scans[0]))
+
+	err = storage.getBackend().Delete(dirKey(scans[0].ID, "test_dir/nested/subnested"))
+	assert.NoError(t, err)
+
+	problems := storage.VerifyScan(scans[0])
+	assert.Len(t, problems, 1)
+	assert.ErrorContains(t, problems[0], "test_dir/nested/subnested")
+	assert.Empty(t, storage.VerifyScan(scans[1]))
+}
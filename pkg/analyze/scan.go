@@ -0,0 +1,260 @@
+package analyze
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// all keys start with zero byte followed by the kind of the key,
+// data of each scan are prefixed by the ID of the scan
+const (
+	scanKeyPrefix = "\x00scan\x00"
+	dirKeyPrefix  = "\x00dir\x00"
+)
+
+// ScanInfo describes scan kept in the storage
+type ScanInfo struct {
+	ID        string
+	Root      string
+	Options   map[string]string
+	Time      time.Time
+	Size      int64
+	Usage     int64
+	ItemCount int
+}
+
+// ScanID returns ID of the scan of given root made with given options.
+// Scans of the same root made with different options are kept apart.
+func ScanID(root string, options map[string]string) string {
+	keys := make([]string, 0, len(options))
+	for k := range options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	h.Write([]byte(root))
+	for _, k := range keys {
+		h.Write([]byte("\x00" + k + "=" + options[k]))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+func scanKey(id string) []byte {
+	return []byte(scanKeyPrefix + id)
+}
+
+func dirKeysPrefix(id string) []byte {
+	return []byte(dirKeyPrefix + id + "\x00")
+}
+
+func dirKey(id, path string) []byte {
+	return append(dirKeysPrefix(id), path...)
+}
+
+// errStopIteration stops iteration over the keys early
+var errStopIteration = errors.New("stop iteration")
+
+// migrateLegacyData moves data stored by gdu versions which kept directories
+// under their plain paths (without scan namespacing) into scans of their own.
+func (s *Storage) migrateLegacyData() error {
+	for {
+		root, err := s.findLegacyRoot()
+		if err != nil {
+			return errors.Wrap(err, "checking legacy data")
+		}
+		if root == "" {
+			return nil
+		}
+		if err := s.migrateLegacyRoot(root); err != nil {
+			return err
+		}
+	}
+}
+
+// findLegacyRoot returns the top directory of a legacy analysis or empty string if there is none.
+// Namespaced keys start with zero byte, legacy keys with any other one.
+// Paths of subdirectories are sorted after the path of their top directory,
+// so the first of the legacy keys is a top directory.
+func (s *Storage) findLegacyRoot() (string, error) {
+	backend := s.getBackend()
+
+	var root string
+	for b := 1; b <= 0xff && root == ""; b++ {
+		err := backend.IteratePrefix([]byte{byte(b)}, func(key, _ []byte) error {
+			root = string(key)
+			return errStopIteration
+		})
+		if err != nil && err != errStopIteration {
+			return "", err
+		}
+	}
+	return root, nil
+}
+
+// migrateLegacyRoot moves legacy analysis of the root
+// (the root itself and paths under it, not paths only sharing its prefix) into a scan
+func (s *Storage) migrateLegacyRoot(root string) error {
+	backend := s.getBackend()
+
+	subdirsPrefix := root
+	if !strings.HasSuffix(root, string(filepath.Separator)) {
+		subdirsPrefix += string(filepath.Separator)
+	}
+	removeLegacyData := func() error {
+		if err := backend.DropPrefix([]byte(subdirsPrefix)); err != nil {
+			return errors.Wrap(err, "removing legacy data")
+		}
+		return errors.Wrap(backend.Delete([]byte(root)), "removing legacy data")
+	}
+
+	id := ScanID(root, nil)
+	if _, err := s.GetScanInfo(id); err == nil {
+		// newer scan of the same root with the same options replaced the legacy analysis
+		log.Printf("Data of %s stored by older version superseded by scan %s", root, id)
+		return removeLegacyData()
+	}
+
+	value, err := backend.Get([]byte(root))
+	if err != nil {
+		return errors.Wrap(err, "reading legacy top directory "+root)
+	}
+	top := &StoredDir{Dir: &Dir{File: &File{}}}
+	if err := gob.NewDecoder(bytes.NewBuffer(value)).Decode(top); err != nil {
+		return errors.Wrap(err, "reading legacy top directory "+root)
+	}
+
+	// time of the legacy analysis is not stored,
+	// the latest modification found by it is the closest estimate
+	scanTime := top.GetMtime()
+	if scanTime.IsZero() {
+		scanTime = time.Now()
+	}
+	info := ScanInfo{
+		ID:        id,
+		Root:      root,
+		Time:      scanTime,
+		Size:      top.GetSize(),
+		Usage:     top.GetUsage(),
+		ItemCount: top.GetItemCount(),
+	}
+
+	batch := backend.NewBatch()
+	if err := batch.Set(dirKey(id, root), value); err != nil {
+		return errors.Wrap(err, "migrating legacy data")
+	}
+	err = backend.IteratePrefix([]byte(subdirsPrefix), func(key, value []byte) error {
+		return batch.Set(dirKey(id, string(key)), bytes.Clone(value))
+	})
+	if err != nil {
+		return errors.Wrap(err, "migrating legacy data")
+	}
+	if err := batch.Flush(); err != nil {
+		return errors.Wrap(err, "migrating legacy data")
+	}
+	if err := s.storeScanInfo(info); err != nil {
+		return err
+	}
+
+	log.Printf("Data of %s stored by older version migrated to scan %s", root, id)
+	return removeLegacyData()
+}
+
+// StartScan removes data of the previous scan with the same ID
+// and starts storing data of the new one
+func (s *Storage) StartScan(info ScanInfo) error {
+	if err := s.migrateLegacyData(); err != nil {
+		return err
+	}
+	if err := s.getBackend().DropPrefix(dirKeysPrefix(info.ID)); err != nil {
+		return errors.Wrap(err, "removing previous scan")
+	}
+	s.scanID = info.ID
+	s.topDir = info.Root
+	return s.storeScanInfo(info)
+}
+
+func (s *Storage) storeScanInfo(info ScanInfo) error {
+	b := &bytes.Buffer{}
+	if err := gob.NewEncoder(b).Encode(info); err != nil {
+		return errors.Wrap(err, "encoding scan info")
+	}
+	return s.set(scanKey(info.ID), b.Bytes())
+}
+
+// finishScan records the final size of the top directory into info about the scan
+func (s *Storage) finishScan(dir fs.Item) error {
+	info, err := s.GetScanInfo(s.scanID)
+	if err != nil {
+		return err
+	}
+	info.Size = dir.GetSize()
+	info.Usage = dir.GetUsage()
+	info.ItemCount = dir.GetItemCount()
+	return s.storeScanInfo(info)
+}
+
+// GetScanInfo returns info about scan with given ID
+func (s *Storage) GetScanInfo(id string) (ScanInfo, error) {
+	var info ScanInfo
+	if err := s.get(scanKey(id), &info); err != nil {
+		return info, errors.Wrap(err, "reading scan "+id)
+	}
+	return info, nil
+}
+
+// ListScans returns scans kept in the storage sorted from the oldest one
+func (s *Storage) ListScans() ([]ScanInfo, error) {
+	if err := s.migrateLegacyData(); err != nil {
+		return nil, err
+	}
+
+	scans := make([]ScanInfo, 0)
+	err := s.getBackend().IteratePrefix([]byte(scanKeyPrefix), func(_, value []byte) error {
+		var info ScanInfo
+		if err := gob.NewDecoder(bytes.NewBuffer(value)).Decode(&info); err != nil {
+			return errors.Wrap(err, "reading scan info")
+		}
+		scans = append(scans, info)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(scans, func(i, j int) bool {
+		return scans[i].Time.Before(scans[j].Time)
+	})
+	return scans, nil
+}
+
+// SelectScan selects the most recent scan of given root,
+// the most recent scan of any root is selected if the root is empty
+func (s *Storage) SelectScan(root string) error {
+	scans, err := s.ListScans()
+	if err != nil {
+		return err
+	}
+	for i := len(scans) - 1; i >= 0; i-- {
+		if root == "" || scans[i].Root == root {
+			s.scanID = scans[i].ID
+			s.topDir = scans[i].Root
+			return nil
+		}
+	}
+	if root == "" {
+		return errors.New("storage is empty")
+	}
+	return errors.New("no scan of " + root + " in the storage")
+}
@@ -0,0 +1,238 @@
+package analyze
+
+import (
+	"bytes"
+	"encoding/gob"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// SnapshotTimeFormat is the format of names of snapshots created without explicit name
+const SnapshotTimeFormat = "2006-01-02T15:04:05"
+
+const (
+	snapshotKeyPrefix      = "\x00snapshot\x00"
+	snapshotUsageKeyPrefix = "\x00usage\x00"
+)
+
+// Snapshot is a named state of the analysis kept in the storage
+type Snapshot struct {
+	Name string
+	Time time.Time
+	Root string
+}
+
+// SnapshotUsage is usage of a directory recorded in a snapshot
+type SnapshotUsage struct {
+	Snapshot  Snapshot
+	Size      int64
+	Usage     int64
+	ItemCount int
+}
+
+type snapshotDirUsage struct {
+	Size      int64
+	Usage     int64
+	ItemCount int
+}
+
+func snapshotKeysPrefix(scanID string) []byte {
+	return []byte(snapshotKeyPrefix + scanID + "\x00")
+}
+
+func snapshotKey(scanID, name string) []byte {
+	return append(snapshotKeysPrefix(scanID), name...)
+}
+
+func snapshotUsageKeysPrefix(scanID, name string) []byte {
+	return []byte(snapshotUsageKeyPrefix + scanID + "\x00" + name + "\x00")
+}
+
+func snapshotUsageKey(scanID, name, path string) []byte {
+	return append(snapshotUsageKeysPrefix(scanID, name), path...)
+}
+
+// StartSnapshot starts recording of snapshot with given name.
+// Snapshot of the same scan with the same name is replaced.
+// Usage of directories is recorded when their stats are updated
+// and the snapshot is finished when stats of the top directory are updated.
+func (s *Storage) StartSnapshot(name string, t time.Time) error {
+	if err := s.DeleteSnapshot(name); err != nil {
+		return err
+	}
+	s.snapshot = &Snapshot{
+		Name: name,
+		Time: t,
+		Root: s.topDir,
+	}
+	return nil
+}
+
+// storeSnapshotUsage records usage of the dir into the snapshot being recorded
+func (s *Storage) storeSnapshotUsage(dir fs.Item) error {
+	if s.snapshot == nil {
+		return nil
+	}
+
+	b := &bytes.Buffer{}
+	err := gob.NewEncoder(b).Encode(snapshotDirUsage{
+		Size:      dir.GetSize(),
+		Usage:     dir.GetUsage(),
+		ItemCount: dir.GetItemCount(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "encoding snapshot usage")
+	}
+	return s.set(snapshotUsageKey(s.scanID, s.snapshot.Name, dir.GetPath()), b.Bytes())
+}
+
+// finishSnapshot stores the snapshot being recorded into the list of snapshots
+func (s *Storage) finishSnapshot() error {
+	if s.snapshot == nil {
+		return nil
+	}
+	snapshot := s.snapshot
+	s.snapshot = nil
+
+	b := &bytes.Buffer{}
+	if err := gob.NewEncoder(b).Encode(snapshot); err != nil {
+		return errors.Wrap(err, "encoding snapshot")
+	}
+	return s.set(snapshotKey(s.scanID, snapshot.Name), b.Bytes())
+}
+
+// ListSnapshots returns snapshots of the scan sorted from the oldest one
+func (s *Storage) ListSnapshots() ([]Snapshot, error) {
+	snapshots := make([]Snapshot, 0)
+	err := s.getBackend().IteratePrefix(snapshotKeysPrefix(s.scanID), func(_, value []byte) error {
+		var snapshot Snapshot
+		if err := gob.NewDecoder(bytes.NewBuffer(value)).Decode(&snapshot); err != nil {
+			return errors.Wrap(err, "reading snapshot")
+		}
+		snapshots = append(snapshots, snapshot)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.Before(snapshots[j].Time)
+	})
+	return snapshots, nil
+}
+
+// GetSnapshotDir returns tree of directories recorded in the snapshot with given name.
+// Snapshots keep only usage of directories, so the tree doesn't contain any files.
+func (s *Storage) GetSnapshotDir(name string) (fs.Item, error) {
+	var snapshot Snapshot
+	if err := s.get(snapshotKey(s.scanID, name), &snapshot); err != nil {
+		return nil, errors.Wrap(err, "reading snapshot "+name)
+	}
+
+	// parent directories are sorted before their subdirectories
+	dirs := make(map[string]*Dir)
+	prefix := snapshotUsageKeysPrefix(s.scanID, name)
+	err := s.getBackend().IteratePrefix(prefix, func(key, value []byte) error {
+		var usage snapshotDirUsage
+		if err := gob.NewDecoder(bytes.NewBuffer(value)).Decode(&usage); err != nil {
+			return errors.Wrap(err, "reading snapshot usage")
+		}
+
+		path := string(key[len(prefix):])
+		dir := &Dir{
+			File: &File{
+				Name:  filepath.Base(path),
+				Size:  usage.Size,
+				Usage: usage.Usage,
+				Flag:  ' ',
+			},
+			ItemCount: usage.ItemCount,
+		}
+		if path == snapshot.Root {
+			dir.BasePath = filepath.Dir(path)
+		} else {
+			parent, ok := dirs[filepath.Dir(path)]
+			if !ok {
+				return nil
+			}
+			dir.Parent = parent
+			parent.AddFile(dir)
+		}
+		dirs[path] = dir
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	root, ok := dirs[snapshot.Root]
+	if !ok {
+		return nil, errors.New("snapshot " + name + " doesn't contain " + snapshot.Root)
+	}
+	return root, nil
+}
+
+// DeleteSnapshot removes snapshot with given name together with all its recorded usage
+func (s *Storage) DeleteSnapshot(name string) error {
+	if err := s.getBackend().Delete(snapshotKey(s.scanID, name)); err != nil {
+		return err
+	}
+	return s.getBackend().DropPrefix(snapshotUsageKeysPrefix(s.scanID, name))
+}
+
+// PruneSnapshots removes all but given number of the newest snapshots
+// and returns the removed ones
+func (s *Storage) PruneSnapshots(keep int) ([]Snapshot, error) {
+	snapshots, err := s.ListSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) <= keep {
+		return nil, nil
+	}
+
+	removed := snapshots[:len(snapshots)-keep]
+	for _, snapshot := range removed {
+		if err := s.DeleteSnapshot(snapshot.Name); err != nil {
+			return nil, err
+		}
+	}
+	return removed, nil
+}
+
+// GetUsageHistory returns usage of directory with given path in the last count snapshots
+// sorted from the oldest one. Snapshots not containing the directory are skipped.
+func (s *Storage) GetUsageHistory(path string, count int) ([]SnapshotUsage, error) {
+	snapshots, err := s.ListSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) > count {
+		snapshots = snapshots[len(snapshots)-count:]
+	}
+
+	history := make([]SnapshotUsage, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		var usage snapshotDirUsage
+		err := s.get(snapshotUsageKey(s.scanID, snapshot.Name, path), &usage)
+		if errors.Is(err, ErrKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "reading snapshot usage")
+		}
+		history = append(history, SnapshotUsage{
+			Snapshot:  snapshot,
+			Size:      usage.Size,
+			Usage:     usage.Usage,
+			ItemCount: usage.ItemCount,
+		})
+	}
+	return history, nil
+}
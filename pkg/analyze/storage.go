@@ -6,7 +6,6 @@ import (
 	"path/filepath"
 	"sync"
 
-	"github.com/dgraph-io/badger/v4"
 	"github.com/dundee/gdu/v5/pkg/fs"
 	"github.com/pkg/errors"
 )
@@ -18,27 +17,35 @@ func init() {
 	gob.RegisterName("analyze.ParentDir", &ParentDir{})
 }
 
-// DefaultStorage is a default instance of badger storage
-var DefaultStorage *Storage
-
-// Storage represents a badger storage
+// Storage represents a key-value storage of one scan
 type Storage struct {
-	db          *badger.DB
+	db          *sharedBackend
+	backendName string
 	storagePath string
 	topDir      string
+	scanID      string
+	snapshot    *Snapshot
+	batch       Batch
 	m           sync.RWMutex
-	counter     int
-	counterM    sync.Mutex
 }
 
-// NewStorage returns new instance of badger storage
+// NewStorage returns new instance of storage using the default backend
 func NewStorage(storagePath, topDir string) *Storage {
-	st := &Storage{
+	return &Storage{
+		backendName: DefaultStorageBackend,
 		storagePath: storagePath,
 		topDir:      topDir,
 	}
-	DefaultStorage = st
-	return st
+}
+
+// SetBackend sets name of the backend used when the storage is opened
+func (s *Storage) SetBackend(name string) {
+	s.backendName = name
+}
+
+// GetBackend returns name of the backend
+func (s *Storage) GetBackend() string {
+	return s.backendName
 }
 
 // GetTopDir returns top directory
@@ -46,65 +53,117 @@ func (s *Storage) GetTopDir() string {
 	return s.topDir
 }
 
-// IsOpen returns true if badger DB is open
+// GetScanID returns ID of the scan whose data are read and written
+func (s *Storage) GetScanID() string {
+	return s.scanID
+}
+
+// SetScanID sets ID of the scan whose data are read and written
+func (s *Storage) SetScanID(id string) {
+	s.scanID = id
+}
+
+// IsOpen returns true if the backend is open
 func (s *Storage) IsOpen() bool {
 	s.m.RLock()
 	defer s.m.RUnlock()
 	return s.db != nil
 }
 
-// Open opens badger DB
+// Open opens the backend
 func (s *Storage) Open() func() {
-	options := badger.DefaultOptions(s.storagePath)
-	options.Logger = nil
-	db, err := badger.Open(options)
+	db, err := acquireBackend(s.backendName, s.storagePath)
 	if err != nil {
 		panic(err)
 	}
+	s.m.Lock()
 	s.db = db
+	s.m.Unlock()
 
 	return func() {
-		s.db.Close()
+		s.m.Lock()
 		s.db = nil
+		s.m.Unlock()
+		db.release()
 	}
 }
 
-// StoreDir saves item info into badger DB
-func (s *Storage) StoreDir(dir fs.Item) error {
-	s.checkCount()
+func (s *Storage) getBackend() Backend {
 	s.m.RLock()
 	defer s.m.RUnlock()
+	return s.db.backend
+}
 
-	return s.db.Update(func(txn *badger.Txn) error {
-		b := &bytes.Buffer{}
-		enc := gob.NewEncoder(b)
-		err := enc.Encode(dir)
-		if err != nil {
-			return errors.Wrap(err, "encoding dir value")
-		}
-
-		return txn.Set([]byte(dir.GetPath()), b.Bytes())
-	})
+// StartBatch makes all following writes grouped into large batches
+// which are written in the background until FlushBatch is called.
+// Written values can't be read before the batch is flushed.
+func (s *Storage) StartBatch() {
+	batch := s.getBackend().NewBatch()
+	s.m.Lock()
+	s.batch = batch
+	s.m.Unlock()
 }
 
-// LoadDir saves item info into badger DB
-func (s *Storage) LoadDir(dir fs.Item) error {
-	s.checkCount()
+// IsBatching returns true if writes are grouped into batches
+func (s *Storage) IsBatching() bool {
 	s.m.RLock()
 	defer s.m.RUnlock()
+	return s.batch != nil
+}
+
+// FlushBatch waits until all values written since StartBatch are stored
+func (s *Storage) FlushBatch() error {
+	s.m.Lock()
+	batch := s.batch
+	s.batch = nil
+	s.m.Unlock()
 
-	return s.db.View(func(txn *badger.Txn) error {
-		path := dir.GetPath()
-		item, err := txn.Get([]byte(path))
-		if err != nil {
-			return errors.Wrap(err, "reading stored value for path: "+path)
-		}
-		return item.Value(func(val []byte) error {
-			b := bytes.NewBuffer(val)
-			dec := gob.NewDecoder(b)
-			return dec.Decode(dir)
-		})
-	})
+	if batch == nil {
+		return nil
+	}
+	return batch.Flush()
+}
+
+// set writes value of the key into the batch if it's started or in a new transaction otherwise
+func (s *Storage) set(key, value []byte) error {
+	s.m.RLock()
+	batch := s.batch
+	s.m.RUnlock()
+
+	if batch != nil {
+		return batch.Set(key, value)
+	}
+	return s.getBackend().Set(key, value)
+}
+
+// get decodes value of the key into v
+func (s *Storage) get(key []byte, v any) error {
+	value, err := s.getBackend().Get(key)
+	if err != nil {
+		return err
+	}
+	return gob.NewDecoder(bytes.NewBuffer(value)).Decode(v)
+}
+
+// StoreDir saves item info into the storage
+func (s *Storage) StoreDir(dir fs.Item) error {
+	b := &bytes.Buffer{}
+	enc := gob.NewEncoder(b)
+	if err := enc.Encode(dir); err != nil {
+		return errors.Wrap(err, "encoding dir value")
+	}
+	return s.set(dirKey(s.scanID, dir.GetPath()), b.Bytes())
+}
+
+// LoadDir loads item info from the storage
+func (s *Storage) LoadDir(dir *StoredDir) error {
+	path := dir.GetPath()
+	err := s.get(dirKey(s.scanID, path), dir)
+	if err != nil {
+		err = errors.Wrap(err, "reading stored value for path: "+path)
+	}
+	dir.storage = s
+	return err
 }
 
 // GetDirForPath returns Dir for given path
@@ -112,14 +171,12 @@ func (s *Storage) GetDirForPath(path string) (item fs.Item, err error) {
 	dirPath := filepath.Dir(path)
 	name := filepath.Base(path)
 	dir := &StoredDir{
-		&Dir{
+		Dir: &Dir{
 			File: &File{
 				Name: name,
 			},
 			BasePath: dirPath,
 		},
-		nil,
-		sync.Mutex{},
 	}
 	err = s.LoadDir(dir)
 	if err != nil {
@@ -127,16 +184,3 @@ func (s *Storage) GetDirForPath(path string) (item fs.Item, err error) {
 	}
 	return dir, nil
 }
-
-func (s *Storage) checkCount() {
-	s.counterM.Lock()
-	defer s.counterM.Unlock()
-	s.counter++
-	if s.counter >= 10000 {
-		s.m.Lock()
-		defer s.m.Unlock()
-		s.counter = 0
-		s.db.Close()
-		s.Open()
-	}
-}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime/debug"
+	"strings"
 	"sync"
 	"time"
 
@@ -24,16 +25,22 @@ type StoredAnalyzer struct {
 	wait                *WaitGroup
 	ignoreDir           common.ShouldDirBeIgnored
 	storagePath         string
+	storageBackend      string
 	followSymlinks      bool
 	gitAnnexedSize      bool
 	matchesTimeFilterFn common.TimeFilter
 	archiveBrowsing     bool
+	scanOptions         map[string]string
+	snapshotName        string
+	root                string
+	scanID              string
 }
 
 // CreateStoredAnalyzer returns Analyzer
 func CreateStoredAnalyzer(storagePath string) *StoredAnalyzer {
 	return &StoredAnalyzer{
-		storagePath: storagePath,
+		storagePath:    storagePath,
+		storageBackend: DefaultStorageBackend,
 		progress: &common.CurrentProgress{
 			ItemCount: 0,
 			TotalSize: int64(0),
@@ -74,6 +81,23 @@ func (a *StoredAnalyzer) SetArchiveBrowsing(v bool) {
 	a.archiveBrowsing = v
 }
 
+// SetScanOptions sets options of the analysis which make the scan differ
+// from scans of the same directory with other options
+func (a *StoredAnalyzer) SetScanOptions(options map[string]string) {
+	a.scanOptions = options
+}
+
+// SetSnapshotName sets name of the snapshot recorded by the next analysis
+// Snapshot is named by the time of the analysis if the name is empty
+func (a *StoredAnalyzer) SetSnapshotName(name string) {
+	a.snapshotName = name
+}
+
+// SetStorageBackend sets name of the backend the analysis is stored in
+func (a *StoredAnalyzer) SetStorageBackend(name string) {
+	a.storageBackend = name
+}
+
 // ResetProgress returns progress
 func (a *StoredAnalyzer) ResetProgress() {
 	a.progress = &common.CurrentProgress{}
@@ -93,22 +117,35 @@ func (a *StoredAnalyzer) AnalyzeDir(
 		go manageMemoryUsage(a.doneChan)
 	}
 
-	a.storage = NewStorage(a.storagePath, path)
+	// rescans of subdirectories are stored into the current scan,
+	// analysis of any other directory (or full rescan) starts new one
+	newScan := a.scanID == "" || !isUnderDir(path, a.root)
+	if newScan {
+		a.storage = NewStorage(a.storagePath, path)
+	} else {
+		a.storage = NewStorage(a.storagePath, a.root)
+		a.storage.SetScanID(a.scanID)
+	}
+	a.storage.SetBackend(a.storageBackend)
 	closeFn := a.storage.Open()
-	defer func() {
-		// nasty hack to close storage after all goroutines are done
-		// Wait returns immediately if value is 0
-		// few last goroutines might still start after that
-		time.Sleep(1 * time.Second)
-		closeFn()
-	}()
+	defer closeFn()
+
+	if newScan {
+		a.startScan(path)
+	}
+	a.storage.StartBatch()
 
 	a.ignoreDir = ignore
 
 	go a.updateProgress()
+	a.wait.Add(1)
 	dir := a.processDir(path)
 
+	// all directories are processed and written into the batch once the wait is over
 	a.wait.Wait()
+	if err := a.storage.FlushBatch(); err != nil {
+		log.Print(err.Error())
+	}
 
 	a.progressDoneChan <- struct{}{}
 	a.doneChan.Broadcast()
@@ -116,6 +153,39 @@ func (a *StoredAnalyzer) AnalyzeDir(
 	return dir
 }
 
+func (a *StoredAnalyzer) startScan(path string) {
+	start := time.Now()
+	a.root = path
+	a.scanID = ScanID(path, a.scanOptions)
+
+	err := a.storage.StartScan(ScanInfo{
+		ID:      a.scanID,
+		Root:    path,
+		Options: a.scanOptions,
+		Time:    start,
+	})
+	if err != nil {
+		log.Print(err.Error())
+	}
+
+	name := a.snapshotName
+	if name == "" {
+		name = start.Format(SnapshotTimeFormat)
+	}
+	if err := a.storage.StartSnapshot(name, start); err != nil {
+		log.Print(err.Error())
+	}
+}
+
+// isUnderDir returns true if path is strictly under the dir
+func isUnderDir(path, dir string) bool {
+	prefix := dir
+	if !strings.HasSuffix(dir, string(filepath.Separator)) {
+		prefix += string(filepath.Separator)
+	}
+	return len(path) > len(prefix) && strings.HasPrefix(path, prefix)
+}
+
 func (a *StoredAnalyzer) processDir(path string) *StoredDir {
 	var (
 		file      fs.Item
@@ -125,8 +195,6 @@ func (a *StoredAnalyzer) processDir(path string) *StoredDir {
 		dirCount  int
 	)
 
-	a.wait.Add(1)
-
 	files, err := os.ReadDir(path)
 	if err != nil {
 		log.Print(err.Error())
@@ -142,6 +210,7 @@ func (a *StoredAnalyzer) processDir(path string) *StoredDir {
 			ItemCount: 1,
 			Files:     make(fs.Files, 0, len(files)),
 		},
+		storage: a.storage,
 	}
 	parent := &ParentDir{Path: path}
 
@@ -157,17 +226,17 @@ func (a *StoredAnalyzer) processDir(path string) *StoredDir {
 			dirCount++
 
 			subdir := &StoredDir{
-				&Dir{
+				Dir: &Dir{
 					File: &File{
 						Name: name,
 					},
 					BasePath: path,
 				},
-				nil,
-				sync.Mutex{},
 			}
 			dir.AddFile(subdir)
 
+			// counted before the goroutine starts so Wait can't return too early
+			a.wait.Add(1)
 			go func(entryPath string) {
 				concurrencyLimit <- struct{}{}
 				a.processDir(entryPath)
@@ -265,20 +334,26 @@ type StoredDir struct {
 	*Dir
 	cachedFiles fs.Files
 	dbLock      sync.Mutex
+	storage     *Storage
+}
+
+// GetStorage returns storage the dir is kept in
+func (f *StoredDir) GetStorage() *Storage {
+	return f.storage
 }
 
 // GetParent returns parent dir
 func (f *StoredDir) GetParent() fs.Item {
-	if DefaultStorage.GetTopDir() == f.GetPath() {
+	if f.storage.GetTopDir() == f.GetPath() {
 		return nil
 	}
 
-	if !DefaultStorage.IsOpen() {
-		closeFn := DefaultStorage.Open()
+	if !f.storage.IsOpen() {
+		closeFn := f.storage.Open()
 		defer closeFn()
 	}
 
-	dir, err := DefaultStorage.GetDirForPath(f.BasePath)
+	dir, err := f.storage.GetDirForPath(f.BasePath)
 	if err != nil {
 		log.Print(err.Error())
 	}
@@ -293,10 +368,10 @@ func (f *StoredDir) GetFiles() fs.Files {
 		return f.cachedFiles
 	}
 
-	if !DefaultStorage.IsOpen() {
+	if !f.storage.IsOpen() {
 		f.dbLock.Lock()
 		defer f.dbLock.Unlock()
-		closeFn := DefaultStorage.Open()
+		closeFn := f.storage.Open()
 		defer closeFn()
 	}
 
@@ -304,17 +379,15 @@ func (f *StoredDir) GetFiles() fs.Files {
 	for _, file := range f.Files {
 		if file.IsDir() {
 			dir := &StoredDir{
-				&Dir{
+				Dir: &Dir{
 					File: &File{
 						Name: file.GetName(),
 					},
 					BasePath: f.GetPath(),
 				},
-				nil,
-				sync.Mutex{},
 			}
 
-			err := DefaultStorage.LoadDir(dir)
+			err := f.storage.LoadDir(dir)
 			if err != nil {
 				log.Print(err.Error())
 			}
@@ -336,10 +409,10 @@ func (f *StoredDir) SetFiles(files fs.Files) {
 // RemoveFile removes file from stored directory
 // It also updates size and item count of parent directories
 func (f *StoredDir) RemoveFile(item fs.Item) {
-	if !DefaultStorage.IsOpen() {
+	if !f.storage.IsOpen() {
 		f.dbLock.Lock()
 		defer f.dbLock.Unlock()
-		closeFn := DefaultStorage.Open()
+		closeFn := f.storage.Open()
 		defer closeFn()
 	}
 
@@ -352,7 +425,7 @@ func (f *StoredDir) RemoveFile(item fs.Item) {
 		cur.Size -= item.GetSize()
 		cur.Usage -= item.GetUsage()
 
-		err := DefaultStorage.StoreDir(cur)
+		err := f.storage.StoreDir(cur)
 		if err != nil {
 			log.Print(err.Error())
 		}
@@ -373,10 +446,19 @@ func (f *StoredDir) GetItemStats(linkedItems fs.HardLinkedItems) (itemCount int,
 
 // UpdateStats recursively updates size and item count
 func (f *StoredDir) UpdateStats(linkedItems fs.HardLinkedItems) {
-	if !DefaultStorage.IsOpen() {
-		closeFn := DefaultStorage.Open()
+	if !f.storage.IsOpen() {
+		closeFn := f.storage.Open()
 		defer closeFn()
 	}
+	// the outermost call writes stats of the whole subtree in one batch
+	if !f.storage.IsBatching() {
+		f.storage.StartBatch()
+		defer func() {
+			if err := f.storage.FlushBatch(); err != nil {
+				log.Print(err.Error())
+			}
+		}()
+	}
 
 	totalSize := int64(4096)
 	totalUsage := int64(4096)
@@ -403,10 +485,22 @@ func (f *StoredDir) UpdateStats(linkedItems fs.HardLinkedItems) {
 	f.ItemCount = itemCount + 1
 	f.Size = totalSize
 	f.Usage = totalUsage
-	err := DefaultStorage.StoreDir(f)
+	err := f.storage.StoreDir(f)
 	if err != nil {
 		log.Print(err.Error())
 	}
+
+	if err := f.storage.storeSnapshotUsage(f); err != nil {
+		log.Print(err.Error())
+	}
+	if f.GetPath() == f.storage.GetTopDir() {
+		if err := f.storage.finishScan(f); err != nil {
+			log.Print(err.Error())
+		}
+		if err := f.storage.finishSnapshot(); err != nil {
+			log.Print(err.Error())
+		}
+	}
 }
 
 // ParentDir represents parent directory of single file
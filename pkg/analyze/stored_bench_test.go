@@ -0,0 +1,130 @@
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// the generated tree has 10000 directories with 100 files each
+const (
+	benchDirCount  = 10000
+	benchFileCount = 100
+)
+
+// benchTreeEnv names directory where the generated tree is kept between runs
+const benchTreeEnv = "GDU_BENCH_TREE"
+
+// getBenchTree returns path of the tree with a million files.
+// The tree is generated into temporary directory removed after the benchmark
+// unless a directory for reusing the tree is given by GDU_BENCH_TREE.
+func getBenchTree(b *testing.B) string {
+	b.Helper()
+
+	path := os.Getenv(benchTreeEnv)
+	if path == "" {
+		path = b.TempDir()
+	} else if _, err := os.Stat(filepath.Join(path, ".complete")); err == nil {
+		return path
+	}
+
+	for i := 0; i < benchDirCount; i++ {
+		dir := filepath.Join(path, "dir"+strconv.Itoa(i))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			b.Fatal(err)
+		}
+		for j := 0; j < benchFileCount; j++ {
+			if err := os.WriteFile(filepath.Join(dir, "file"+strconv.Itoa(j)), nil, 0o644); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	if err := os.WriteFile(filepath.Join(path, ".complete"), nil, 0o644); err != nil {
+		b.Fatal(err)
+	}
+	return path
+}
+
+func BenchmarkStoredAnalyzeDir(b *testing.B) {
+	path := getBenchTree(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		analyzer := CreateStoredAnalyzer(b.TempDir())
+		dir := analyzer.AnalyzeDir(
+			path, func(_, _ string) bool { return false }, false,
+		)
+		analyzer.GetDone().Wait()
+		dir.UpdateStats(make(fs.HardLinkedItems))
+	}
+}
+
+// createBenchDirs returns directories of the same shape as the generated tree without touching the disk
+func createBenchDirs() []*StoredDir {
+	dirs := make([]*StoredDir, 0, benchDirCount)
+	for i := 0; i < benchDirCount; i++ {
+		dir := &StoredDir{
+			Dir: &Dir{
+				File: &File{
+					Name: "dir" + strconv.Itoa(i),
+				},
+				BasePath: "/bench",
+				Files:    make(fs.Files, 0, benchFileCount),
+			},
+		}
+		parent := &ParentDir{Path: dir.GetPath()}
+		for j := 0; j < benchFileCount; j++ {
+			dir.AddFile(&File{
+				Name:   "file" + strconv.Itoa(j),
+				Size:   int64(j),
+				Usage:  4096,
+				Parent: parent,
+			})
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// previousReopenCount is the number of writes after which the previous implementation reopened the storage
+const previousReopenCount = 10000
+
+// BenchmarkStorageStoreDirs compares the write path of the previous implementation
+// (each directory in its own transaction, storage reopened after every 10 000 writes
+// and a second of sleep before closing the storage at the end of analysis)
+// with storing each directory in its own transaction and with storing all of them in one batch
+func BenchmarkStorageStoreDirs(b *testing.B) {
+	dirs := createBenchDirs()
+
+	for _, name := range []string{"previous", "transactions", "batch"} {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				storage := NewStorage(b.TempDir(), "/bench")
+				closeFn := storage.Open()
+				if name == "batch" {
+					storage.StartBatch()
+				}
+				for j, dir := range dirs {
+					if err := storage.StoreDir(dir); err != nil {
+						b.Fatal(err)
+					}
+					if name == "previous" && (j+1)%previousReopenCount == 0 {
+						closeFn()
+						closeFn = storage.Open()
+					}
+				}
+				if err := storage.FlushBatch(); err != nil {
+					b.Fatal(err)
+				}
+				if name == "previous" {
+					time.Sleep(time.Second)
+				}
+				closeFn()
+			}
+		})
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
+	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/dundee/gdu/v5/internal/testdir"
 	"github.com/dundee/gdu/v5/pkg/fs"
@@ -99,9 +101,10 @@ func TestRemoveStoredFile(t *testing.T) {
 	subdir := dir.GetFiles()[0].(*StoredDir)
 	subdir.RemoveFile(subdir.GetFiles()[0])
 
-	closeFn := DefaultStorage.Open()
+	storage := dir.GetStorage()
+	closeFn := storage.Open()
 	defer closeFn()
-	stored, err := DefaultStorage.GetDirForPath("test_dir")
+	stored, err := storage.GetDirForPath("test_dir")
 	assert.NoError(t, err)
 
 	assert.Equal(t, 4, stored.GetItemCount())
@@ -313,3 +316,315 @@ func TestParentDirGetItemStatsPanics(t *testing.T) {
 	dir := &ParentDir{}
 	dir.GetItemStats(nil)
 }
+
+func TestStorageSelectScan(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	storagePath := t.TempDir()
+	a := CreateStoredAnalyzer(storagePath)
+	a.AnalyzeDir(
+		"test_dir", func(_, _ string) bool { return false }, false,
+	)
+	a.GetDone().Wait()
+
+	storage := NewStorage(storagePath, "")
+	closeFn := storage.Open()
+	defer closeFn()
+
+	err := storage.SelectScan("")
+	assert.NoError(t, err)
+	assert.Equal(t, "test_dir", storage.GetTopDir())
+	assert.Equal(t, ScanID("test_dir", nil), storage.GetScanID())
+
+	err = storage.SelectScan("test_dir/nested")
+	assert.ErrorContains(t, err, "no scan of test_dir/nested")
+}
+
+func TestStorageMigratesLegacyData(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	storagePath := t.TempDir()
+	a := CreateStoredAnalyzer(storagePath)
+	a.AnalyzeDir(
+		"test_dir", func(_, _ string) bool { return false }, false,
+	)
+	a.GetDone().Wait()
+
+	storage := NewStorage(storagePath, "")
+	closeFn := storage.Open()
+	defer closeFn()
+
+	// older versions kept directories under their plain paths
+	backend := storage.getBackend()
+	prefix := dirKeysPrefix(ScanID("test_dir", nil))
+	legacy := make(map[string][]byte)
+	err := backend.IteratePrefix(prefix, func(key, value []byte) error {
+		legacy[string(key[len(prefix):])] = bytes.Clone(value)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, backend.DropPrefix(prefix, scanKey(ScanID("test_dir", nil))))
+
+	// scan of another directory kept by the current version
+	assert.NoError(t, storage.StartScan(ScanInfo{ID: "current", Root: "/other", Time: time.Now()}))
+
+	analyzed := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for key, value := range legacy {
+		if key == "test_dir" {
+			top := &StoredDir{Dir: &Dir{File: &File{}}}
+			assert.NoError(t, gob.NewDecoder(bytes.NewBuffer(value)).Decode(top))
+			top.Mtime = analyzed
+			b := &bytes.Buffer{}
+			assert.NoError(t, gob.NewEncoder(b).Encode(top))
+			value = b.Bytes()
+		}
+		assert.NoError(t, backend.Set([]byte(key), value))
+		// path sharing prefix with the first root
+		assert.NoError(t, backend.Set([]byte("test_dir2"+key[len("test_dir"):]), value))
+	}
+
+	err = storage.SelectScan("test_dir")
+	assert.NoError(t, err)
+	assert.Equal(t, ScanID("test_dir", nil), storage.GetScanID())
+
+	dir, err := storage.GetDirForPath("test_dir")
+	assert.NoError(t, err)
+	assert.Len(t, dir.GetFiles(), 1)
+	assert.Equal(t, "nested", dir.GetFiles()[0].GetName())
+
+	scans, err := storage.ListScans()
+	assert.NoError(t, err)
+	assert.Len(t, scans, 3)
+	roots := make(map[string]ScanInfo)
+	for _, scan := range scans {
+		roots[scan.Root] = scan
+	}
+	assert.Contains(t, roots, "/other")
+	assert.Contains(t, roots, "test_dir2")
+	assert.True(t, analyzed.Equal(roots["test_dir"].Time))
+
+	_, err = backend.Get(dirKey(ScanID("test_dir", nil), "test_dir2"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = backend.Get(dirKey(ScanID("test_dir2", nil), "test_dir2/nested"))
+	assert.NoError(t, err)
+
+	for _, key := range []string{"test_dir", "test_dir/nested", "test_dir2", "test_dir2/nested"} {
+		_, err = backend.Get([]byte(key))
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+	}
+}
+
+func TestEmptyStorageSelectScan(t *testing.T) {
+	storage := NewStorage(t.TempDir(), "")
+	closeFn := storage.Open()
+	defer closeFn()
+
+	err := storage.SelectScan("")
+	assert.ErrorContains(t, err, "storage is empty")
+}
+
+func TestScanID(t *testing.T) {
+	id := ScanID("/home", map[string]string{"no-hidden": "true", "ignore-dirs": "/home/a"})
+	assert.Len(t, id, 12)
+	assert.Equal(t, id, ScanID("/home", map[string]string{"ignore-dirs": "/home/a", "no-hidden": "true"}))
+	assert.NotEqual(t, id, ScanID("/home", map[string]string{"ignore-dirs": "/home/a"}))
+	assert.NotEqual(t, id, ScanID("/var", map[string]string{"no-hidden": "true", "ignore-dirs": "/home/a"}))
+}
+
+func TestStoredScansOfSameRootWithDifferentOptions(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	storagePath := t.TempDir()
+
+	all := CreateStoredAnalyzer(storagePath)
+	allDir := all.AnalyzeDir(
+		"test_dir", func(_, _ string) bool { return false }, false,
+	).(*StoredDir)
+	all.GetDone().Wait()
+
+	// second analyzer is used while the first storage is still open
+	closeFn := allDir.GetStorage().Open()
+	defer closeFn()
+
+	ignoring := CreateStoredAnalyzer(storagePath)
+	ignoring.SetScanOptions(map[string]string{"ignore-dirs": "test_dir/nested/subnested"})
+	ignoringDir := ignoring.AnalyzeDir(
+		"test_dir", func(name, _ string) bool { return name == "subnested" }, false,
+	).(*StoredDir)
+	ignoring.GetDone().Wait()
+
+	allDir.UpdateStats(make(fs.HardLinkedItems))
+	ignoringDir.UpdateStats(make(fs.HardLinkedItems))
+
+	assert.Equal(t, 5, allDir.GetItemCount())
+	assert.Equal(t, 3, ignoringDir.GetItemCount())
+	assert.Len(t, allDir.GetFiles()[0].GetFiles(), 2)
+	assert.Len(t, ignoringDir.GetFiles()[0].GetFiles(), 1)
+
+	scans, err := allDir.GetStorage().ListScans()
+	assert.NoError(t, err)
+	assert.Len(t, scans, 2)
+	assert.Equal(t, "test_dir", scans[1].Root)
+	assert.Equal(t, 3, scans[1].ItemCount)
+	assert.Equal(t, "test_dir/nested/subnested", scans[1].Options["ignore-dirs"])
+}
+
+func TestStoredAnalyzerStartsScanOfOtherDir(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	other := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(other, "file"), []byte("hello"), 0o600))
+
+	storagePath := t.TempDir()
+	a := CreateStoredAnalyzer(storagePath)
+	analyze := func(path string) {
+		a.ResetProgress()
+		a.AnalyzeDir(path, func(_, _ string) bool { return false }, false)
+		a.GetDone().Wait()
+	}
+	listScans := func() []ScanInfo {
+		storage := NewStorage(storagePath, "")
+		closeFn := storage.Open()
+		defer closeFn()
+		scans, err := storage.ListScans()
+		assert.NoError(t, err)
+		return scans
+	}
+
+	analyze("test_dir")
+	analyze("test_dir/nested")
+	scans := listScans()
+	assert.Len(t, scans, 1)
+	assert.Equal(t, "test_dir", scans[0].Root)
+
+	analyze(other)
+	scans = listScans()
+	assert.Len(t, scans, 2)
+	assert.Equal(t, other, scans[1].Root)
+
+	analyze("test_dir")
+	scans = listScans()
+	assert.Len(t, scans, 2)
+	assert.Equal(t, other, scans[0].Root)
+	assert.Equal(t, "test_dir", scans[1].Root)
+
+	storage := NewStorage(storagePath, "")
+	closeFn := storage.Open()
+	defer closeFn()
+	assert.NoError(t, storage.SelectScan(other))
+	dir, err := storage.GetDirForPath(other)
+	assert.NoError(t, err)
+	assert.Equal(t, "file", dir.GetFiles()[0].GetName())
+}
+
+func TestIsUnderDir(t *testing.T) {
+	root := string(filepath.Separator)
+	assert.True(t, isUnderDir(filepath.Join("test_dir", "nested"), "test_dir"))
+	assert.True(t, isUnderDir(filepath.Join(root, "home"), root))
+	assert.False(t, isUnderDir("test_dir", "test_dir"))
+	assert.False(t, isUnderDir("test_dir2", "test_dir"))
+	assert.False(t, isUnderDir(root, root))
+}
+
+func TestStoredAnalyzerSnapshots(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	storagePath := t.TempDir()
+	analyzeWithSnapshot := func(name string) {
+		a := CreateStoredAnalyzer(storagePath)
+		a.SetSnapshotName(name)
+		dir := a.AnalyzeDir(
+			"test_dir", func(_, _ string) bool { return false }, false,
+		).(*StoredDir)
+		a.GetDone().Wait()
+		dir.UpdateStats(make(fs.HardLinkedItems))
+	}
+
+	analyzeWithSnapshot("first")
+	err := os.WriteFile("test_dir/nested/big", make([]byte, 10000), 0o600)
+	assert.NoError(t, err)
+	analyzeWithSnapshot("second")
+	analyzeWithSnapshot("")
+
+	storage := NewStorage(storagePath, "")
+	closeFn := storage.Open()
+	defer closeFn()
+	assert.NoError(t, storage.SelectScan("test_dir"))
+
+	snapshots, err := storage.ListSnapshots()
+	assert.NoError(t, err)
+	assert.Len(t, snapshots, 3)
+	assert.Equal(t, "first", snapshots[0].Name)
+	assert.Equal(t, "second", snapshots[1].Name)
+	assert.Equal(t, snapshots[2].Time.Format(SnapshotTimeFormat), snapshots[2].Name)
+	assert.Equal(t, "test_dir", snapshots[0].Root)
+
+	history, err := storage.GetUsageHistory("test_dir/nested", 2)
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, "second", history[0].Snapshot.Name)
+	assert.Equal(t, int64(7+10000+4096*2), history[0].Size)
+
+	history, err = storage.GetUsageHistory("test_dir/nested", 10)
+	assert.NoError(t, err)
+	assert.Len(t, history, 3)
+	assert.Equal(t, int64(7+4096*2), history[0].Size)
+	assert.Equal(t, 4, history[0].ItemCount)
+
+	dir, err := storage.GetSnapshotDir("first")
+	assert.NoError(t, err)
+	assert.Equal(t, "test_dir", dir.GetPath())
+	assert.Equal(t, 5, dir.GetItemCount())
+	nested := dir.GetFiles()[0]
+	assert.Equal(t, "test_dir/nested", nested.GetPath())
+	assert.Equal(t, int64(7+4096*2), nested.GetSize())
+	assert.Equal(t, "test_dir/nested/subnested", nested.GetFiles()[0].GetPath())
+
+	_, err = storage.GetSnapshotDir("missing")
+	assert.ErrorContains(t, err, "reading snapshot missing")
+
+	// snapshots are kept with the scan
+	scans, err := storage.ListScans()
+	assert.NoError(t, err)
+	assert.Len(t, scans, 1)
+
+	removed, err := storage.PruneSnapshots(1)
+	assert.NoError(t, err)
+	assert.Len(t, removed, 2)
+	assert.Equal(t, "first", removed[0].Name)
+
+	history, err = storage.GetUsageHistory("test_dir/nested", 10)
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+}
+
+func TestStorageDropsSupersededLegacyData(t *testing.T) {
+	storage := NewStorage(t.TempDir(), "")
+	closeFn := storage.Open()
+	defer closeFn()
+
+	info := ScanInfo{ID: ScanID("/data", nil), Root: "/data", Time: time.Now()}
+	assert.NoError(t, storage.StartScan(info))
+	assert.NoError(t, storage.StoreDir(&Dir{File: &File{Name: "data", Size: 5}, BasePath: "/"}))
+
+	backend := storage.getBackend()
+	b := &bytes.Buffer{}
+	assert.NoError(t, gob.NewEncoder(b).Encode(&StoredDir{Dir: &Dir{File: &File{Name: "data", Size: 3}, BasePath: "/"}}))
+	assert.NoError(t, backend.Set([]byte("/data"), b.Bytes()))
+
+	scans, err := storage.ListScans()
+	assert.NoError(t, err)
+	assert.Len(t, scans, 1)
+
+	dir, err := storage.GetDirForPath("/data")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), dir.GetSize())
+
+	_, err = backend.Get([]byte("/data"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
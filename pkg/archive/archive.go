@@ -0,0 +1,323 @@
+// Package archive packs files and directories into tar.gz, tar.xz or zip archives
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ulikunitz/xz"
+)
+
+// Archive formats
+const (
+	FormatTarGz = "tar.gz"
+	FormatTarXz = "tar.xz"
+	FormatZip   = "zip"
+)
+
+// Formats lists supported archive formats
+var Formats = []string{FormatTarGz, FormatTarXz, FormatZip}
+
+// ErrInsideArchivedItem is returned when the archive would be created inside one of the archived items
+var ErrInsideArchivedItem = errors.New("archive can't be created inside of archived item")
+
+// entryWriter writes entries of the archive
+type entryWriter interface {
+	writeDir(name string, info os.FileInfo) error
+	writeFile(name string, info os.FileInfo, r io.Reader) error
+	writeSymlink(name, target string, info os.FileInfo) error
+	Close() error
+}
+
+// Create packs items with the given paths into a new archive with the given path.
+// Items are stored under their base names, special files (devices, sockets, ...) are skipped.
+// Progress is called with the number of bytes read so far.
+// It returns the number of entries written to the archive.
+func Create(dest, format string, paths []string, progress func(int64)) (int, error) {
+	for _, path := range paths {
+		rel, err := filepath.Rel(path, dest)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return 0, ErrInsideArchivedItem
+		}
+	}
+
+	f, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return 0, err
+	}
+
+	entries, err := write(f, format, paths, progress)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dest)
+		return 0, err
+	}
+	return entries, nil
+}
+
+func write(f io.Writer, format string, paths []string, progress func(int64)) (int, error) {
+	w, err := newEntryWriter(f, format)
+	if err != nil {
+		return 0, err
+	}
+
+	counter := &progressCounter{progress: progress}
+	entries := 0
+	for _, path := range paths {
+		n, err := addPath(w, path, counter)
+		entries += n
+		if err != nil {
+			w.Close()
+			return 0, err
+		}
+	}
+	return entries, w.Close()
+}
+
+func newEntryWriter(w io.Writer, format string) (entryWriter, error) {
+	switch format {
+	case FormatTarGz:
+		gz := gzip.NewWriter(w)
+		return &tarWriter{Writer: tar.NewWriter(gz), compressor: gz}, nil
+	case FormatTarXz:
+		xzw, err := xz.NewWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		return &tarWriter{Writer: tar.NewWriter(xzw), compressor: xzw}, nil
+	case FormatZip:
+		return &zipWriter{Writer: zip.NewWriter(w)}, nil
+	}
+	return nil, fmt.Errorf("unknown archive format: %s", format)
+}
+
+func addPath(w entryWriter, root string, counter *progressCounter) (int, error) {
+	base := filepath.Base(root)
+	entries := 0
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(filepath.Join(base, rel))
+
+		switch {
+		case info.IsDir():
+			err = w.writeDir(name, info)
+		case info.Mode()&os.ModeSymlink != 0:
+			var target string
+			target, err = os.Readlink(path)
+			if err == nil {
+				err = w.writeSymlink(name, target, info)
+			}
+		case info.Mode().IsRegular():
+			err = writeFile(w, path, name, info, counter)
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		entries++
+		return nil
+	})
+	return entries, err
+}
+
+func writeFile(w entryWriter, path, name string, info os.FileInfo, counter *progressCounter) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	counter.Reader = f
+	return w.writeFile(name, info, counter)
+}
+
+// Verify reads the whole archive and checks its integrity.
+// It returns the number of entries in the archive.
+func Verify(path, format string) (int, error) {
+	switch format {
+	case FormatZip:
+		return verifyZip(path)
+	case FormatTarGz, FormatTarXz:
+	default:
+		return 0, fmt.Errorf("unknown archive format: %s", format)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var r io.Reader
+	switch format {
+	case FormatTarGz:
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return 0, err
+		}
+		defer gz.Close()
+		r = gz
+	case FormatTarXz:
+		r, err = xz.NewReader(f)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	entries := 0
+	tr := tar.NewReader(r)
+	for {
+		_, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		if _, err := io.Copy(io.Discard, tr); err != nil {
+			return 0, err
+		}
+		entries++
+	}
+
+	// read the rest of the stream so the checksum of the compressed data is checked
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return 0, err
+	}
+	return entries, nil
+}
+
+func verifyZip(path string) (int, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return 0, err
+	}
+	defer zr.Close()
+
+	for _, file := range zr.File {
+		rc, err := file.Open()
+		if err != nil {
+			return 0, err
+		}
+		// CRC of the content is checked when the end of file is reached
+		_, err = io.Copy(io.Discard, rc)
+		rc.Close()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(zr.File), nil
+}
+
+type tarWriter struct {
+	*tar.Writer
+	compressor io.WriteCloser
+}
+
+func (w *tarWriter) writeHeader(name, link string, info os.FileInfo) error {
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	return w.WriteHeader(header)
+}
+
+func (w *tarWriter) writeDir(name string, info os.FileInfo) error {
+	return w.writeHeader(name+"/", "", info)
+}
+
+func (w *tarWriter) writeFile(name string, info os.FileInfo, r io.Reader) error {
+	if err := w.writeHeader(name, "", info); err != nil {
+		return err
+	}
+	_, err := io.CopyN(w, r, info.Size())
+	return err
+}
+
+func (w *tarWriter) writeSymlink(name, target string, info os.FileInfo) error {
+	return w.writeHeader(name, target, info)
+}
+
+func (w *tarWriter) Close() error {
+	if err := w.Writer.Close(); err != nil {
+		return err
+	}
+	return w.compressor.Close()
+}
+
+type zipWriter struct {
+	*zip.Writer
+}
+
+func (w *zipWriter) create(name string, info os.FileInfo, method uint16) (io.Writer, error) {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return nil, err
+	}
+	header.Name = name
+	header.Method = method
+	return w.CreateHeader(header)
+}
+
+func (w *zipWriter) writeDir(name string, info os.FileInfo) error {
+	_, err := w.create(name+"/", info, zip.Store)
+	return err
+}
+
+func (w *zipWriter) writeFile(name string, info os.FileInfo, r io.Reader) error {
+	fw, err := w.create(name, info, zip.Deflate)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, r)
+	return err
+}
+
+// writeSymlink stores target of the link as content of the entry as other zip tools do
+func (w *zipWriter) writeSymlink(name, target string, info os.FileInfo) error {
+	fw, err := w.create(name, info, zip.Store)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(fw, target)
+	return err
+}
+
+// progressCounter reports number of bytes read from all files
+type progressCounter struct {
+	io.Reader
+	read     int64
+	progress func(int64)
+}
+
+func (c *progressCounter) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	c.read += int64(n)
+	if c.progress != nil && n > 0 {
+		c.progress(c.read)
+	}
+	return n, err
+}
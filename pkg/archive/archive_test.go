@@ -0,0 +1,107 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createItems(t *testing.T) (string, []string) {
+	t.Helper()
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "project", "src")
+	assert.Nil(t, os.MkdirAll(nested, 0o755))
+	assert.Nil(t, os.WriteFile(filepath.Join(nested, "main.go"), []byte("package main\n"), 0o644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hello"), 0o644))
+	return dir, []string{filepath.Join(dir, "project"), filepath.Join(dir, "notes.txt")}
+}
+
+func TestCreateAndVerify(t *testing.T) {
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			dir, paths := createItems(t)
+			dest := filepath.Join(dir, "archive."+format)
+
+			var progress int64
+			entries, err := Create(dest, format, paths, func(read int64) {
+				progress = read
+			})
+			assert.Nil(t, err)
+			// project, project/src, project/src/main.go, notes.txt
+			assert.Equal(t, 4, entries)
+			assert.Equal(t, int64(18), progress)
+
+			verified, err := Verify(dest, format)
+			assert.Nil(t, err)
+			assert.Equal(t, entries, verified)
+		})
+	}
+}
+
+func TestCreateWithSymlink(t *testing.T) {
+	dir, paths := createItems(t)
+	assert.Nil(t, os.Symlink("src/main.go", filepath.Join(paths[0], "link")))
+
+	dest := filepath.Join(dir, "archive.tar.gz")
+	entries, err := Create(dest, FormatTarGz, paths[:1], nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, entries)
+}
+
+func TestCreateExisting(t *testing.T) {
+	dir, paths := createItems(t)
+
+	_, err := Create(filepath.Join(dir, "notes.txt"), FormatZip, paths[:1], nil)
+	assert.ErrorIs(t, err, os.ErrExist)
+	content, err := os.ReadFile(filepath.Join(dir, "notes.txt"))
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(content))
+}
+
+func TestCreateInsideArchivedItem(t *testing.T) {
+	_, paths := createItems(t)
+
+	_, err := Create(filepath.Join(paths[0], "src", "project.zip"), FormatZip, paths[:1], nil)
+	assert.ErrorIs(t, err, ErrInsideArchivedItem)
+}
+
+func TestCreateUnknownFormat(t *testing.T) {
+	dir, paths := createItems(t)
+	dest := filepath.Join(dir, "archive.rar")
+
+	_, err := Create(dest, "rar", paths, nil)
+	assert.ErrorContains(t, err, "unknown archive format: rar")
+	assert.NoFileExists(t, dest)
+
+	_, err = Verify(dest, "rar")
+	assert.ErrorContains(t, err, "unknown archive format: rar")
+}
+
+func TestCreateMissingItem(t *testing.T) {
+	dir, _ := createItems(t)
+	dest := filepath.Join(dir, "archive.tar.xz")
+
+	_, err := Create(dest, FormatTarXz, []string{filepath.Join(dir, "xxx")}, nil)
+	assert.NotNil(t, err)
+	assert.NoFileExists(t, dest)
+}
+
+func TestVerifyCorrupted(t *testing.T) {
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			dir, paths := createItems(t)
+			dest := filepath.Join(dir, "archive."+format)
+			_, err := Create(dest, format, paths, nil)
+			assert.Nil(t, err)
+
+			content, err := os.ReadFile(dest)
+			assert.Nil(t, err)
+			assert.Nil(t, os.WriteFile(dest, content[:len(content)/2], 0o644))
+
+			_, err = Verify(dest, format)
+			assert.NotNil(t, err)
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package device
 
-import "strings"
+import (
+	"path/filepath"
+	"strings"
+)
 
 // Device struct
 type Device struct {
@@ -54,3 +57,20 @@ func GetNestedMountpointsPaths(path string, mounts Devices) []string {
 	}
 	return paths
 }
+
+// GetDeviceForPath returns device with the deepest mount point containing given path
+// or nil if no such device exists
+func GetDeviceForPath(path string, devices Devices) *Device {
+	var found *Device
+
+	for _, dev := range devices {
+		rel, err := filepath.Rel(dev.MountPoint, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if found == nil || len(dev.MountPoint) > len(found.MountPoint) {
+			found = dev
+		}
+	}
+	return found
+}
@@ -26,6 +26,19 @@ func TestNested(t *testing.T) {
 	assert.Equal(t, "/xxx/yyy", mountsNested[0])
 }
 
+func TestGetDeviceForPath(t *testing.T) {
+	root := &Device{Name: "/dev/sda1", MountPoint: "/"}
+	home := &Device{Name: "/dev/sda2", MountPoint: "/home"}
+	homer := &Device{Name: "/dev/sda3", MountPoint: "/homer"}
+
+	devices := Devices{root, homer, home}
+
+	assert.Equal(t, home, GetDeviceForPath("/home/user", devices))
+	assert.Equal(t, home, GetDeviceForPath("/home", devices))
+	assert.Equal(t, root, GetDeviceForPath("/var", devices))
+	assert.Nil(t, GetDeviceForPath("/var", Devices{home}))
+}
+
 func TestSortByName(t *testing.T) {
 	item := &Device{
 		Name: "/xxx",
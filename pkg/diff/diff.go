@@ -0,0 +1,242 @@
+// Package diff compares two analyses of the same directory tree
+package diff
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// Status describes how item changed between old and new analysis
+type Status int
+
+const (
+	// Unchanged item is present in both analyses with the same size
+	Unchanged Status = iota
+	// Changed item is present in both analyses but its size or content differs
+	Changed
+	// Added item is present only in the new analysis
+	Added
+	// Removed item is present only in the old analysis
+	Removed
+)
+
+// String returns name of the status
+func (s Status) String() string {
+	switch s {
+	case Changed:
+		return "changed"
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	default:
+		return "unchanged"
+	}
+}
+
+// Item represents difference of one item between old and new analysis
+type Item struct {
+	Parent       *Item
+	Children     []*Item
+	index        map[string]*Item
+	Name         string
+	OldSize      int64
+	NewSize      int64
+	OldUsage     int64
+	NewUsage     int64
+	OldItemCount int
+	NewItemCount int
+	Status       Status
+	IsDir        bool
+	inOld        bool
+	inNew        bool
+}
+
+// GetPath returns path of the item under the root of the new analysis
+func (i *Item) GetPath() string {
+	if i.Parent == nil {
+		return i.Name
+	}
+	return filepath.Join(i.Parent.GetPath(), i.Name)
+}
+
+// SizeDelta returns change of apparent size
+func (i *Item) SizeDelta() int64 {
+	return i.NewSize - i.OldSize
+}
+
+// UsageDelta returns change of disk usage
+func (i *Item) UsageDelta() int64 {
+	return i.NewUsage - i.OldUsage
+}
+
+// Delta returns change of apparent size or disk usage
+func (i *Item) Delta(apparentSize bool) int64 {
+	if apparentSize {
+		return i.SizeDelta()
+	}
+	return i.UsageDelta()
+}
+
+// ItemCountDelta returns change of number of items
+func (i *Item) ItemCountDelta() int {
+	return i.NewItemCount - i.OldItemCount
+}
+
+// SortChildren sorts children by absolute change, biggest first
+func (i *Item) SortChildren(apparentSize bool) {
+	sortByChange(i.Children, apparentSize)
+}
+
+func (i *Item) add(item fs.Item, old bool) {
+	if old {
+		i.inOld = true
+		i.OldSize = item.GetSize()
+		i.OldUsage = item.GetUsage()
+		i.OldItemCount = item.GetItemCount()
+	} else {
+		i.inNew = true
+		i.NewSize = item.GetSize()
+		i.NewUsage = item.GetUsage()
+		i.NewItemCount = item.GetItemCount()
+	}
+	i.IsDir = item.IsDir()
+
+	if !item.IsDir() {
+		return
+	}
+
+	if i.index == nil {
+		i.index = make(map[string]*Item, len(i.Children))
+		for _, child := range i.Children {
+			i.index[child.Name] = child
+		}
+	}
+	for _, file := range item.GetFiles() {
+		child, ok := i.index[file.GetName()]
+		if !ok {
+			child = &Item{Name: file.GetName(), Parent: i}
+			i.index[child.Name] = child
+			i.Children = append(i.Children, child)
+		}
+		child.add(file, old)
+	}
+}
+
+func (i *Item) finish(apparentSize bool) {
+	i.index = nil
+
+	switch {
+	case i.inOld && !i.inNew:
+		i.Status = Removed
+	case !i.inOld && i.inNew:
+		i.Status = Added
+	case i.OldSize != i.NewSize || i.OldUsage != i.NewUsage || i.OldItemCount != i.NewItemCount:
+		i.Status = Changed
+	default:
+		i.Status = Unchanged
+	}
+
+	for _, child := range i.Children {
+		child.finish(apparentSize)
+		if child.Status != Unchanged && i.Status == Unchanged {
+			i.Status = Changed
+		}
+	}
+	i.SortChildren(apparentSize)
+}
+
+// Tree builds tree of differences incrementally.
+// The old analysis has to be added before the new one.
+// Analyses don't need to be held in memory at the same time,
+// so they can be read e.g. from storage one after another.
+type Tree struct {
+	root *Item
+}
+
+// NewTree returns empty tree of differences
+func NewTree() *Tree {
+	return &Tree{root: &Item{}}
+}
+
+// AddOld adds old analysis into the tree
+func (t *Tree) AddOld(item fs.Item) {
+	t.root.Name = item.GetPath()
+	t.root.add(item, true)
+}
+
+// AddNew adds new analysis into the tree
+func (t *Tree) AddNew(item fs.Item) {
+	t.root.Name = item.GetPath()
+	t.root.add(item, false)
+}
+
+// Root computes status of all items and returns root of the tree.
+// Children are sorted by change of apparent size or disk usage.
+// Roots of both analyses are always matched together and their children
+// are matched by relative path, so analyses taken under different
+// mount prefixes line up.
+func (t *Tree) Root(apparentSize bool) *Item {
+	t.root.finish(apparentSize)
+	return t.root
+}
+
+// Compare returns tree of differences between old and new analysis
+func Compare(old, new fs.Item, apparentSize bool) *Item {
+	tree := NewTree()
+	tree.AddOld(old)
+	tree.AddNew(new)
+	return tree.Root(apparentSize)
+}
+
+// Top returns n biggest changes sorted by absolute change.
+// Changed directories are descended into, added and removed directories
+// are reported as a whole. Changed directories without changed items
+// (e.g. in trees of directories only) are reported themselves.
+// All changes are returned if n is not positive.
+func Top(root *Item, n int, apparentSize bool) []*Item {
+	var changes []*Item
+
+	var walk func(item *Item)
+	walk = func(item *Item) {
+		for _, child := range item.Children {
+			switch {
+			case child.Status == Unchanged:
+			case child.Status == Changed && child.IsDir:
+				count := len(changes)
+				walk(child)
+				if len(changes) == count {
+					changes = append(changes, child)
+				}
+			default:
+				changes = append(changes, child)
+			}
+		}
+	}
+	walk(root)
+
+	sortByChange(changes, apparentSize)
+	if n > 0 && len(changes) > n {
+		changes = changes[:n]
+	}
+	return changes
+}
+
+func sortByChange(items []*Item, apparentSize bool) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := abs(items[i].Delta(apparentSize)), abs(items[j].Delta(apparentSize))
+		if a != b {
+			return a > b
+		}
+		return items[i].Name < items[j].Name
+	})
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,184 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/stretchr/testify/assert"
+)
+
+func createDir(basePath, name string, files ...fs.Item) *analyze.Dir {
+	dir := &analyze.Dir{
+		File:     &analyze.File{Name: name},
+		BasePath: basePath,
+	}
+	for _, file := range files {
+		file.SetParent(dir)
+		dir.AddFile(file)
+	}
+	return dir
+}
+
+func createFile(name string, size int64) *analyze.File {
+	return &analyze.File{Name: name, Size: size, Usage: size}
+}
+
+func createTrees() (old, new *analyze.Dir) {
+	old = createDir("/mnt/old", "data",
+		createDir("", "logs",
+			createFile("app.log", 100),
+			createFile("old.log", 50),
+		),
+		createDir("", "cache",
+			createFile("a", 10),
+		),
+		createFile("same", 5),
+		createFile("shrunk", 300),
+	)
+	old.UpdateStats(make(fs.HardLinkedItems))
+
+	new = createDir("/mnt/new", "data",
+		createDir("", "logs",
+			createFile("app.log", 1000),
+			createFile("new.log", 20),
+		),
+		createDir("", "videos",
+			createFile("movie", 5000),
+		),
+		createFile("same", 5),
+		createFile("shrunk", 100),
+	)
+	new.UpdateStats(make(fs.HardLinkedItems))
+	return old, new
+}
+
+func findChild(item *Item, name string) *Item {
+	for _, child := range item.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+func TestCompare(t *testing.T) {
+	old, new := createTrees()
+
+	root := Compare(old, new, false)
+
+	assert.Equal(t, "/mnt/new/data", root.GetPath())
+	assert.Equal(t, Changed, root.Status)
+	assert.Equal(t, new.GetUsage()-old.GetUsage(), root.UsageDelta())
+
+	logs := findChild(root, "logs")
+	assert.Equal(t, Changed, logs.Status)
+	assert.True(t, logs.IsDir)
+	assert.Equal(t, "/mnt/new/data/logs", logs.GetPath())
+	assert.Equal(t, Changed, findChild(logs, "app.log").Status)
+	assert.Equal(t, int64(900), findChild(logs, "app.log").SizeDelta())
+	assert.Equal(t, Removed, findChild(logs, "old.log").Status)
+	assert.Equal(t, Added, findChild(logs, "new.log").Status)
+
+	assert.Equal(t, Removed, findChild(root, "cache").Status)
+	assert.Equal(t, Added, findChild(root, "videos").Status)
+	assert.Equal(t, Unchanged, findChild(root, "same").Status)
+	assert.Equal(t, int64(-200), findChild(root, "shrunk").UsageDelta())
+}
+
+func TestCompareSortsByAbsoluteChange(t *testing.T) {
+	old, new := createTrees()
+
+	root := Compare(old, new, false)
+
+	names := make([]string, 0, len(root.Children))
+	for _, child := range root.Children {
+		names = append(names, child.Name)
+	}
+	assert.Equal(t, []string{"videos", "cache", "logs", "shrunk", "same"}, names)
+}
+
+func TestCompareSortsByApparentSize(t *testing.T) {
+	old := createDir("/old", "root",
+		&analyze.File{Name: "sparse", Size: 10, Usage: 4096},
+		&analyze.File{Name: "big", Size: 1000, Usage: 1000},
+	)
+	old.UpdateStats(make(fs.HardLinkedItems))
+	new := createDir("/new", "root")
+	new.UpdateStats(make(fs.HardLinkedItems))
+
+	root := Compare(old, new, false)
+	assert.Equal(t, "sparse", root.Children[0].Name)
+
+	root = Compare(old, new, true)
+	assert.Equal(t, "big", root.Children[0].Name)
+}
+
+func TestCompareIdentical(t *testing.T) {
+	old, _ := createTrees()
+	new, _ := createTrees()
+
+	root := Compare(old, new, false)
+
+	assert.Equal(t, Unchanged, root.Status)
+	assert.Empty(t, Top(root, 10, false))
+}
+
+func TestTop(t *testing.T) {
+	old, new := createTrees()
+
+	root := Compare(old, new, false)
+	top := Top(root, 0, true)
+
+	paths := make([]string, 0, len(top))
+	for _, item := range top {
+		paths = append(paths, item.GetPath())
+	}
+	assert.Equal(t, []string{
+		"/mnt/new/data/videos",
+		"/mnt/new/data/cache",
+		"/mnt/new/data/logs/app.log",
+		"/mnt/new/data/shrunk",
+		"/mnt/new/data/logs/old.log",
+		"/mnt/new/data/logs/new.log",
+	}, paths)
+
+	assert.Len(t, Top(root, 2, true), 2)
+}
+
+func TestTopOfDirectoriesOnly(t *testing.T) {
+	old := createDir("/old", "root", createDir("", "a", createDir("", "b")))
+	old.UpdateStats(make(fs.HardLinkedItems))
+	new := createDir("/new", "root", createDir("", "a", createDir("", "b")))
+	new.UpdateStats(make(fs.HardLinkedItems))
+	new.Files[0].(*analyze.Dir).Size = 100
+
+	top := Top(Compare(old, new, true), 0, true)
+
+	assert.Len(t, top, 1)
+	assert.Equal(t, "/new/root/a", top[0].GetPath())
+}
+
+func TestTreeWithTypeChange(t *testing.T) {
+	old := createDir("/old", "root", createFile("x", 10))
+	old.UpdateStats(make(fs.HardLinkedItems))
+	new := createDir("/new", "root", createDir("", "x", createFile("y", 20)))
+	new.UpdateStats(make(fs.HardLinkedItems))
+
+	tree := NewTree()
+	tree.AddOld(old)
+	tree.AddNew(new)
+	root := tree.Root(false)
+
+	x := findChild(root, "x")
+	assert.True(t, x.IsDir)
+	assert.Equal(t, Changed, x.Status)
+	assert.Equal(t, Added, findChild(x, "y").Status)
+}
+
+func TestStatusString(t *testing.T) {
+	assert.Equal(t, "unchanged", Unchanged.String())
+	assert.Equal(t, "changed", Changed.String())
+	assert.Equal(t, "added", Added.String())
+	assert.Equal(t, "removed", Removed.String())
+}
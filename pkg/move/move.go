@@ -0,0 +1,197 @@
+// Package move moves files and directories to another path, also across filesystems
+package move
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInsideMovedItem is returned when the item would be moved inside of itself
+var ErrInsideMovedItem = errors.New("item can't be moved inside of itself")
+
+// Item moves item from src to dst path.
+// The item is renamed when both paths are on the same device,
+// otherwise it's copied, the copy is verified and the source is removed.
+// The copy keeps owners, modes (including setuid, setgid and sticky bits), times
+// and hard links within the item, the source is kept if the owner can't be set.
+// If leaveSymlink is set, symlink pointing to the new location is created in place of the source.
+// Progress is called with the number of bytes copied so far.
+func Item(src, dst string, leaveSymlink bool, progress func(int64)) error {
+	rel, err := filepath.Rel(src, dst)
+	if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ErrInsideMovedItem
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("%s already exists", dst)
+	}
+
+	err = os.Rename(src, dst)
+	if isCrossDevice(err) {
+		err = copyVerifyRemove(src, dst, progress)
+	}
+	if err != nil {
+		return err
+	}
+
+	if leaveSymlink {
+		return os.Symlink(dst, src)
+	}
+	return nil
+}
+
+// copyVerifyRemove copies src to dst, verifies content of the copied files and removes src
+func copyVerifyRemove(src, dst string, progress func(int64)) error {
+	c := &copier{progress: progress}
+	if err := c.copyTree(src, dst); err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+type copier struct {
+	copied   int64
+	progress func(int64)
+	// copied files with more hard links by their device and inode
+	links map[fileID]string
+}
+
+func (c *copier) copyTree(src, dst string) error {
+	var dirs []string
+	c.links = make(map[fileID]string)
+
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			// directory must be writable until its content is copied
+			dirs = append(dirs, rel)
+			return os.Mkdir(target, 0o700)
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if err := os.Symlink(link, target); err != nil {
+				return err
+			}
+			return setOwner(target, info)
+		case info.Mode().IsRegular():
+			id, linked := getFileID(info)
+			if first, ok := c.links[id]; linked && ok {
+				return os.Link(first, target)
+			}
+			if linked {
+				c.links[id] = target
+			}
+			return c.copyFile(path, target, info)
+		}
+		return fmt.Errorf("%s: unsupported file type", path)
+	})
+	if err != nil {
+		return err
+	}
+
+	// set attributes of directories from the deepest ones
+	for i := len(dirs) - 1; i >= 0; i-- {
+		info, err := os.Stat(filepath.Join(src, dirs[i]))
+		if err != nil {
+			return err
+		}
+		if err := setAttributes(filepath.Join(dst, dirs[i]), info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setAttributes sets owner, mode and times of the copy to the ones of the source.
+// Mode is set after the owner as changing the owner clears setuid and setgid bits.
+func setAttributes(path string, info os.FileInfo) error {
+	if err := setOwner(path, info); err != nil {
+		return err
+	}
+	mode := info.Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+	if err := os.Chmod(path, mode); err != nil {
+		return err
+	}
+	return os.Chtimes(path, info.ModTime(), info.ModTime())
+}
+
+// copyFile copies content of the file and checks that the copy has the same checksum
+func (c *copier) copyFile(src, dst string, info os.FileInfo) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	hash := crc32.NewIEEE()
+	_, err = io.Copy(io.MultiWriter(out, hash), &progressReader{Reader: in, copier: c})
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := verify(dst, hash.Sum(nil)); err != nil {
+		return err
+	}
+	return setAttributes(dst, info)
+}
+
+func verify(path string, sum []byte) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	hash := crc32.NewIEEE()
+	if _, err := io.Copy(hash, f); err != nil {
+		return err
+	}
+	if !bytes.Equal(hash.Sum(nil), sum) {
+		return fmt.Errorf("verification of %s failed: content differs from the source", path)
+	}
+	return nil
+}
+
+type progressReader struct {
+	io.Reader
+	copier *copier
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.copier.copied += int64(n)
+	if r.copier.progress != nil && n > 0 {
+		r.copier.progress(r.copier.copied)
+	}
+	return n, err
+}
@@ -0,0 +1,22 @@
+//go:build plan9
+
+package move
+
+import "os"
+
+// fileID identifies file, it's empty as hard links are not detected here
+type fileID struct{}
+
+func isCrossDevice(err error) bool {
+	return false
+}
+
+// getFileID returns ID of the file and whether the file has more hard links
+func getFileID(_ os.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
+
+// setOwner does nothing as owner can't be set on plan9
+func setOwner(_ string, _ os.FileInfo) error {
+	return nil
+}
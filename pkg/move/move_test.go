@@ -0,0 +1,166 @@
+//go:build !windows
+
+package move
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createItem(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	src := filepath.Join(dir, "project")
+	assert.Nil(t, os.MkdirAll(filepath.Join(src, "src"), 0o755))
+	assert.Nil(t, os.WriteFile(filepath.Join(src, "src", "main.go"), []byte("package main\n"), 0o644))
+	assert.Nil(t, os.WriteFile(filepath.Join(src, "README"), []byte("hello"), 0o600))
+	assert.Nil(t, os.Symlink("README", filepath.Join(src, "link")))
+	assert.Nil(t, os.Chmod(filepath.Join(src, "src"), 0o555))
+	t.Cleanup(func() {
+		os.Chmod(filepath.Join(src, "src"), 0o755)
+		os.Chmod(filepath.Join(dir, "big", "project", "src"), 0o755)
+	})
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "big"), 0o755))
+	return src, filepath.Join(dir, "big", "project")
+}
+
+func assertMoved(t *testing.T, dst string) {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(dst, "src", "main.go"))
+	assert.Nil(t, err)
+	assert.Equal(t, "package main\n", string(content))
+	target, err := os.Readlink(filepath.Join(dst, "link"))
+	assert.Nil(t, err)
+	assert.Equal(t, "README", target)
+}
+
+func TestMoveItem(t *testing.T) {
+	src, dst := createItem(t)
+
+	assert.Nil(t, Item(src, dst, false, nil))
+	assert.NoDirExists(t, src)
+	assertMoved(t, dst)
+}
+
+func TestMoveItemWithSymlink(t *testing.T) {
+	src, dst := createItem(t)
+
+	assert.Nil(t, Item(src, dst, true, nil))
+	target, err := os.Readlink(src)
+	assert.Nil(t, err)
+	assert.Equal(t, dst, target)
+	assertMoved(t, dst)
+}
+
+func TestMoveItemErrors(t *testing.T) {
+	src, dst := createItem(t)
+
+	assert.ErrorIs(t, Item(src, filepath.Join(src, "src", "project"), false, nil), ErrInsideMovedItem)
+	assert.ErrorContains(t, Item(src, filepath.Dir(dst), false, nil), "already exists")
+	assert.NotNil(t, Item(filepath.Join(src, "xxx"), dst, false, nil))
+	assert.DirExists(t, src)
+}
+
+func TestCopyVerifyRemove(t *testing.T) {
+	src, dst := createItem(t)
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Nil(t, os.Chtimes(filepath.Join(src, "README"), mtime, mtime))
+
+	var copied int64
+	assert.Nil(t, copyVerifyRemove(src, dst, func(c int64) {
+		copied = c
+	}))
+	assert.Equal(t, int64(18), copied)
+	assert.NoDirExists(t, src)
+	assertMoved(t, dst)
+
+	// attributes are kept
+	info, err := os.Stat(filepath.Join(dst, "README"))
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	assert.True(t, mtime.Equal(info.ModTime()))
+	info, err = os.Stat(filepath.Join(dst, "src"))
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0o555), info.Mode().Perm())
+}
+
+func TestCopyVerifyRemoveKeepsHardLinks(t *testing.T) {
+	src, dst := createItem(t)
+	assert.Nil(t, os.Link(filepath.Join(src, "README"), filepath.Join(src, "README.link")))
+
+	var copied int64
+	assert.Nil(t, copyVerifyRemove(src, dst, func(c int64) {
+		copied = c
+	}))
+	// content of the linked file is copied only once
+	assert.Equal(t, int64(18), copied)
+
+	info, err := os.Stat(filepath.Join(dst, "README"))
+	assert.Nil(t, err)
+	linkInfo, err := os.Stat(filepath.Join(dst, "README.link"))
+	assert.Nil(t, err)
+	assert.True(t, os.SameFile(info, linkInfo))
+	assert.Equal(t, uint64(2), uint64(info.Sys().(*syscall.Stat_t).Nlink))
+}
+
+func TestCopyVerifyRemoveKeepsSpecialModes(t *testing.T) {
+	src, dst := createItem(t)
+	assert.Nil(t, os.WriteFile(filepath.Join(src, "tool"), []byte("#!/bin/sh\n"), 0o755))
+	assert.Nil(t, os.Chmod(filepath.Join(src, "tool"), 0o755|os.ModeSetuid|os.ModeSetgid))
+	assert.Nil(t, os.Mkdir(filepath.Join(src, "shared"), 0o755))
+	assert.Nil(t, os.Chmod(filepath.Join(src, "shared"), 0o777|os.ModeSetgid|os.ModeSticky))
+
+	assert.Nil(t, copyVerifyRemove(src, dst, nil))
+
+	info, err := os.Stat(filepath.Join(dst, "tool"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0o755|os.ModeSetuid|os.ModeSetgid, info.Mode()&^os.ModeType)
+	info, err = os.Stat(filepath.Join(dst, "shared"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0o777|os.ModeSetgid|os.ModeSticky, info.Mode()&^os.ModeType)
+}
+
+func TestCopyVerifyRemoveKeepsOwner(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("changing owner requires root")
+	}
+	src, dst := createItem(t)
+	assert.Nil(t, os.Lchown(filepath.Join(src, "README"), 1234, 2345))
+	assert.Nil(t, os.Lchown(filepath.Join(src, "link"), 1234, 2345))
+	assert.Nil(t, os.Lchown(filepath.Join(src, "src"), 3456, 4567))
+
+	assert.Nil(t, copyVerifyRemove(src, dst, nil))
+
+	for path, owner := range map[string][2]uint32{
+		"README": {1234, 2345},
+		"link":   {1234, 2345},
+		"src":    {3456, 4567},
+	} {
+		info, err := os.Lstat(filepath.Join(dst, path))
+		assert.Nil(t, err)
+		stat := info.Sys().(*syscall.Stat_t)
+		assert.Equal(t, owner, [2]uint32{stat.Uid, stat.Gid}, path)
+	}
+}
+
+func TestCopyVerifyRemoveWithErr(t *testing.T) {
+	src, dst := createItem(t)
+	assert.Nil(t, syscall.Mkfifo(filepath.Join(src, "zfifo"), 0o644))
+
+	// partial copy is removed when the source can't be copied
+	assert.ErrorContains(t, copyVerifyRemove(src, dst, nil), "unsupported file type")
+	assert.NoDirExists(t, dst)
+	assert.FileExists(t, filepath.Join(src, "README"))
+}
+
+func TestVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	assert.Nil(t, os.WriteFile(path, []byte("abc"), 0o644))
+
+	assert.ErrorContains(t, verify(path, []byte{1, 2, 3, 4}), "content differs")
+}
@@ -0,0 +1,41 @@
+//go:build !windows && !plan9
+
+package move
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// fileID identifies file by its device and inode
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
+
+// getFileID returns ID of the file and whether the file has more hard links
+func getFileID(info os.FileInfo) (fileID, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, false
+	}
+	return fileID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, stat.Nlink > 1
+}
+
+// setOwner sets owner and group of the path (not following symlinks) to the ones of the source
+func setOwner(path string, info os.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	if err := os.Lchown(path, int(stat.Uid), int(stat.Gid)); err != nil {
+		return fmt.Errorf("owner of %s can't be kept: %w", path, err)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/dundee/gdu/v5/pkg/analyze"
 	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/trash"
 )
 
 // ItemFromDir removes item from dir
@@ -18,6 +19,17 @@ func ItemFromDir(dir, item fs.Item) error {
 	return nil
 }
 
+// ItemToTrash moves item from dir to the trash
+func ItemToTrash(dir, item fs.Item) error {
+	err := trash.Put(item.GetPath())
+	if err != nil {
+		return err
+	}
+
+	dir.RemoveFile(item)
+	return nil
+}
+
 // EmptyFileFromDir empty file from dir
 func EmptyFileFromDir(dir, file fs.Item) error {
 	err := os.Truncate(file.GetPath(), 0)
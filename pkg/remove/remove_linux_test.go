@@ -4,10 +4,12 @@ package remove
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/dundee/gdu/v5/internal/testdir"
 	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -39,3 +41,41 @@ func TestRemoveFileWithErr(t *testing.T) {
 	err = ItemFromDir(dir, subdir)
 	assert.Contains(t, err.Error(), "permission denied")
 }
+
+func TestItemToTrash(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	// keep the trash on the same device as the test dir
+	dataHome, err := filepath.Abs("test_dir/data")
+	assert.Nil(t, err)
+	t.Setenv("XDG_DATA_HOME", dataHome)
+
+	dir := &analyze.Dir{
+		File: &analyze.File{
+			Name:  "test_dir",
+			Size:  5,
+			Usage: 12,
+		},
+		ItemCount: 3,
+		BasePath:  ".",
+	}
+	subdir := &analyze.Dir{
+		File: &analyze.File{
+			Name:   "nested",
+			Size:   4,
+			Usage:  8,
+			Parent: dir,
+		},
+		ItemCount: 2,
+	}
+	dir.Files = fs.Files{subdir}
+
+	err = ItemToTrash(dir, subdir)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(dir.Files))
+	assert.Equal(t, int64(1), dir.Size)
+	assert.NoDirExists(t, "test_dir/nested")
+	assert.DirExists(t, "test_dir/data/Trash/files/nested")
+	assert.FileExists(t, "test_dir/data/Trash/info/nested.trashinfo")
+}
@@ -0,0 +1,234 @@
+package search
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// Modes of matching names of the items
+const (
+	ModeSubstring = "substring"
+	ModeGlob      = "glob"
+	ModeRegex     = "regex"
+	ModeFuzzy     = "fuzzy"
+)
+
+// Modes lists all available modes of matching
+var Modes = []string{ModeSubstring, ModeGlob, ModeRegex, ModeFuzzy}
+
+// number of checked items after which the context is checked for cancellation
+const cancelCheckInterval = 1000
+
+// Matcher returns score of the name and whether the name matches at all.
+// Higher score means better match.
+type Matcher func(name string) (int, bool)
+
+// NewMatcher creates matcher for the pattern in the given mode.
+// Matching is case-insensitive unless the pattern contains an upper case letter.
+func NewMatcher(mode, pattern string) (Matcher, error) {
+	ignoreCase := strings.ToLower(pattern) == pattern
+	if ignoreCase {
+		pattern = strings.ToLower(pattern)
+	}
+	normalize := func(name string) string {
+		if ignoreCase {
+			return strings.ToLower(name)
+		}
+		return name
+	}
+
+	switch mode {
+	case ModeSubstring:
+		return func(name string) (int, bool) {
+			return substringScore(normalize(name), pattern)
+		}, nil
+	case ModeGlob:
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern: %w", err)
+		}
+		return func(name string) (int, bool) {
+			ok, _ := filepath.Match(pattern, normalize(name))
+			return 0, ok
+		}, nil
+	case ModeRegex:
+		if ignoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %w", err)
+		}
+		return func(name string) (int, bool) {
+			loc := re.FindStringIndex(name)
+			if loc == nil {
+				return 0, false
+			}
+			// shorter names with longer matches are better
+			return (loc[1]-loc[0])*10 - len(name), true
+		}, nil
+	case ModeFuzzy:
+		return func(name string) (int, bool) {
+			return fuzzyScore(normalize(name), pattern)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown search mode: %s", mode)
+}
+
+func substringScore(name, pattern string) (int, bool) {
+	index := strings.Index(name, pattern)
+	if index < 0 {
+		return 0, false
+	}
+	score := 100 - (len(name) - len(pattern))
+	switch {
+	case len(name) == len(pattern):
+		score += 1000
+	case index == 0:
+		score += 500
+	case isWordStart(name, index):
+		score += 200
+	}
+	return score, true
+}
+
+// fuzzyScore matches characters of the pattern in the name in the same order.
+// Consecutive characters and characters at start of words are preferred, gaps are penalized.
+func fuzzyScore(name, pattern string) (int, bool) {
+	score := 0
+	last := -1
+	index := 0
+	for _, r := range pattern {
+		found := strings.IndexRune(name[index:], r)
+		if found < 0 {
+			return 0, false
+		}
+		pos := index + found
+
+		switch {
+		case pos == last+1:
+			score += 15
+		default:
+			score -= pos - last - 1
+		}
+		if isWordStart(name, pos) {
+			score += 10
+		}
+
+		last = pos
+		index = pos + utf8.RuneLen(r)
+	}
+	return score - (len(name)-len(pattern))/4, true
+}
+
+func isWordStart(name string, index int) bool {
+	if index == 0 {
+		return true
+	}
+	prev, _ := utf8.DecodeLastRuneInString(name[:index])
+	return !unicode.IsLetter(prev) && !unicode.IsDigit(prev)
+}
+
+// Result is one item found by the search
+type Result struct {
+	Item  fs.Item
+	Score int
+}
+
+// Search walks the whole tree under dir and returns at most limit best matching items
+// ordered by score and size. It returns early with results found so far when ctx is cancelled.
+// Count of checked items is reported to progress periodically if given.
+func Search(
+	ctx context.Context, dir fs.Item, match Matcher, limit int, useApparentSize bool, progress func(int),
+) []Result {
+	s := &searcher{
+		ctx:      ctx,
+		match:    match,
+		limit:    limit,
+		results:  &resultHeap{apparent: useApparentSize},
+		progress: progress,
+	}
+	s.walk(dir)
+
+	results := make([]Result, s.results.Len())
+	for i := len(results) - 1; i >= 0; i-- {
+		results[i] = heap.Pop(s.results).(Result)
+	}
+	return results
+}
+
+type searcher struct {
+	ctx      context.Context
+	match    Matcher
+	results  *resultHeap
+	progress func(int)
+	limit    int
+	checked  int
+}
+
+// walk returns false when the search has been cancelled
+func (s *searcher) walk(dir fs.Item) bool {
+	for _, item := range dir.GetFilesLocked() {
+		s.checked++
+		if s.checked%cancelCheckInterval == 0 {
+			if s.ctx.Err() != nil {
+				return false
+			}
+			if s.progress != nil {
+				s.progress(s.checked)
+			}
+		}
+
+		if score, ok := s.match(item.GetName()); ok {
+			s.add(Result{Item: item, Score: score})
+		}
+		if item.IsDir() && !s.walk(item) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *searcher) add(result Result) {
+	if s.results.Len() < s.limit {
+		heap.Push(s.results, result)
+		return
+	}
+	if s.limit > 0 && s.results.less(s.results.items[0], result) {
+		s.results.items[0] = result
+		heap.Fix(s.results, 0)
+	}
+}
+
+// resultHeap keeps the worst result on the top so it can be replaced by a better one
+type resultHeap struct {
+	items    []Result
+	apparent bool
+}
+
+func (h *resultHeap) less(a, b Result) bool {
+	if a.Score != b.Score {
+		return a.Score < b.Score
+	}
+	if h.apparent {
+		return a.Item.GetSize() < b.Item.GetSize()
+	}
+	return a.Item.GetUsage() < b.Item.GetUsage()
+}
+
+func (h *resultHeap) Len() int           { return len(h.items) }
+func (h *resultHeap) Less(i, j int) bool { return h.less(h.items[i], h.items[j]) }
+func (h *resultHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *resultHeap) Push(x any)         { h.items = append(h.items, x.(Result)) }
+func (h *resultHeap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
@@ -0,0 +1,121 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+)
+
+func createTree() *analyze.Dir {
+	dir := &analyze.Dir{
+		File: &analyze.File{
+			Name: "root",
+		},
+		BasePath: ".",
+	}
+	nested := &analyze.Dir{
+		File: &analyze.File{
+			Name:   "node_modules",
+			Usage:  400,
+			Parent: dir,
+		},
+	}
+	dir.AddFile(nested)
+	for _, file := range []*analyze.File{
+		{Name: "report.txt", Usage: 100, Parent: dir},
+		{Name: "old_report.txt", Usage: 300, Parent: dir},
+		{Name: "Makefile", Usage: 50, Parent: dir},
+		{Name: "module.js", Usage: 200, Parent: nested},
+		{Name: "report", Usage: 10, Parent: nested},
+	} {
+		file.Parent.AddFile(file)
+	}
+	return dir
+}
+
+func names(results []Result) []string {
+	res := make([]string, 0, len(results))
+	for _, result := range results {
+		res = append(res, result.Item.GetName())
+	}
+	return res
+}
+
+func find(t *testing.T, mode, pattern string, limit int) []Result {
+	t.Helper()
+	match, err := NewMatcher(mode, pattern)
+	assert.NoError(t, err)
+	return Search(context.Background(), createTree(), match, limit, false, nil)
+}
+
+func TestSearchSubstring(t *testing.T) {
+	// exact match first, then prefix match, then match at start of word
+	assert.Equal(t, []string{"report", "report.txt", "old_report.txt"}, names(find(t, ModeSubstring, "report", 10)))
+	assert.Equal(t, []string{"Makefile"}, names(find(t, ModeSubstring, "makef", 10)))
+	// upper case letter makes the search case-sensitive
+	assert.Empty(t, find(t, ModeSubstring, "MAKE", 10))
+}
+
+func TestSearchGlob(t *testing.T) {
+	// items with the same score are ordered by size
+	assert.Equal(t, []string{"old_report.txt", "report.txt"}, names(find(t, ModeGlob, "*.txt", 10)))
+
+	_, err := NewMatcher(ModeGlob, "[")
+	assert.ErrorContains(t, err, "invalid glob pattern")
+}
+
+func TestSearchRegex(t *testing.T) {
+	assert.Equal(t, []string{"node_modules", "module.js"}, names(find(t, ModeRegex, "^mod|_mod", 10)))
+
+	_, err := NewMatcher(ModeRegex, "(")
+	assert.ErrorContains(t, err, "invalid regular expression")
+}
+
+func TestSearchFuzzy(t *testing.T) {
+	// consecutive characters and starts of words are preferred
+	assert.Equal(t, []string{"module.js", "node_modules"}, names(find(t, ModeFuzzy, "mod", 10)))
+	assert.Equal(t, []string{"report", "report.txt", "old_report.txt"}, names(find(t, ModeFuzzy, "rep", 10)))
+	assert.Equal(t, []string{"node_modules"}, names(find(t, ModeFuzzy, "nm", 10)))
+	assert.Empty(t, find(t, ModeFuzzy, "zz", 10))
+}
+
+func TestSearchLimit(t *testing.T) {
+	// the best results are kept
+	assert.Equal(t, []string{"report", "report.txt"}, names(find(t, ModeSubstring, "report", 2)))
+	assert.Empty(t, find(t, ModeSubstring, "report", 0))
+}
+
+func TestSearchCancelled(t *testing.T) {
+	dir := &analyze.Dir{File: &analyze.File{Name: "root"}, BasePath: "."}
+	for range cancelCheckInterval * 2 {
+		dir.AddFile(&analyze.File{Name: "file", Parent: dir})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	match, _ := NewMatcher(ModeSubstring, "file")
+	results := Search(ctx, dir, match, cancelCheckInterval*2, false, nil)
+	assert.Len(t, results, cancelCheckInterval-1)
+}
+
+func TestSearchProgress(t *testing.T) {
+	dir := &analyze.Dir{File: &analyze.File{Name: "root"}, BasePath: "."}
+	for range cancelCheckInterval {
+		dir.AddFile(&analyze.File{Name: "file", Parent: dir})
+	}
+
+	var counts []int
+	match, _ := NewMatcher(ModeSubstring, "x")
+	Search(context.Background(), dir, match, 10, false, func(count int) {
+		counts = append(counts, count)
+	})
+	assert.Equal(t, []int{cancelCheckInterval}, counts)
+}
+
+func TestUnknownMode(t *testing.T) {
+	_, err := NewMatcher("xxx", "a")
+	assert.ErrorContains(t, err, "unknown search mode: xxx")
+}
@@ -0,0 +1,59 @@
+package theme
+
+// builtinThemes are themes available without any configuration
+var builtinThemes = map[string]*Theme{
+	DefaultName: {
+		Header:      ColorStyle{TextColor: "#000000", BackgroundColor: "#2479D0"},
+		Footer:      FooterStyle{TextColor: "#000000", BackgroundColor: "#2479D0", NumberColor: "#FFFFFF"},
+		SelectedRow: ColorStyle{TextColor: "white", BackgroundColor: "green"},
+		MarkedRow:   ColorStyle{TextColor: "white", BackgroundColor: "blue"},
+		IgnoredRow:  ColorStyle{TextColor: "yellow"},
+		ResultRow:   ResultRowStyle{NumberColor: "#e67100", DirectoryColor: "#3498db"},
+		Modal:       ModalStyle{BorderColor: "default", TitleColor: "#1ba1e3"},
+	},
+	"light": {
+		Header:      ColorStyle{TextColor: "#ffffff", BackgroundColor: "#1f5fa8"},
+		Footer:      FooterStyle{TextColor: "#ffffff", BackgroundColor: "#1f5fa8", NumberColor: "#ffd75f"},
+		SelectedRow: ColorStyle{TextColor: "#ffffff", BackgroundColor: "#1f5fa8"},
+		MarkedRow:   ColorStyle{TextColor: "#000000", BackgroundColor: "#ffd75f"},
+		IgnoredRow:  ColorStyle{TextColor: "#8a8a8a"},
+		ResultRow: ResultRowStyle{
+			NumberColor: "#b35900", DirectoryColor: "#005fd7", BarColor: "#1f5fa8", FlagColor: "#8a8a8a",
+		},
+		Modal: ModalStyle{
+			TextColor: "#000000", BackgroundColor: "#e4e4e4", BorderColor: "#1f5fa8", TitleColor: "#1f5fa8",
+		},
+		SizeRules: []SizeRule{{MinSize: "10G", Color: "#d70000"}},
+		AgeRules:  []AgeRule{{MinAge: "1y", Color: "#8a8a8a"}},
+	},
+	"gruvbox": {
+		Header:      ColorStyle{TextColor: "#282828", BackgroundColor: "#d79921"},
+		Footer:      FooterStyle{TextColor: "#282828", BackgroundColor: "#d79921", NumberColor: "#fbf1c7"},
+		SelectedRow: ColorStyle{TextColor: "#282828", BackgroundColor: "#83a598"},
+		MarkedRow:   ColorStyle{TextColor: "#282828", BackgroundColor: "#b8bb26"},
+		IgnoredRow:  ColorStyle{TextColor: "#928374"},
+		ResultRow: ResultRowStyle{
+			NumberColor: "#fe8019", DirectoryColor: "#83a598", BarColor: "#8ec07c", FlagColor: "#d3869b",
+		},
+		Modal: ModalStyle{
+			TextColor: "#ebdbb2", BackgroundColor: "#3c3836", BorderColor: "#d79921", TitleColor: "#fabd2f",
+		},
+		SizeRules: []SizeRule{{MinSize: "10G", Color: "#fb4934"}, {MinSize: "1G", Color: "#fabd2f"}},
+		AgeRules:  []AgeRule{{MinAge: "1y", Color: "#928374"}},
+	},
+	"nord": {
+		Header:      ColorStyle{TextColor: "#2e3440", BackgroundColor: "#88c0d0"},
+		Footer:      FooterStyle{TextColor: "#2e3440", BackgroundColor: "#88c0d0", NumberColor: "#eceff4"},
+		SelectedRow: ColorStyle{TextColor: "#eceff4", BackgroundColor: "#5e81ac"},
+		MarkedRow:   ColorStyle{TextColor: "#2e3440", BackgroundColor: "#a3be8c"},
+		IgnoredRow:  ColorStyle{TextColor: "#4c566a"},
+		ResultRow: ResultRowStyle{
+			NumberColor: "#d08770", DirectoryColor: "#81a1c1", BarColor: "#8fbcbb", FlagColor: "#b48ead",
+		},
+		Modal: ModalStyle{
+			TextColor: "#d8dee9", BackgroundColor: "#3b4252", BorderColor: "#88c0d0", TitleColor: "#88c0d0",
+		},
+		SizeRules: []SizeRule{{MinSize: "10G", Color: "#bf616a"}, {MinSize: "1G", Color: "#ebcb8b"}},
+		AgeRules:  []AgeRule{{MinAge: "1y", Color: "#4c566a"}},
+	},
+}
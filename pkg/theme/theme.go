@@ -0,0 +1,260 @@
+// Package theme defines colour themes of the interactive and non-interactive UI
+package theme
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dundee/gdu/v5/internal/common"
+	"github.com/dundee/gdu/v5/pkg/timefilter"
+)
+
+// DefaultName is the name of the theme used when no theme is configured
+const DefaultName = "default"
+
+// Theme defines colours of all parts of the UI
+type Theme struct {
+	Header      ColorStyle     `yaml:"header"`
+	Footer      FooterStyle    `yaml:"footer"`
+	SelectedRow ColorStyle     `yaml:"selected-row"`
+	MarkedRow   ColorStyle     `yaml:"marked-row"`
+	IgnoredRow  ColorStyle     `yaml:"ignored-row"`
+	ResultRow   ResultRowStyle `yaml:"result-row"`
+	Modal       ModalStyle     `yaml:"modal"`
+	SizeRules   []SizeRule     `yaml:"size-rules"`
+	AgeRules    []AgeRule      `yaml:"age-rules"`
+
+	sizeRules []sizeRule
+	ageRules  []ageRule
+}
+
+// ColorStyle defines text and background colour of some part of the UI
+type ColorStyle struct {
+	TextColor       string `yaml:"text-color"`
+	BackgroundColor string `yaml:"background-color"`
+}
+
+// FooterStyle defines colours of the footer
+type FooterStyle struct {
+	TextColor       string `yaml:"text-color"`
+	BackgroundColor string `yaml:"background-color"`
+	NumberColor     string `yaml:"number-color"`
+}
+
+// ResultRowStyle defines colours of the columns of result rows
+type ResultRowStyle struct {
+	NumberColor    string `yaml:"number-color"`
+	DirectoryColor string `yaml:"directory-color"`
+	BarColor       string `yaml:"bar-color"`
+	FlagColor      string `yaml:"flag-color"`
+}
+
+// ModalStyle defines colours of modals, forms and dialogs
+type ModalStyle struct {
+	TextColor       string `yaml:"text-color"`
+	BackgroundColor string `yaml:"background-color"`
+	BorderColor     string `yaml:"border-color"`
+	TitleColor      string `yaml:"title-color"`
+}
+
+// SizeRule colours rows of items with size of at least MinSize (e.g. 10G)
+type SizeRule struct {
+	MinSize string `yaml:"min-size"`
+	Color   string `yaml:"color"`
+}
+
+// AgeRule colours rows of items not modified for at least MinAge (e.g. 1y, 6mo)
+type AgeRule struct {
+	MinAge string `yaml:"min-age"`
+	Color  string `yaml:"color"`
+}
+
+type sizeRule struct {
+	size  int64
+	color string
+}
+
+type ageRule struct {
+	age   time.Duration
+	color string
+}
+
+// Names returns sorted names of the built-in themes
+func Names() []string {
+	names := make([]string, 0, len(builtinThemes))
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the theme of the given name.
+// User-defined themes are decoded on top of their base theme given by the "base" key
+// (the default theme is used when it's missing) and take precedence over built-in themes.
+func Get(name string, userThemes map[string]yaml.Node) (*Theme, error) {
+	if name == "" {
+		name = DefaultName
+	}
+	t, err := resolve(name, userThemes, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.compile(); err != nil {
+		return nil, fmt.Errorf("theme %q: %w", name, err)
+	}
+	return t, nil
+}
+
+func resolve(name string, userThemes map[string]yaml.Node, visited []string) (*Theme, error) {
+	node, ok := userThemes[name]
+	if !ok {
+		builtin, ok := builtinThemes[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown theme %q", name)
+		}
+		return builtin.copy(), nil
+	}
+	if slices.Contains(visited, name) {
+		return nil, fmt.Errorf("theme %q is based on itself", name)
+	}
+
+	var header struct {
+		Base string `yaml:"base"`
+	}
+	if err := node.Decode(&header); err != nil {
+		return nil, fmt.Errorf("theme %q: %w", name, err)
+	}
+
+	var (
+		base *Theme
+		err  error
+	)
+	switch header.Base {
+	case "":
+		base = builtinThemes[DefaultName].copy()
+	case name:
+		// theme overriding a built-in theme of the same name
+		builtin, ok := builtinThemes[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown theme %q", name)
+		}
+		base = builtin.copy()
+	default:
+		base, err = resolve(header.Base, userThemes, append(visited, name))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := node.Decode(base); err != nil {
+		return nil, fmt.Errorf("theme %q: %w", name, err)
+	}
+	return base, nil
+}
+
+func (t *Theme) copy() *Theme {
+	c := *t
+	c.SizeRules = slices.Clone(t.SizeRules)
+	c.AgeRules = slices.Clone(t.AgeRules)
+	return &c
+}
+
+// compile validates colours and parses thresholds of the rules
+func (t *Theme) compile() error {
+	colors := []string{
+		t.Header.TextColor, t.Header.BackgroundColor,
+		t.Footer.TextColor, t.Footer.BackgroundColor, t.Footer.NumberColor,
+		t.SelectedRow.TextColor, t.SelectedRow.BackgroundColor,
+		t.MarkedRow.TextColor, t.MarkedRow.BackgroundColor,
+		t.IgnoredRow.TextColor, t.IgnoredRow.BackgroundColor,
+		t.ResultRow.NumberColor, t.ResultRow.DirectoryColor, t.ResultRow.BarColor, t.ResultRow.FlagColor,
+		t.Modal.TextColor, t.Modal.BackgroundColor, t.Modal.BorderColor, t.Modal.TitleColor,
+	}
+	for _, color := range colors {
+		if err := validateColor(color); err != nil {
+			return err
+		}
+	}
+
+	t.sizeRules = make([]sizeRule, 0, len(t.SizeRules))
+	for _, rule := range t.SizeRules {
+		size, err := common.ParseSize(rule.MinSize)
+		if err != nil {
+			return fmt.Errorf("size rule: %w", err)
+		}
+		if err := validateRuleColor(rule.Color); err != nil {
+			return fmt.Errorf("size rule %q: %w", rule.MinSize, err)
+		}
+		t.sizeRules = append(t.sizeRules, sizeRule{size: size, color: rule.Color})
+	}
+	sort.SliceStable(t.sizeRules, func(i, j int) bool {
+		return t.sizeRules[i].size > t.sizeRules[j].size
+	})
+
+	t.ageRules = make([]ageRule, 0, len(t.AgeRules))
+	for _, rule := range t.AgeRules {
+		age, err := timefilter.ParseDuration(rule.MinAge)
+		if err != nil {
+			return fmt.Errorf("age rule: %w", err)
+		}
+		if err := validateRuleColor(rule.Color); err != nil {
+			return fmt.Errorf("age rule %q: %w", rule.MinAge, err)
+		}
+		t.ageRules = append(t.ageRules, ageRule{age: age, color: rule.Color})
+	}
+	sort.SliceStable(t.ageRules, func(i, j int) bool {
+		return t.ageRules[i].age > t.ageRules[j].age
+	})
+	return nil
+}
+
+// RuleColor returns colour of the item with given size and mtime,
+// empty string is returned when no rule matches.
+// Size rules take precedence over age rules, the rule with the highest threshold wins.
+func (t *Theme) RuleColor(size int64, mtime, now time.Time) string {
+	for _, rule := range t.sizeRules {
+		if size >= rule.size {
+			return rule.color
+		}
+	}
+	if mtime.IsZero() {
+		return ""
+	}
+	for _, rule := range t.ageRules {
+		if now.Sub(mtime) >= rule.age {
+			return rule.color
+		}
+	}
+	return ""
+}
+
+// HasRules returns true if the theme colours rows by size or age
+func (t *Theme) HasRules() bool {
+	return len(t.sizeRules) > 0 || len(t.ageRules) > 0
+}
+
+// IsSet returns true if the colour is set and is not the terminal's default colour
+func IsSet(color string) bool {
+	return color != "" && color != "default" && color != "-"
+}
+
+func validateColor(color string) error {
+	if !IsSet(color) || tcell.GetColor(strings.ToLower(color)) != tcell.ColorDefault {
+		return nil
+	}
+	return fmt.Errorf("unknown color %q", color)
+}
+
+func validateRuleColor(color string) error {
+	if !IsSet(color) {
+		return fmt.Errorf("missing color")
+	}
+	return validateColor(color)
+}
@@ -0,0 +1,142 @@
+package theme
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func parseThemes(t *testing.T, data string) map[string]yaml.Node {
+	t.Helper()
+
+	var themes map[string]yaml.Node
+	assert.Nil(t, yaml.Unmarshal([]byte(data), &themes))
+	return themes
+}
+
+func TestBuiltinThemes(t *testing.T) {
+	assert.Equal(t, []string{"default", "gruvbox", "light", "nord"}, Names())
+
+	for _, name := range Names() {
+		theme, err := Get(name, nil)
+		assert.Nil(t, err, name)
+		assert.NotEmpty(t, theme.Header.BackgroundColor, name)
+	}
+
+	theme, err := Get("", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "#2479D0", theme.Header.BackgroundColor)
+	assert.False(t, theme.HasRules())
+}
+
+func TestUnknownTheme(t *testing.T) {
+	_, err := Get("solarized", nil)
+	assert.ErrorContains(t, err, `unknown theme "solarized"`)
+}
+
+func TestRuleColor(t *testing.T) {
+	theme, err := Get("gruvbox", nil)
+	assert.Nil(t, err)
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "#fb4934", theme.RuleColor(20<<30, now, now))
+	assert.Equal(t, "#fabd2f", theme.RuleColor(2<<30, now.AddDate(-2, 0, 0), now))
+	assert.Equal(t, "#928374", theme.RuleColor(1<<20, now.AddDate(-2, 0, 0), now))
+	assert.Equal(t, "", theme.RuleColor(1<<20, now.AddDate(0, -1, 0), now))
+	assert.Equal(t, "", theme.RuleColor(1<<20, time.Time{}, now))
+}
+
+func TestUserTheme(t *testing.T) {
+	themes := parseThemes(t, `
+mine:
+  base: nord
+  header:
+    background-color: red
+  size-rules:
+    - min-size: 100M
+      color: yellow
+    - min-size: 5G
+      color: "#ff0000"
+  age-rules: []
+`)
+
+	theme, err := Get("mine", themes)
+	assert.Nil(t, err)
+	assert.Equal(t, "red", theme.Header.BackgroundColor)
+	assert.Equal(t, "#2e3440", theme.Header.TextColor)
+	assert.Equal(t, "#81a1c1", theme.ResultRow.DirectoryColor)
+
+	now := time.Now()
+	assert.Equal(t, "#ff0000", theme.RuleColor(10<<30, now, now))
+	assert.Equal(t, "yellow", theme.RuleColor(200<<20, now, now))
+	assert.Equal(t, "", theme.RuleColor(1<<20, now.AddDate(-5, 0, 0), now))
+
+	// built-in theme is not changed by the user theme
+	nord, err := Get("nord", nil)
+	assert.Nil(t, err)
+	assert.Len(t, nord.SizeRules, 2)
+	assert.Equal(t, "#88c0d0", nord.Header.BackgroundColor)
+}
+
+func TestUserThemeOverridingBuiltin(t *testing.T) {
+	themes := parseThemes(t, `
+light:
+  base: light
+  marked-row:
+    background-color: orange
+default:
+  age-rules:
+    - min-age: 6mo
+      color: gray
+`)
+
+	theme, err := Get("light", themes)
+	assert.Nil(t, err)
+	assert.Equal(t, "orange", theme.MarkedRow.BackgroundColor)
+	assert.Equal(t, "#1f5fa8", theme.Header.BackgroundColor)
+
+	theme, err = Get("", themes)
+	assert.Nil(t, err)
+	assert.Equal(t, "#2479D0", theme.Header.BackgroundColor)
+	assert.Equal(t, "gray", theme.RuleColor(0, time.Now().AddDate(-1, 0, 0), time.Now()))
+}
+
+func TestInvalidUserThemes(t *testing.T) {
+	themes := parseThemes(t, `
+loop-a:
+  base: loop-b
+loop-b:
+  base: loop-a
+bad-base:
+  base: solarized
+bad-color:
+  header:
+    text-color: not-a-color
+bad-size:
+  size-rules:
+    - min-size: 10X
+      color: red
+bad-age:
+  age-rules:
+    - min-age: old
+      color: red
+no-color:
+  size-rules:
+    - min-size: 1G
+`)
+
+	_, err := Get("loop-a", themes)
+	assert.ErrorContains(t, err, `theme "loop-a" is based on itself`)
+	_, err = Get("bad-base", themes)
+	assert.ErrorContains(t, err, `unknown theme "solarized"`)
+	_, err = Get("bad-color", themes)
+	assert.ErrorContains(t, err, `theme "bad-color": unknown color "not-a-color"`)
+	_, err = Get("bad-size", themes)
+	assert.ErrorContains(t, err, "invalid size unit: 10X")
+	_, err = Get("bad-age", themes)
+	assert.ErrorContains(t, err, `invalid duration format "old"`)
+	_, err = Get("no-color", themes)
+	assert.ErrorContains(t, err, `size rule "1G": missing color`)
+}
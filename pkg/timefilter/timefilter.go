@@ -53,7 +53,7 @@ func NewTimeFilter(since, until, maxAge, minAge string, now time.Time, loc *time
 
 	// Parse max-age (convert to since)
 	if maxAge != "" {
-		duration, err := parseDuration(maxAge)
+		duration, err := ParseDuration(maxAge)
 		if err != nil {
 			return nil, fmt.Errorf("invalid --max-age value: %w", err)
 		}
@@ -63,7 +63,7 @@ func NewTimeFilter(since, until, maxAge, minAge string, now time.Time, loc *time
 
 	// Parse min-age (convert to until)
 	if minAge != "" {
-		duration, err := parseDuration(minAge)
+		duration, err := ParseDuration(minAge)
 		if err != nil {
 			return nil, fmt.Errorf("invalid --min-age value: %w", err)
 		}
@@ -163,10 +163,10 @@ func includeByTimeBound(mtime time.Time, tb TimeBound, loc *time.Location, isUnt
 	return true
 }
 
-// parseDuration parses a duration string with support for extended units
+// ParseDuration parses a duration string with support for extended units
 // Supports: s, m, h, d (=24h), w (=7d), mo (=30d), y (=365d)
 // Examples: "90m", "2h30m", "7d", "6w", "1y2mo"
-func parseDuration(input string) (time.Duration, error) {
+func ParseDuration(input string) (time.Duration, error) {
 	if input == "" {
 		return 0, fmt.Errorf("empty duration")
 	}
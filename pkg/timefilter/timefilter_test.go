@@ -341,7 +341,7 @@ func TestParseDuration(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := parseDuration(tt.input)
+			result, err := ParseDuration(tt.input)
 
 			if tt.expectError {
 				if err == nil {
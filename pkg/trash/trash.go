@@ -0,0 +1,277 @@
+// Package trash moves files to the trash following the freedesktop.org Trash specification
+// (https://specifications.freedesktop.org/trash-spec/latest/)
+package trash
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	infoExt    = ".trashinfo"
+	infoHeader = "[Trash Info]"
+	dateFormat = "2006-01-02T15:04:05"
+	// gduKey marks items moved to the trash by gdu
+	gduKey = "X-Gdu"
+)
+
+// Trash is a trash directory containing "files" and "info" subdirectories
+type Trash struct {
+	// Dir is path of the trash directory
+	Dir string
+	// TopDir is the top directory of the mount point for per-mount trash directories,
+	// original paths of the trashed items are stored relative to it.
+	// It is empty for the home trash where absolute paths are stored.
+	TopDir string
+}
+
+// Item is an item moved to the trash by gdu
+type Item struct {
+	// Name is name of the item in the trash
+	Name string
+	// Path is the original path of the item
+	Path         string
+	DeletionDate time.Time
+	Size         int64
+	Trash        *Trash
+}
+
+// HomeTrash returns the trash in $XDG_DATA_HOME/Trash
+func HomeTrash() (*Trash, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return &Trash{Dir: filepath.Join(dataHome, "Trash")}, nil
+}
+
+// Put moves the item with the given path to the trash
+func Put(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	trash, err := ForPath(path)
+	if err != nil {
+		return err
+	}
+	return trash.Put(path)
+}
+
+func (t *Trash) filesDir() string {
+	return filepath.Join(t.Dir, "files")
+}
+
+func (t *Trash) infoDir() string {
+	return filepath.Join(t.Dir, "info")
+}
+
+func (t *Trash) init() error {
+	for _, dir := range []string{t.filesDir(), t.infoDir()} {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Put moves the item with the given absolute path to the trash
+func (t *Trash) Put(path string) error {
+	if err := t.init(); err != nil {
+		return err
+	}
+
+	infoPath := path
+	if t.TopDir != "" {
+		rel, err := filepath.Rel(t.TopDir, path)
+		if err != nil {
+			return err
+		}
+		infoPath = rel
+	}
+
+	info, name, err := t.createInfoFile(filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(
+		info,
+		"%s\nPath=%s\nDeletionDate=%s\n%s=true\n",
+		infoHeader,
+		(&url.URL{Path: infoPath}).EscapedPath(),
+		time.Now().Format(dateFormat),
+		gduKey,
+	)
+	if closeErr := info.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(path, filepath.Join(t.filesDir(), name))
+	}
+	if err != nil {
+		os.Remove(info.Name())
+		return err
+	}
+	return nil
+}
+
+// createInfoFile creates info file with unique name,
+// the name is used for the trashed item as well
+func (t *Trash) createInfoFile(base string) (*os.File, string, error) {
+	for i := 1; ; i++ {
+		name := base
+		if i > 1 {
+			name = base + "." + strconv.Itoa(i)
+		}
+		if _, err := os.Lstat(filepath.Join(t.filesDir(), name)); err == nil {
+			continue
+		}
+
+		info, err := os.OpenFile(
+			filepath.Join(t.infoDir(), name+infoExt),
+			os.O_WRONLY|os.O_CREATE|os.O_EXCL,
+			0o600,
+		)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		return info, name, nil
+	}
+}
+
+// List returns items moved to the trash by gdu, most recently deleted first
+func (t *Trash) List() ([]*Item, error) {
+	entries, err := os.ReadDir(t.infoDir())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*Item, 0, len(entries))
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), infoExt)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		item, err := t.readInfo(name)
+		if err != nil || item == nil {
+			continue
+		}
+		item.Size = getSize(filepath.Join(t.filesDir(), name))
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].DeletionDate.After(items[j].DeletionDate)
+	})
+	return items, nil
+}
+
+// readInfo parses info file of the item, nil is returned for items not deleted by gdu
+func (t *Trash) readInfo(name string) (*Item, error) {
+	f, err := os.Open(filepath.Join(t.infoDir(), name+infoExt))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	item := &Item{Name: name, Trash: t}
+	inSection := false
+	byGdu := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inSection = line == infoHeader
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !inSection || !ok {
+			continue
+		}
+		switch key {
+		case "Path":
+			path, err := url.PathUnescape(value)
+			if err != nil {
+				return nil, err
+			}
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(t.TopDir, path)
+			}
+			item.Path = path
+		case "DeletionDate":
+			item.DeletionDate, _ = time.ParseInLocation(dateFormat, value, time.Local)
+		case gduKey:
+			byGdu = value == "true"
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !byGdu || item.Path == "" {
+		return nil, nil
+	}
+	return item, nil
+}
+
+func getSize(path string) int64 {
+	var size int64
+	_ = filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+func (i *Item) filePath() string {
+	return filepath.Join(i.Trash.filesDir(), i.Name)
+}
+
+func (i *Item) infoPath() string {
+	return filepath.Join(i.Trash.infoDir(), i.Name+infoExt)
+}
+
+// Restore moves the item back to its original path
+func (i *Item) Restore() error {
+	if _, err := os.Lstat(i.Path); err == nil {
+		return fmt.Errorf("%s already exists", i.Path)
+	}
+	if err := os.MkdirAll(filepath.Dir(i.Path), 0o755); err != nil {
+		return err
+	}
+	if err := os.Rename(i.filePath(), i.Path); err != nil {
+		return err
+	}
+	return os.Remove(i.infoPath())
+}
+
+// Remove deletes the item from the trash permanently
+func (i *Item) Remove() error {
+	if err := os.RemoveAll(i.filePath()); err != nil {
+		return err
+	}
+	return os.Remove(i.infoPath())
+}
@@ -0,0 +1,15 @@
+//go:build windows || plan9
+
+package trash
+
+import "errors"
+
+// ForPath returns the trash for the item with the given absolute path
+func ForPath(path string) (*Trash, error) {
+	return nil, errors.New("trash is not supported on this platform")
+}
+
+// ForMount returns the trash in the top directory of the given mount point
+func ForMount(mountPoint string) (*Trash, error) {
+	return nil, errors.New("trash is not supported on this platform")
+}
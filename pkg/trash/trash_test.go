@@ -0,0 +1,163 @@
+//go:build !windows && !plan9
+
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createTrash(t *testing.T) (*Trash, string) {
+	t.Helper()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	trash, err := HomeTrash()
+	assert.Nil(t, err)
+	return trash, t.TempDir()
+}
+
+func TestPutAndRestore(t *testing.T) {
+	trash, dir := createTrash(t)
+
+	path := filepath.Join(dir, "file with space")
+	assert.Nil(t, os.WriteFile(path, []byte("hello"), 0o600))
+
+	assert.Nil(t, Put(path))
+	assert.NoFileExists(t, path)
+
+	info, err := os.ReadFile(filepath.Join(trash.Dir, "info", "file with space.trashinfo"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(info), "[Trash Info]\nPath="+filepath.Dir(path)+"/file%20with%20space\n")
+	assert.Contains(t, string(info), "DeletionDate=")
+
+	items, err := trash.List()
+	assert.Nil(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, path, items[0].Path)
+	assert.Equal(t, int64(5), items[0].Size)
+
+	assert.Nil(t, items[0].Restore())
+	assert.FileExists(t, path)
+	items, err = trash.List()
+	assert.Nil(t, err)
+	assert.Empty(t, items)
+}
+
+func TestPutSameName(t *testing.T) {
+	trash, dir := createTrash(t)
+
+	nested := filepath.Join(dir, "nested", "dir")
+	assert.Nil(t, os.MkdirAll(nested, 0o755))
+	assert.Nil(t, os.WriteFile(filepath.Join(nested, "file"), []byte("abc"), 0o600))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "dir"), []byte("abc"), 0o600))
+
+	assert.Nil(t, trash.Put(nested))
+	assert.Nil(t, trash.Put(filepath.Join(dir, "dir")))
+
+	items, err := trash.List()
+	assert.Nil(t, err)
+	assert.Len(t, items, 2)
+	names := []string{items[0].Name, items[1].Name}
+	assert.ElementsMatch(t, []string{"dir", "dir.2"}, names)
+
+	for _, item := range items {
+		assert.Nil(t, item.Remove())
+	}
+	items, err = trash.List()
+	assert.Nil(t, err)
+	assert.Empty(t, items)
+	assert.NoDirExists(t, filepath.Join(trash.Dir, "files", "dir"))
+}
+
+func TestListOnlyGduItems(t *testing.T) {
+	trash, _ := createTrash(t)
+
+	items, err := trash.List()
+	assert.Nil(t, err)
+	assert.Empty(t, items)
+
+	assert.Nil(t, trash.init())
+	assert.Nil(t, os.WriteFile(
+		filepath.Join(trash.Dir, "info", "other.trashinfo"),
+		[]byte("[Trash Info]\nPath=/tmp/other\nDeletionDate=2024-01-01T10:00:00\n"),
+		0o600,
+	))
+	items, err = trash.List()
+	assert.Nil(t, err)
+	assert.Empty(t, items)
+}
+
+func TestRestoreExisting(t *testing.T) {
+	trash, dir := createTrash(t)
+
+	path := filepath.Join(dir, "file")
+	assert.Nil(t, os.WriteFile(path, []byte("abc"), 0o600))
+	assert.Nil(t, trash.Put(path))
+	assert.Nil(t, os.WriteFile(path, []byte("new"), 0o600))
+
+	items, err := trash.List()
+	assert.Nil(t, err)
+	assert.ErrorContains(t, items[0].Restore(), "already exists")
+}
+
+func TestPutNonExisting(t *testing.T) {
+	trash, dir := createTrash(t)
+
+	assert.NotNil(t, trash.Put(filepath.Join(dir, "xxx")))
+	entries, err := os.ReadDir(filepath.Join(trash.Dir, "info"))
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestMountTrash(t *testing.T) {
+	topDir := t.TempDir()
+	path := filepath.Join(topDir, "sub", "file")
+	assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	assert.Nil(t, os.WriteFile(path, []byte("abc"), 0o600))
+
+	trash := mountTrash(topDir)
+	assert.Equal(t, ".Trash-"+strconv.Itoa(os.Getuid()), filepath.Base(trash.Dir))
+
+	// paths are stored relative to the top directory
+	assert.Nil(t, trash.Put(path))
+	info, err := os.ReadFile(filepath.Join(trash.Dir, "info", "file.trashinfo"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(info), "Path=sub/file\n")
+
+	items, err := trash.List()
+	assert.Nil(t, err)
+	assert.Equal(t, path, items[0].Path)
+
+	// shared .Trash directory with sticky bit
+	assert.Nil(t, os.Mkdir(filepath.Join(topDir, ".Trash"), 0o777))
+	assert.Nil(t, os.Chmod(filepath.Join(topDir, ".Trash"), 0o777|os.ModeSticky))
+	trash = mountTrash(topDir)
+	assert.Equal(t, filepath.Join(topDir, ".Trash", strconv.Itoa(os.Getuid())), trash.Dir)
+}
+
+func TestForPathInHome(t *testing.T) {
+	trash, dir := createTrash(t)
+
+	found, err := ForPath(filepath.Join(dir, "file"))
+	assert.Nil(t, err)
+	assert.Equal(t, trash.Dir, found.Dir)
+}
+
+func TestGetTopDir(t *testing.T) {
+	dir := t.TempDir()
+	dev, err := getDevice(dir)
+	assert.Nil(t, err)
+
+	topDir, err := getTopDir(dir, dev)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(dir, topDir))
+	if parent := filepath.Dir(topDir); parent != topDir {
+		parentDev, err := getDevice(parent)
+		assert.Nil(t, err)
+		assert.NotEqual(t, dev, parentDev)
+	}
+}
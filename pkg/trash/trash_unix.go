@@ -0,0 +1,98 @@
+//go:build !windows && !plan9
+
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// ForPath returns the trash for the item with the given absolute path,
+// the trash directory is created when the first item is put into it.
+// The home trash is used for items on the same device,
+// otherwise the trash in the top directory of the mount point is used.
+func ForPath(path string) (*Trash, error) {
+	home, err := HomeTrash()
+	if err != nil {
+		return nil, err
+	}
+	dev, err := getDevice(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	homeDev, err := getDevice(getExistingDir(home.Dir))
+	if err != nil {
+		return nil, err
+	}
+	if dev == homeDev {
+		return home, nil
+	}
+
+	topDir, err := getTopDir(filepath.Dir(path), dev)
+	if err != nil {
+		return nil, err
+	}
+	return mountTrash(topDir), nil
+}
+
+// ForMount returns the trash in the top directory of the given mount point
+func ForMount(mountPoint string) (*Trash, error) {
+	return mountTrash(mountPoint), nil
+}
+
+// mountTrash returns $topdir/.Trash/$uid if $topdir/.Trash is a sticky directory,
+// $topdir/.Trash-$uid otherwise
+func mountTrash(topDir string) *Trash {
+	uid := strconv.Itoa(os.Getuid())
+
+	shared := filepath.Join(topDir, ".Trash")
+	if stat, err := os.Lstat(shared); err == nil &&
+		stat.IsDir() && stat.Mode()&os.ModeSticky != 0 {
+		return &Trash{Dir: filepath.Join(shared, uid), TopDir: topDir}
+	}
+	return &Trash{Dir: filepath.Join(topDir, ".Trash-"+uid), TopDir: topDir}
+}
+
+// getExistingDir returns the nearest existing directory on the path,
+// the trash directory doesn't have to be created yet
+func getExistingDir(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// getTopDir returns the top directory of the mount point containing path
+func getTopDir(path string, dev uint64) (string, error) {
+	for {
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path, nil
+		}
+		parentDev, err := getDevice(parent)
+		if err != nil {
+			return "", err
+		}
+		if parentDev != dev {
+			return path, nil
+		}
+		path = parent
+	}
+}
+
+func getDevice(path string) (uint64, error) {
+	var stat syscall.Stat_t
+	if err := syscall.Stat(path, &stat); err != nil {
+		return 0, err
+	}
+	// nolint: unconvert // Why: type of Dev differs between platforms
+	return uint64(stat.Dev), nil
+}
@@ -0,0 +1,54 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// AtomicFile is file which appears at its path only after it's completely written.
+// Data are written into temporary file in the same directory
+// which is renamed to the target path on Close.
+type AtomicFile struct {
+	*os.File
+	path string
+	done bool
+}
+
+// CreateAtomicFile creates temporary file for atomic writing to given path
+func CreateAtomicFile(path string) (*AtomicFile, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	return &AtomicFile{File: f, path: path}, nil
+}
+
+// Close closes the temporary file and renames it to the target path
+func (f *AtomicFile) Close() error {
+	f.done = true
+	if err := f.File.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	// the file is usually read by other user (e.g. node_exporter)
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), f.path); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// Discard closes and removes the temporary file leaving the target path untouched.
+// It does nothing when the file has been already closed or discarded.
+func (f *AtomicFile) Discard() error {
+	if f.done {
+		return nil
+	}
+	f.done = true
+	_ = f.File.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,85 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+var csvHeader = []string{
+	"path", "type", "asize", "usage", "items", "mtime", "flag", "depth", "inode",
+}
+
+// WriteCSV writes one row per item of the given dir tree into writer.
+// Rows are streamed in depth-first order, parent directories first.
+// Items deeper than maxDepth are skipped (0 means no limit),
+// files are skipped if dirsOnly is set.
+func WriteCSV(writer io.Writer, dir fs.Item, separator rune, maxDepth int, dirsOnly bool) error {
+	w := csv.NewWriter(writer)
+	w.Comma = separator
+
+	if err := w.Write(csvHeader); err != nil {
+		return err
+	}
+	if err := writeCSVItem(w, dir, 0, maxDepth, dirsOnly); err != nil {
+		return err
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func writeCSVItem(w *csv.Writer, item fs.Item, depth, maxDepth int, dirsOnly bool) error {
+	if maxDepth > 0 && depth > maxDepth {
+		return nil
+	}
+	if dirsOnly && !item.IsDir() {
+		return nil
+	}
+
+	if err := w.Write(csvRow(item, depth)); err != nil {
+		return err
+	}
+
+	if !item.IsDir() {
+		return nil
+	}
+
+	files := item.GetFiles()
+	sort.Sort(sort.Reverse(files))
+	for _, file := range files {
+		if err := writeCSVItem(w, file, depth+1, maxDepth, dirsOnly); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func csvRow(item fs.Item, depth int) []string {
+	var mtime, inode, flag string
+	if !item.GetMtime().IsZero() {
+		mtime = item.GetMtime().Format(time.RFC3339)
+	}
+	if item.GetMultiLinkedInode() > 0 {
+		inode = strconv.FormatUint(item.GetMultiLinkedInode(), 10)
+	}
+	if f := item.GetFlag(); f != 0 && f != ' ' {
+		flag = string(f)
+	}
+
+	return []string{
+		item.GetPath(),
+		item.GetType(),
+		strconv.FormatInt(item.GetSize(), 10),
+		strconv.FormatInt(item.GetUsage(), 10),
+		strconv.Itoa(item.GetItemCount()),
+		mtime,
+		flag,
+		strconv.Itoa(depth),
+		inode,
+	}
+}
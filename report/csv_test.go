@@ -0,0 +1,111 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportCSV(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	output := bytes.NewBuffer(make([]byte, 10))
+	reportOutput := bytes.NewBuffer(nil)
+
+	ui := CreateExportUI(output, reportOutput, false, false, false, false)
+	err := ui.SetFormat("csv")
+	assert.Nil(t, err)
+	ui.SetIgnoreDirPaths([]string{"/xxx"})
+	err = ui.AnalyzePath("test_dir", nil)
+	assert.Nil(t, err)
+
+	rows, err := csv.NewReader(reportOutput).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, csvHeader, rows[0])
+	assert.Len(t, rows, 6)
+	assert.Equal(t, "test_dir", rows[1][0])
+	assert.Equal(t, "Directory", rows[1][1])
+	assert.Equal(t, "0", rows[1][7])
+	assert.Equal(t, "test_dir/nested", rows[2][0])
+	assert.Equal(t, "1", rows[2][7])
+}
+
+func TestExportTSVWithMaxDepthAndDirsOnly(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	output := bytes.NewBuffer(make([]byte, 10))
+	reportOutput := bytes.NewBuffer(nil)
+
+	ui := CreateExportUI(output, reportOutput, false, false, false, false)
+	err := ui.SetFormat("TSV")
+	assert.Nil(t, err)
+	ui.SetMaxDepth(1)
+	ui.SetDirsOnly(true)
+	err = ui.AnalyzePath("test_dir", nil)
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(reportOutput.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, strings.Join(csvHeader, "\t"), lines[0])
+	assert.True(t, strings.HasPrefix(lines[2], "test_dir/nested\tDirectory\t"))
+}
+
+func TestConvertAnalysisToCSV(t *testing.T) {
+	input, err := os.Open("../internal/testdata/test.json")
+	assert.Nil(t, err)
+	defer input.Close()
+
+	output := bytes.NewBuffer(make([]byte, 10))
+	reportOutput := bytes.NewBuffer(nil)
+
+	ui := CreateExportUI(output, reportOutput, false, false, false, false)
+	err = ui.SetFormat("csv")
+	assert.Nil(t, err)
+	err = ui.ReadAnalysis(input)
+	assert.Nil(t, err)
+
+	rows, err := csv.NewReader(reportOutput).ReadAll()
+	assert.Nil(t, err)
+	assert.Len(t, rows, 7)
+	assert.Equal(t, []string{"/home/gdu/app/app_test.go", "File", "4974", "8192", "1", "", "", "2", ""}, rows[3])
+}
+
+func TestSetWrongFormat(t *testing.T) {
+	ui := CreateExportUI(&bytes.Buffer{}, &bytes.Buffer{}, false, false, false, false)
+	err := ui.SetFormat("xxx")
+	assert.ErrorContains(t, err, "unknown output format")
+}
+
+func TestCSVRow(t *testing.T) {
+	dir := &analyze.Dir{
+		File: &analyze.File{
+			Name: "dir",
+		},
+		BasePath: "/",
+	}
+	file := &analyze.File{
+		Name:   "file, with comma",
+		Size:   10,
+		Usage:  4096,
+		Mli:    123,
+		Flag:   'H',
+		Mtime:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Parent: dir,
+	}
+	dir.Files = fs.Files{file}
+
+	var buff bytes.Buffer
+	err := WriteCSV(&buff, dir, ',', 0, false)
+	assert.Nil(t, err)
+	assert.Contains(t, buff.String(), `"/dir/file, with comma",File,10,4096,1,2024-01-02T03:04:05Z,H,1,123`)
+}
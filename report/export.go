@@ -1,23 +1,34 @@
 package report
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"math"
 	"os"
+	"path/filepath"
 	"sort"
-	"strconv"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/dundee/gdu/v5/build"
 	"github.com/dundee/gdu/v5/internal/common"
 	"github.com/dundee/gdu/v5/pkg/analyze"
 	"github.com/dundee/gdu/v5/pkg/device"
 	"github.com/dundee/gdu/v5/pkg/fs"
 	"github.com/fatih/color"
+	log "github.com/sirupsen/logrus"
+)
+
+// Supported export formats
+const (
+	FormatJSON       = "json"
+	FormatCSV        = "csv"
+	FormatTSV        = "tsv"
+	FormatHTML       = "html"
+	FormatPrometheus = "prometheus"
+	FormatFolded     = "folded"
+	FormatSQLite     = "sqlite"
 )
 
 // UI struct
@@ -25,9 +36,15 @@ type UI struct {
 	*common.UI
 	output       io.Writer
 	exportOutput io.Writer
+	getter       device.DevicesInfoGetter
+	metadata     *Metadata
 	red          *color.Color
 	orange       *color.Color
 	writtenChan  chan struct{}
+	format       string
+	maxDepth     int
+	minSize      int64
+	dirsOnly     bool
 }
 
 // CreateExportUI creates UI for stdout
@@ -49,6 +66,7 @@ func CreateExportUI(
 		output:       output,
 		exportOutput: exportOutput,
 		writtenChan:  make(chan struct{}),
+		format:       FormatJSON,
 	}
 	ui.red = color.New(color.FgRed).Add(color.Bold)
 	ui.orange = color.New(color.FgYellow).Add(color.Bold)
@@ -60,6 +78,64 @@ func CreateExportUI(
 	return ui
 }
 
+// SetFormat sets the format of exported data
+func (ui *UI) SetFormat(format string) error {
+	switch strings.ToLower(format) {
+	case "", FormatJSON:
+		ui.format = FormatJSON
+	case FormatCSV:
+		ui.format = FormatCSV
+	case FormatTSV:
+		ui.format = FormatTSV
+	case FormatHTML:
+		ui.format = FormatHTML
+	case FormatPrometheus:
+		ui.format = FormatPrometheus
+	case FormatFolded:
+		ui.format = FormatFolded
+	case FormatSQLite:
+		ui.format = FormatSQLite
+	default:
+		return fmt.Errorf("unknown output format: %s", format)
+	}
+	return nil
+}
+
+// SetMaxDepth sets maximum depth of exported items (0 means no limit)
+// It is used only by flat export formats
+func (ui *UI) SetMaxDepth(depth int) {
+	ui.maxDepth = depth
+}
+
+// SetDirsOnly sets whether only directories should be exported
+// It is used only by flat export formats
+func (ui *UI) SetDirsOnly(value bool) {
+	ui.dirsOnly = value
+}
+
+// SetMinSize sets the size threshold under which items are merged together
+// It is used only by the HTML and folded formats
+func (ui *UI) SetMinSize(size int64) {
+	ui.minSize = size
+}
+
+// SetDevicesInfoGetter sets getter used for finding the device of the analyzed directory
+// It is used only by the Prometheus format
+func (ui *UI) SetDevicesInfoGetter(getter device.DevicesInfoGetter) {
+	ui.getter = getter
+}
+
+// SetMetadata sets metadata of the analysis stored together with exported data
+// It is used only by the JSON and SQLite formats
+func (ui *UI) SetMetadata(meta *Metadata) {
+	ui.metadata = meta
+}
+
+// SetShowApparentSize sets whether apparent size should be used instead of disk usage
+func (ui *UI) SetShowApparentSize(value bool) {
+	ui.ShowApparentSize = value
+}
+
 // StartUILoop stub
 func (ui *UI) StartUILoop() error {
 	return nil
@@ -74,9 +150,16 @@ func (ui *UI) ListDevices(getter device.DevicesInfoGetter) error {
 	return errors.New("exporting devices list is not supported")
 }
 
-// ReadAnalysis reads analysis report from JSON file
+// ReadAnalysis reads analysis report from JSON file and exports it in the selected format
 func (ui *UI) ReadAnalysis(input io.Reader) error {
-	return errors.New("reading analysis is not possible while exporting")
+	dir, meta, err := ReadAnalysisWithMetadata(input)
+	if err != nil {
+		return err
+	}
+	dir.UpdateStats(make(fs.HardLinkedItems, 10))
+	ui.metadata = meta
+
+	return ui.ShowAnalysis(dir)
 }
 
 // ReadFromStorage reads analysis data from persistent key-value storage
@@ -85,11 +168,19 @@ func (ui *UI) ReadFromStorage(storagePath, path string) error {
 	closeFn := storage.Open()
 	defer closeFn()
 
+	if err := storage.SelectScan(path); err != nil {
+		return err
+	}
 	dir, err := storage.GetDirForPath(path)
 	if err != nil {
 		return err
 	}
 
+	return ui.ShowAnalysis(dir)
+}
+
+// ShowAnalysis exports already loaded analysis
+func (ui *UI) ShowAnalysis(dir fs.Item) error {
 	var waitWritten sync.WaitGroup
 	if ui.ShowProgress {
 		waitWritten.Add(1)
@@ -118,6 +209,8 @@ func (ui *UI) AnalyzePath(path string, _ fs.Item) error {
 		}()
 	}
 
+	start := time.Now()
+
 	wait.Add(1)
 	go func() {
 		defer wait.Done()
@@ -127,34 +220,40 @@ func (ui *UI) AnalyzePath(path string, _ fs.Item) error {
 
 	wait.Wait()
 
+	if ui.metadata != nil {
+		ui.metadata.Finish(dir, start, ui.getDevice(dir.GetPath()))
+	}
+
 	return ui.exportDir(dir, &waitWritten)
 }
 
 func (ui *UI) exportDir(dir fs.Item, waitWritten *sync.WaitGroup) error {
-	sort.Sort(sort.Reverse(dir.GetFiles()))
-
-	var (
-		buff bytes.Buffer
-		err  error
-	)
-
-	buff.Write([]byte(`[1,2,{"progname":"gdu","progver":"`))
-	buff.Write([]byte(build.Version))
-	buff.Write([]byte(`","timestamp":`))
-	buff.Write([]byte(strconv.FormatInt(time.Now().Unix(), 10)))
-	buff.Write([]byte("},\n"))
-
-	if err := dir.EncodeJSON(&buff, true); err != nil {
-		return err
-	}
-	if _, err = buff.Write([]byte("]\n")); err != nil {
-		return err
+	var err error
+
+	switch ui.format {
+	case FormatCSV:
+		err = WriteCSV(ui.exportOutput, dir, ',', ui.maxDepth, ui.dirsOnly)
+	case FormatTSV:
+		err = WriteCSV(ui.exportOutput, dir, '\t', ui.maxDepth, ui.dirsOnly)
+	case FormatHTML:
+		err = WriteHTML(ui.exportOutput, dir, ui.minSize, ui.ShowApparentSize, ui.UseSIPrefix)
+	case FormatPrometheus:
+		err = WritePrometheus(ui.exportOutput, dir, ui.maxDepth, ui.getDevice(dir.GetPath()), time.Now())
+	case FormatFolded:
+		err = WriteFolded(ui.exportOutput, dir, ui.minSize, ui.ShowApparentSize)
+	case FormatSQLite:
+		err = ui.writeSQLite(dir)
+	default:
+		err = ui.writeJSON(dir)
 	}
-	if _, err = buff.WriteTo(ui.exportOutput); err != nil {
+	if err != nil {
+		if f, ok := ui.exportOutput.(*AtomicFile); ok {
+			_ = f.Discard()
+		}
 		return err
 	}
 
-	if f, ok := ui.exportOutput.(*os.File); ok {
+	if f, ok := ui.exportOutput.(io.Closer); ok {
 		err = f.Close()
 		if err != nil {
 			return err
@@ -169,6 +268,49 @@ func (ui *UI) exportDir(dir fs.Item, waitWritten *sync.WaitGroup) error {
 	return nil
 }
 
+// getDevice returns device containing given path or nil if it can't be found
+func (ui *UI) getDevice(path string) *device.Device {
+	if ui.getter == nil {
+		return nil
+	}
+	devices, err := ui.getter.GetDevicesInfo()
+	if err != nil {
+		log.Printf("Error reading devices info: %s", err)
+		return nil
+	}
+	return device.GetDeviceForPath(path, devices)
+}
+
+// writeSQLite writes the database into temporary file first
+// as SQLite can't write into arbitrary writer
+func (ui *UI) writeSQLite(dir fs.Item) error {
+	tmpDir, err := os.MkdirTemp("", "gdu-sqlite")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "export.sqlite")
+	if err := WriteSQLite(path, dir, ui.metadata); err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(ui.exportOutput, f)
+	return err
+}
+
+func (ui *UI) writeJSON(dir fs.Item) error {
+	sort.Sort(sort.Reverse(dir.GetFiles()))
+
+	return WriteJSON(ui.exportOutput, dir, ui.metadata)
+}
+
 func (ui *UI) updateProgress() {
 	waitingForWrite := false
 
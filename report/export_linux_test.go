@@ -51,5 +51,5 @@ func TestReadFromStorageWithErr(t *testing.T) {
 	ui.SetIgnoreDirPaths([]string{"/xxx"})
 	err := ui.ReadFromStorage(storagePath, "test_dir")
 
-	assert.ErrorContains(t, err, "Key not found")
+	assert.ErrorContains(t, err, "no scan of")
 }
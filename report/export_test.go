@@ -61,13 +61,28 @@ func TestShowDevices(t *testing.T) {
 }
 
 func TestReadAnalysisWhileExporting(t *testing.T) {
+	input, err := os.Open("../internal/testdata/test.json")
+	assert.Nil(t, err)
+	defer input.Close()
+
 	output := bytes.NewBuffer(make([]byte, 10))
 	reportOutput := bytes.NewBuffer(make([]byte, 10))
 
 	ui := CreateExportUI(output, reportOutput, false, true, false, false)
-	err := ui.ReadAnalysis(output)
+	err = ui.ReadAnalysis(input)
+
+	assert.Nil(t, err)
+	assert.Contains(t, reportOutput.String(), `"name":"app.go"`)
+}
+
+func TestReadWrongAnalysisWhileExporting(t *testing.T) {
+	output := bytes.NewBuffer(make([]byte, 10))
+	reportOutput := bytes.NewBuffer(make([]byte, 10))
+
+	ui := CreateExportUI(output, reportOutput, false, false, false, false)
+	err := ui.ReadAnalysis(bytes.NewBufferString("{}"))
 
-	assert.Contains(t, err.Error(), "not possible while exporting")
+	assert.ErrorContains(t, err, "does not contain top level array")
 }
 
 func TestExportToFile(t *testing.T) {
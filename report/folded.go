@@ -0,0 +1,62 @@
+package report
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+var foldedFrameEscaper = strings.NewReplacer(";", "_", "\n", "_", "\r", "_")
+
+// WriteFolded writes given dir tree into writer in the folded stacks format
+// used by flamegraph.pl, speedscope and similar tools.
+// Every line contains semicolon separated path components followed by number of bytes.
+// Directories get line with their own size not covered by their children.
+// Items smaller than minSize are not written, their size is added to their parent.
+func WriteFolded(writer io.Writer, dir fs.Item, minSize int64, apparentSize bool) error {
+	w := bufio.NewWriter(writer)
+
+	value := func(item fs.Item) int64 {
+		if apparentSize {
+			return item.GetSize()
+		}
+		return item.GetUsage()
+	}
+
+	if err := writeFoldedItem(w, dir, foldedFrameEscaper.Replace(dir.GetPath()), minSize, value); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func writeFoldedItem(w *bufio.Writer, item fs.Item, stack string, minSize int64, value func(fs.Item) int64) error {
+	self := value(item)
+
+	if item.IsDir() {
+		files := item.GetFiles()
+		sort.Sort(sort.Reverse(files))
+		for _, file := range files {
+			size := value(file)
+			if size < minSize {
+				continue
+			}
+			self -= size
+
+			frame := stack + ";" + foldedFrameEscaper.Replace(file.GetName())
+			if err := writeFoldedItem(w, file, frame, minSize, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	// hard linked files can make the sum of children bigger than the directory
+	if self <= 0 {
+		return nil
+	}
+	_, err := w.WriteString(stack + " " + strconv.FormatInt(self, 10) + "\n")
+	return err
+}
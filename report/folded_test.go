@@ -0,0 +1,91 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func createFoldedTestDir() *analyze.Dir {
+	dir := &analyze.Dir{
+		File: &analyze.File{
+			Name:  "xxx",
+			Size:  8292,
+			Usage: 20480,
+		},
+		BasePath: "/srv",
+	}
+	subdir := &analyze.Dir{
+		File: &analyze.File{
+			Name:   "a;b",
+			Size:   4146,
+			Usage:  12288,
+			Parent: dir,
+		},
+	}
+	small := &analyze.File{
+		Name:   "small",
+		Size:   50,
+		Usage:  4096,
+		Parent: dir,
+	}
+	big := &analyze.File{
+		Name:   "big",
+		Size:   50,
+		Usage:  8192,
+		Parent: subdir,
+	}
+	subdir.Files = fs.Files{big}
+	dir.Files = fs.Files{subdir, small}
+	return dir
+}
+
+func TestWriteFolded(t *testing.T) {
+	buff := bytes.NewBuffer(nil)
+
+	err := WriteFolded(buff, createFoldedTestDir(), 0, false)
+	assert.Nil(t, err)
+
+	assert.Equal(t, `/srv/xxx;a_b;big 8192
+/srv/xxx;a_b 4096
+/srv/xxx;small 4096
+/srv/xxx 4096
+`, buff.String())
+}
+
+func TestWriteFoldedApparentSizeWithMinSize(t *testing.T) {
+	buff := bytes.NewBuffer(nil)
+
+	err := WriteFolded(buff, createFoldedTestDir(), 100, true)
+	assert.Nil(t, err)
+
+	// both files are under min size, so they are counted into their directories
+	assert.Equal(t, `/srv/xxx;a_b 4146
+/srv/xxx 4146
+`, buff.String())
+}
+
+func TestExportFolded(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	output := bytes.NewBuffer(make([]byte, 10))
+	reportOutput := bytes.NewBuffer(nil)
+
+	ui := CreateExportUI(output, reportOutput, false, false, false, false)
+	err := ui.SetFormat("folded")
+	assert.Nil(t, err)
+	err = ui.AnalyzePath("test_dir", nil)
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(reportOutput.String()), "\n")
+	assert.Len(t, lines, 5)
+	assert.Contains(t, lines, "test_dir;nested;subnested;file 4096")
+	assert.Contains(t, lines, "test_dir 4096")
+}
@@ -0,0 +1,99 @@
+package report
+
+import (
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/dundee/gdu/v5/build"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+//go:embed html.tmpl
+var htmlTemplate string
+
+var htmlTmpl = template.Must(template.New("html").Parse(htmlTemplate))
+
+// htmlNode is a compact representation of item embedded into the HTML report
+type htmlNode struct {
+	Name     string      `json:"n"`
+	Size     int64       `json:"s"`
+	Usage    int64       `json:"u"`
+	Items    int         `json:"i,omitempty"`
+	Mtime    int64       `json:"m,omitempty"`
+	Dir      bool        `json:"d,omitempty"`
+	Other    bool        `json:"o,omitempty"`
+	Children []*htmlNode `json:"c,omitempty"`
+}
+
+// WriteHTML writes self-contained HTML report with interactive treemap into writer.
+// Items smaller than minSize are merged into one synthetic item per directory.
+func WriteHTML(writer io.Writer, dir fs.Item, minSize int64, apparentSize, useSIPrefix bool) error {
+	data, err := json.Marshal(struct {
+		Root     *htmlNode `json:"root"`
+		Apparent bool      `json:"apparent"`
+		SI       bool      `json:"si"`
+	}{
+		Root:     createHTMLNode(dir, dir.GetPath(), minSize, apparentSize),
+		Apparent: apparentSize,
+		SI:       useSIPrefix,
+	})
+	if err != nil {
+		return err
+	}
+
+	return htmlTmpl.Execute(writer, struct {
+		Title string
+		Meta  string
+		Data  template.JS
+	}{
+		Title: dir.GetPath(),
+		Meta:  "generated " + time.Now().Format(time.RFC3339) + " by gdu " + build.Version,
+		// json.Marshal escapes <, > and & so the data can't close the script element
+		Data: template.JS(data),
+	})
+}
+
+func createHTMLNode(item fs.Item, name string, minSize int64, apparentSize bool) *htmlNode {
+	node := &htmlNode{
+		Name:  name,
+		Size:  item.GetSize(),
+		Usage: item.GetUsage(),
+		Items: item.GetItemCount(),
+		Dir:   item.IsDir(),
+	}
+	if !item.GetMtime().IsZero() {
+		node.Mtime = item.GetMtime().Unix()
+	}
+	if !item.IsDir() {
+		return node
+	}
+
+	var other *htmlNode
+	for _, child := range item.GetFiles() {
+		value := child.GetUsage()
+		if apparentSize {
+			value = child.GetSize()
+		}
+
+		if value < minSize {
+			if other == nil {
+				other = &htmlNode{Other: true}
+			}
+			other.Size += child.GetSize()
+			other.Usage += child.GetUsage()
+			other.Items += child.GetItemCount()
+			continue
+		}
+		node.Children = append(node.Children, createHTMLNode(child, child.GetName(), minSize, apparentSize))
+	}
+
+	if other != nil {
+		other.Name = "<" + strconv.Itoa(other.Items) + " smaller items>"
+		node.Children = append(node.Children, other)
+	}
+	return node
+}
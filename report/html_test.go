@@ -0,0 +1,77 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportHTML(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	output := bytes.NewBuffer(make([]byte, 10))
+	reportOutput := bytes.NewBuffer(nil)
+
+	ui := CreateExportUI(output, reportOutput, false, false, false, false)
+	err := ui.SetFormat("html")
+	assert.Nil(t, err)
+	err = ui.AnalyzePath("test_dir", nil)
+	assert.Nil(t, err)
+
+	assert.True(t, strings.HasPrefix(reportOutput.String(), "<!DOCTYPE html>"))
+	assert.Contains(t, reportOutput.String(), "<title>gdu report: test_dir</title>")
+	assert.Contains(t, reportOutput.String(), `"n":"subnested"`)
+}
+
+func TestHTMLMinSize(t *testing.T) {
+	dir := &analyze.Dir{
+		File: &analyze.File{
+			Name:  "dir",
+			Usage: 10000,
+			Size:  10000,
+		},
+		BasePath: "/",
+	}
+	big := &analyze.File{Name: "big", Usage: 8192, Size: 8000, Parent: dir}
+	small1 := &analyze.File{Name: "small1", Usage: 100, Size: 100, Parent: dir}
+	small2 := &analyze.File{Name: "small2", Usage: 200, Size: 200, Parent: dir}
+	dir.Files = fs.Files{big, small1, small2}
+
+	node := createHTMLNode(dir, dir.GetPath(), 1000, false)
+
+	assert.Equal(t, "/dir", node.Name)
+	assert.Len(t, node.Children, 2)
+	assert.Equal(t, "big", node.Children[0].Name)
+	assert.Equal(t, "<2 smaller items>", node.Children[1].Name)
+	assert.True(t, node.Children[1].Other)
+	assert.Equal(t, int64(300), node.Children[1].Usage)
+}
+
+func TestHTMLEscaping(t *testing.T) {
+	dir := &analyze.Dir{
+		File: &analyze.File{
+			Name: "</script><b>",
+		},
+		BasePath: "/",
+	}
+
+	var buff bytes.Buffer
+	err := WriteHTML(&buff, dir, 0, false, false)
+	assert.Nil(t, err)
+
+	assert.NotContains(t, buff.String(), "</script><b>")
+	assert.Contains(t, buff.String(), "&lt;/script&gt;&lt;b&gt;")
+
+	start := strings.Index(buff.String(), `<script id="data" type="application/json">`)
+	end := strings.Index(buff.String()[start:], "</script>")
+	data := buff.String()[start+len(`<script id="data" type="application/json">`) : start+end]
+	var parsed map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(data), &parsed))
+}
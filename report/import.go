@@ -13,30 +13,41 @@ import (
 
 // ReadAnalysis reads analysis report from JSON file and returns directory item
 func ReadAnalysis(input io.Reader) (dir *analyze.Dir, err error) {
+	dir, _, err = ReadAnalysisWithMetadata(input)
+	return dir, err
+}
+
+// ReadAnalysisWithMetadata reads analysis report from JSON file
+// and returns directory item together with metadata from the header
+func ReadAnalysisWithMetadata(input io.Reader) (*analyze.Dir, *Metadata, error) {
 	var data interface{}
 
 	var buff bytes.Buffer
-	if _, err = buff.ReadFrom(input); err != nil {
-		return nil, err
+	if _, err := buff.ReadFrom(input); err != nil {
+		return nil, nil, err
 	}
 	if err := json.Unmarshal(buff.Bytes(), &data); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	dataArray, ok := data.([]interface{})
 	if !ok {
-		return nil, errors.New("JSON file does not contain top level array")
+		return nil, nil, errors.New("JSON file does not contain top level array")
 	}
 	if len(dataArray) < 4 {
-		return nil, errors.New("top level array must have at least 4 items")
+		return nil, nil, errors.New("top level array must have at least 4 items")
 	}
 
 	items, ok := dataArray[3].([]interface{})
 	if !ok {
-		return nil, errors.New("array of maps not found in the top level array on 4th position")
+		return nil, nil, errors.New("array of maps not found in the top level array on 4th position")
 	}
 
-	return processDir(items)
+	dir, err := processDir(items)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dir, readJSONMetadata(dataArray[2]), nil
 }
 
 func processDir(items []interface{}) (dir *analyze.Dir, err error) {
@@ -0,0 +1,279 @@
+package report
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// Input formats of imported analysis
+const (
+	InputFormatJSON = "json"
+	InputFormatDuB  = "du-b"
+	InputFormatDuK  = "du-k"
+	InputFormatFind = "find"
+)
+
+const listingLineLimit = 1024 * 1024
+
+// IsListingFormat returns true if given input format is plain text listing of paths
+func IsListingFormat(format string) bool {
+	switch format {
+	case InputFormatDuB, InputFormatDuK, InputFormatFind:
+		return true
+	}
+	return false
+}
+
+type listingEntry struct {
+	size  int64
+	usage int64
+	mtime time.Time
+	dir   bool
+}
+
+type listingNode struct {
+	name     string
+	entry    *listingEntry
+	children map[string]*listingNode
+	order    []*listingNode
+}
+
+func (n *listingNode) child(name string) *listingNode {
+	if c, ok := n.children[name]; ok {
+		return c
+	}
+	c := &listingNode{name: name}
+	if n.children == nil {
+		n.children = make(map[string]*listingNode)
+	}
+	n.children[name] = c
+	n.order = append(n.order, c)
+	return c
+}
+
+// ReadListing reads flat listing of paths and rebuilds directory tree from it.
+// Supported formats are output of `du -ab` (du-b), `du -ak` (du-k)
+// and `find -printf '%s %k %T@ %p\n'` (find).
+// Directories not listed on their own line are created with zero own size.
+// Listed paths with trailing slash are directories even if they are empty,
+// other paths without listed items are files.
+func ReadListing(input io.Reader, format string) (*analyze.Dir, error) {
+	var parse func(string) (string, *listingEntry, error)
+	switch format {
+	case InputFormatDuB:
+		parse = func(line string) (string, *listingEntry, error) {
+			return parseDuLine(line, 1)
+		}
+	case InputFormatDuK:
+		parse = func(line string) (string, *listingEntry, error) {
+			return parseDuLine(line, 1024)
+		}
+	case InputFormatFind:
+		parse = parseFindLine
+	default:
+		return nil, fmt.Errorf("unknown input format: %s", format)
+	}
+
+	paths := make([][]string, 0)
+	entries := make([]*listingEntry, 0)
+
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 0, 64*1024), listingLineLimit)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		path, entry, err := parse(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		entry.dir = strings.HasSuffix(path, "/")
+		paths = append(paths, splitListingPath(path))
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, errors.New("listing does not contain any items")
+	}
+
+	prefix := commonListingPrefix(paths, entries[0].dir)
+
+	root := &listingNode{}
+	for i, path := range paths {
+		node := root
+		for _, name := range path[len(prefix):] {
+			node = node.child(name)
+		}
+		node.entry = entries[i]
+	}
+
+	dir := &analyze.Dir{
+		File: &analyze.File{
+			Flag: ' ',
+		},
+	}
+	switch {
+	case len(prefix) == 0:
+		dir.Name = "."
+	case len(prefix) == 1:
+		dir.Name = prefix[0]
+	default:
+		dir.Name = prefix[len(prefix)-1]
+		dir.BasePath = joinListingPath(prefix[:len(prefix)-1])
+	}
+
+	// du reports directories with size of all their items
+	cumulative := format != InputFormatFind
+	fillListingDir(dir, root, cumulative)
+	return dir, nil
+}
+
+func parseDuLine(line string, unit int64) (string, *listingEntry, error) {
+	sizeStr, path, ok := strings.Cut(line, "\t")
+	if !ok {
+		sizeStr, path, ok = strings.Cut(line, " ")
+	}
+	if !ok || path == "" {
+		return "", nil, fmt.Errorf("expected size and path: %q", line)
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(sizeStr), 10, 64)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid size: %q", sizeStr)
+	}
+	size *= unit
+
+	// du prints only one of the sizes
+	return path, &listingEntry{size: size, usage: size}, nil
+}
+
+func parseFindLine(line string) (string, *listingEntry, error) {
+	parts := strings.SplitN(line, " ", 4)
+	if len(parts) != 4 || parts[3] == "" {
+		return "", nil, fmt.Errorf("expected size, blocks, mtime and path: %q", line)
+	}
+	size, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid size: %q", parts[0])
+	}
+	blocks, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid number of blocks: %q", parts[1])
+	}
+	mtime, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid mtime: %q", parts[2])
+	}
+	sec, frac := math.Modf(mtime)
+
+	return parts[3], &listingEntry{
+		size:  size,
+		usage: blocks * 1024,
+		mtime: time.Unix(int64(sec), int64(frac*1e9)),
+	}, nil
+}
+
+func splitListingPath(path string) []string {
+	parts := strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+	if strings.HasPrefix(path, "/") {
+		parts = append([]string{"/"}, parts...)
+	}
+	return parts
+}
+
+func joinListingPath(parts []string) string {
+	if len(parts) > 0 && parts[0] == "/" {
+		return "/" + strings.Join(parts[1:], "/")
+	}
+	return strings.Join(parts, "/")
+}
+
+// commonListingPrefix returns path of the deepest directory containing all listed items
+func commonListingPrefix(paths [][]string, firstIsDir bool) []string {
+	prefix := paths[0]
+	for _, path := range paths[1:] {
+		i := 0
+		for i < len(prefix) && i < len(path) && prefix[i] == path[i] {
+			i++
+		}
+		prefix = prefix[:i]
+	}
+
+	// listing of single file
+	if len(paths) == 1 && len(prefix) > 0 && !firstIsDir {
+		prefix = prefix[:len(prefix)-1]
+	}
+	return prefix
+}
+
+func fillListingDir(dir *analyze.Dir, node *listingNode, cumulative bool) {
+	var size, usage int64
+	itemCount := 1
+	files := make(fs.Files, 0, len(node.order))
+
+	for _, child := range node.order {
+		var item fs.Item
+		if len(child.children) > 0 || (child.entry != nil && child.entry.dir) {
+			subdir := &analyze.Dir{
+				File: &analyze.File{
+					Name:   child.name,
+					Flag:   ' ',
+					Parent: dir,
+				},
+			}
+			fillListingDir(subdir, child, cumulative)
+			item = subdir
+		} else {
+			file := &analyze.File{
+				Name:   child.name,
+				Flag:   ' ',
+				Parent: dir,
+			}
+			if child.entry != nil {
+				file.Size = child.entry.size
+				file.Usage = child.entry.usage
+				file.Mtime = child.entry.mtime
+			}
+			item = file
+		}
+
+		size += item.GetSize()
+		usage += item.GetUsage()
+		itemCount += item.GetItemCount()
+		if item.GetMtime().After(dir.Mtime) {
+			dir.Mtime = item.GetMtime()
+		}
+		files = append(files, item)
+	}
+
+	if entry := node.entry; entry != nil {
+		if entry.mtime.After(dir.Mtime) {
+			dir.Mtime = entry.mtime
+		}
+		if cumulative {
+			size = max(size, entry.size)
+			usage = max(usage, entry.usage)
+		} else {
+			size += entry.size
+			usage += entry.usage
+		}
+	}
+
+	dir.Files = files
+	dir.Size = size
+	dir.Usage = usage
+	dir.ItemCount = itemCount
+}
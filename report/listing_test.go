@@ -0,0 +1,149 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+)
+
+func TestReadDuBytesListing(t *testing.T) {
+	input := `100	/srv/xxx/sp ace/g h
+4196	/srv/xxx/sp ace
+5000	/srv/xxx/a/b/f
+9096	/srv/xxx/a/b
+13192	/srv/xxx/a
+21484	/srv/xxx
+`
+
+	dir, err := ReadListing(strings.NewReader(input), InputFormatDuB)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "/srv/xxx", dir.GetPath())
+	assert.Equal(t, int64(21484), dir.GetSize())
+	assert.Equal(t, int64(21484), dir.GetUsage())
+	assert.Equal(t, 6, dir.GetItemCount())
+
+	space := dir.Files[0].(*analyze.Dir)
+	assert.Equal(t, "/srv/xxx/sp ace", space.GetPath())
+	assert.Equal(t, "/srv/xxx/sp ace/g h", space.Files[0].GetPath())
+	assert.False(t, space.Files[0].IsDir())
+
+	b := dir.Files[1].GetFiles()[0]
+	assert.Equal(t, "/srv/xxx/a/b", b.GetPath())
+	assert.Equal(t, int64(9096), b.GetSize())
+	assert.Equal(t, dir.Files[1], b.GetParent())
+}
+
+func TestReadDuKiBListingWithImplicitDirs(t *testing.T) {
+	input := "8\t./a/b/f\n4\t./a/c\n4\t./d\n"
+
+	dir, err := ReadListing(strings.NewReader(input), InputFormatDuK)
+	assert.Nil(t, err)
+
+	assert.Equal(t, ".", dir.GetPath())
+	assert.Equal(t, int64(16*1024), dir.GetUsage())
+	assert.Equal(t, 6, dir.GetItemCount())
+
+	a := dir.Files[0]
+	assert.True(t, a.IsDir())
+	assert.Equal(t, "a", a.GetName())
+	assert.Equal(t, int64(12*1024), a.GetUsage())
+	assert.True(t, a.GetFiles()[0].IsDir())
+}
+
+func TestReadFindListing(t *testing.T) {
+	input := `4096 4 1700000000.5000000000 /srv
+4096 4 1700000000.0000000000 /srv/a
+5000 8 1700000100.2500000000 /srv/a/f
+100 4 1600000000.0000000000 /srv/g
+`
+
+	dir, err := ReadListing(strings.NewReader(input), InputFormatFind)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "/srv", dir.GetPath())
+	assert.Equal(t, int64(4096+4096+5000+100), dir.GetSize())
+	assert.Equal(t, int64(20*1024), dir.GetUsage())
+	assert.Equal(t, int64(1700000100), dir.GetMtime().Unix())
+	assert.Equal(t, 4, dir.GetItemCount())
+
+	f := dir.Files[0].GetFiles()[0]
+	assert.Equal(t, "/srv/a/f", f.GetPath())
+	assert.Equal(t, int64(5000), f.GetSize())
+	assert.Equal(t, int64(8192), f.GetUsage())
+	assert.Equal(t, 250, f.GetMtime().Nanosecond()/1e6)
+}
+
+func TestReadFindListingWithEmptyDir(t *testing.T) {
+	input := `4096 4 1700000000.0000000000 /srv/
+4096 4 1700000000.0000000000 /srv/empty/
+0 0 1700000000.0000000000 /srv/file
+`
+
+	dir, err := ReadListing(strings.NewReader(input), InputFormatFind)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "/srv", dir.GetPath())
+	assert.Equal(t, 3, dir.GetItemCount())
+
+	empty := dir.Files[0]
+	assert.Equal(t, "/srv/empty", empty.GetPath())
+	assert.True(t, empty.IsDir())
+	assert.Empty(t, empty.GetFiles())
+	assert.Equal(t, int64(4096), empty.GetSize())
+	assert.False(t, dir.Files[1].IsDir())
+}
+
+func TestReadListingOfSingleEmptyDir(t *testing.T) {
+	dir, err := ReadListing(strings.NewReader("4096 4 1700000000 /srv/empty/\n"), InputFormatFind)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "/srv/empty", dir.GetPath())
+	assert.Equal(t, int64(4096), dir.GetSize())
+	assert.Empty(t, dir.GetFiles())
+}
+
+func TestReadListingOfRoot(t *testing.T) {
+	dir, err := ReadListing(strings.NewReader("5\t/etc/x\n10\t/\n"), InputFormatDuB)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "/", dir.GetPath())
+	assert.Equal(t, int64(10), dir.GetSize())
+	assert.Equal(t, "/etc/x", dir.Files[0].GetFiles()[0].GetPath())
+}
+
+func TestReadListingOfSingleFile(t *testing.T) {
+	dir, err := ReadListing(strings.NewReader("5\tdata/file\n"), InputFormatDuB)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "data", dir.GetPath())
+	assert.Equal(t, "data/file", dir.Files[0].GetPath())
+}
+
+func TestReadListingErrors(t *testing.T) {
+	_, err := ReadListing(strings.NewReader(""), InputFormatDuB)
+	assert.ErrorContains(t, err, "listing does not contain any items")
+
+	_, err = ReadListing(strings.NewReader("5\ta\nxx\tb\n"), InputFormatDuB)
+	assert.ErrorContains(t, err, `line 2: invalid size: "xx"`)
+
+	_, err = ReadListing(strings.NewReader("5\n"), InputFormatDuK)
+	assert.ErrorContains(t, err, "expected size and path")
+
+	_, err = ReadListing(strings.NewReader("1 2 x a\n"), InputFormatFind)
+	assert.ErrorContains(t, err, `invalid mtime: "x"`)
+
+	_, err = ReadListing(strings.NewReader("1 2\n"), InputFormatFind)
+	assert.ErrorContains(t, err, "expected size, blocks, mtime and path")
+
+	_, err = ReadListing(strings.NewReader("1\ta\n"), "xxx")
+	assert.ErrorContains(t, err, "unknown input format: xxx")
+}
+
+func TestIsListingFormat(t *testing.T) {
+	assert.True(t, IsListingFormat(InputFormatFind))
+	assert.False(t, IsListingFormat(InputFormatJSON))
+}
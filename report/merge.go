@@ -0,0 +1,244 @@
+package report
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// MergeInput is one analysis to be merged
+type MergeInput struct {
+	Dir  *analyze.Dir
+	Name string
+}
+
+// Conflict describes path claimed by more than one merged analysis
+type Conflict struct {
+	Path    string
+	Kept    string
+	Skipped string
+}
+
+// String returns description of the conflict
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s is claimed by both %s and %s, keeping the one from %s", c.Path, c.Kept, c.Skipped, c.Kept)
+}
+
+type merger struct {
+	owners    map[fs.Item]string
+	inodes    map[uint64]struct{}
+	conflicts []Conflict
+	nextInode uint64
+}
+
+// MergeAnalyses combines several analyses into one tree.
+//
+// By default every analysis is placed as a directory named by its input
+// under a synthetic root directory named rootName.
+// With overlay, analyses are overlaid by their paths under their deepest
+// common directory and directories present in more analyses are merged.
+//
+// Paths claimed by more than one analysis are returned as conflicts,
+// the item from the analysis given first is kept.
+// Stats of the resulting tree are recomputed.
+func MergeAnalyses(inputs []MergeInput, rootName string, overlay bool) (*analyze.Dir, []Conflict) {
+	m := &merger{
+		owners:    make(map[fs.Item]string),
+		inodes:    make(map[uint64]struct{}),
+		nextInode: 1 << 63,
+	}
+
+	for _, input := range inputs {
+		m.renumberInodes(input.Dir, make(map[uint64]uint64))
+	}
+
+	var root *analyze.Dir
+	if overlay {
+		root = m.overlay(inputs)
+	} else {
+		root = m.underRoot(inputs, rootName)
+	}
+
+	root.UpdateStats(make(fs.HardLinkedItems, 10))
+	return root, m.conflicts
+}
+
+func (m *merger) underRoot(inputs []MergeInput, rootName string) *analyze.Dir {
+	root := createMergedDir(rootName)
+	index := make(map[string]fs.Item, len(inputs))
+
+	for _, input := range inputs {
+		if existing, ok := index[input.Name]; ok {
+			m.addConflict(existing, input.Name, filepath.Join(rootName, input.Name))
+			continue
+		}
+
+		input.Dir.Name = input.Name
+		input.Dir.BasePath = ""
+		m.moveTo(root, input.Dir, input.Name)
+		index[input.Name] = input.Dir
+	}
+	return root
+}
+
+func (m *merger) overlay(inputs []MergeInput) *analyze.Dir {
+	paths := make([]string, 0, len(inputs))
+	for _, input := range inputs {
+		paths = append(paths, filepath.Clean(input.Dir.GetPath()))
+	}
+	common := commonDir(paths)
+
+	root := createMergedDir(filepath.Base(common))
+	root.BasePath = filepath.Dir(common)
+
+	for i, input := range inputs {
+		target := root
+		rel, _ := filepath.Rel(common, paths[i])
+
+		if rel != "." {
+			for _, name := range strings.Split(rel, string(filepath.Separator)) {
+				target = m.getSubdir(target, name, input.Name)
+				if target == nil {
+					break
+				}
+			}
+		}
+		if target != nil {
+			m.mergeDir(target, input.Dir, input.Name)
+		}
+	}
+	return root
+}
+
+// getSubdir returns subdirectory with given name, creates it if needed.
+// Returns nil if other analysis has file with the same path.
+func (m *merger) getSubdir(dir *analyze.Dir, name, inputName string) *analyze.Dir {
+	for _, item := range dir.Files {
+		if item.GetName() != name {
+			continue
+		}
+		if subdir, ok := item.(*analyze.Dir); ok {
+			return subdir
+		}
+		m.addConflict(item, inputName, item.GetPath())
+		return nil
+	}
+
+	subdir := createMergedDir(name)
+	subdir.Parent = dir
+	dir.AddFile(subdir)
+	return subdir
+}
+
+// mergeDir moves content of src directory into dst directory
+func (m *merger) mergeDir(dst, src *analyze.Dir, inputName string) {
+	index := make(map[string]fs.Item, len(dst.Files))
+	for _, item := range dst.Files {
+		index[item.GetName()] = item
+	}
+
+	for _, item := range src.Files {
+		existing, ok := index[item.GetName()]
+		if !ok {
+			m.moveTo(dst, item, inputName)
+			continue
+		}
+
+		existingDir, existingIsDir := existing.(*analyze.Dir)
+		itemDir, itemIsDir := item.(*analyze.Dir)
+		if existingIsDir && itemIsDir {
+			m.mergeDir(existingDir, itemDir, inputName)
+			continue
+		}
+		m.addConflict(existing, inputName, existing.GetPath())
+	}
+}
+
+func (m *merger) moveTo(dir *analyze.Dir, item fs.Item, inputName string) {
+	item.SetParent(dir)
+	dir.AddFile(item)
+	m.owners[item] = inputName
+}
+
+func (m *merger) addConflict(existing fs.Item, inputName, path string) {
+	m.conflicts = append(m.conflicts, Conflict{
+		Path:    path,
+		Kept:    m.getOwner(existing),
+		Skipped: inputName,
+	})
+}
+
+// getOwner returns name of the analysis the item comes from
+func (m *merger) getOwner(item fs.Item) string {
+	for ; item != nil; item = item.GetParent() {
+		if owner, ok := m.owners[item]; ok {
+			return owner
+		}
+	}
+	return ""
+}
+
+// renumberInodes changes inode numbers of hard linked files colliding
+// with inode numbers of previously merged analyses
+// so that files from different analyses are not counted as hard links
+func (m *merger) renumberInodes(item fs.Item, remap map[uint64]uint64) {
+	for _, child := range item.GetFiles() {
+		if child.IsDir() {
+			m.renumberInodes(child, remap)
+			continue
+		}
+
+		file, ok := child.(*analyze.File)
+		if !ok || file.Mli == 0 {
+			continue
+		}
+
+		inode, ok := remap[file.Mli]
+		if !ok {
+			inode = file.Mli
+			if _, used := m.inodes[inode]; used {
+				inode = m.nextInode
+				m.nextInode++
+			}
+			remap[file.Mli] = inode
+			m.inodes[inode] = struct{}{}
+		}
+		file.Mli = inode
+	}
+}
+
+func createMergedDir(name string) *analyze.Dir {
+	return &analyze.Dir{
+		File: &analyze.File{
+			Name: name,
+			Flag: ' ',
+		},
+		Files: make(fs.Files, 0),
+	}
+}
+
+// commonDir returns the deepest directory containing all given paths
+func commonDir(paths []string) string {
+	common := paths[0]
+	for _, path := range paths[1:] {
+		for !isWithin(path, common) {
+			parent := filepath.Dir(common)
+			if parent == common {
+				break
+			}
+			common = parent
+		}
+	}
+	return common
+}
+
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
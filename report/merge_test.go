@@ -0,0 +1,123 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+)
+
+func readTestAnalysis(t *testing.T, data string) *analyze.Dir {
+	t.Helper()
+	dir, err := ReadAnalysis(bytes.NewBufferString(data))
+	assert.Nil(t, err)
+	return dir
+}
+
+func TestMergeAnalysesUnderRoot(t *testing.T) {
+	first := readTestAnalysis(t, `[1,2,{"progname":"gdu"},
+		[{"name":"/srv/data"},
+		{"name":"a","asize":100,"dsize":4096}]]`)
+	second := readTestAnalysis(t, `[1,2,{"progname":"gdu"},
+		[{"name":"/srv/data"},
+		{"name":"b","asize":200,"dsize":8192}]]`)
+
+	root, conflicts := MergeAnalyses([]MergeInput{
+		{Dir: first, Name: "host1"},
+		{Dir: second, Name: "host2"},
+	}, "merged", false)
+
+	assert.Empty(t, conflicts)
+	assert.Equal(t, "merged", root.GetPath())
+	assert.Equal(t, 2, len(root.Files))
+	assert.Equal(t, "merged/host1/a", root.Files[0].GetFiles()[0].GetPath())
+	assert.Equal(t, "merged/host2/b", root.Files[1].GetFiles()[0].GetPath())
+	assert.Equal(t, int64(4096+4096+4096+4096+8192), root.GetUsage())
+	assert.Equal(t, 5, root.GetItemCount())
+}
+
+func TestMergeAnalysesUnderRootWithSameName(t *testing.T) {
+	first := readTestAnalysis(t, `[1,2,{"progname":"gdu"},[{"name":"/srv/data"}]]`)
+	second := readTestAnalysis(t, `[1,2,{"progname":"gdu"},[{"name":"/srv/other"}]]`)
+
+	root, conflicts := MergeAnalyses([]MergeInput{
+		{Dir: first, Name: "data"},
+		{Dir: second, Name: "data"},
+	}, "merged", false)
+
+	assert.Equal(t, 1, len(root.Files))
+	assert.Equal(t, []Conflict{{Path: "merged/data", Kept: "data", Skipped: "data"}}, conflicts)
+}
+
+func TestMergeAnalysesOverlay(t *testing.T) {
+	first := readTestAnalysis(t, `[1,2,{"progname":"gdu"},
+		[{"name":"/srv"},
+		[{"name":"data"},
+		{"name":"a","asize":100,"dsize":4096},
+		{"name":"same","asize":100,"dsize":4096}]]]`)
+	second := readTestAnalysis(t, `[1,2,{"progname":"gdu"},
+		[{"name":"/srv/data"},
+		{"name":"b","asize":200,"dsize":8192},
+		{"name":"same","asize":300,"dsize":8192}]]`)
+	third := readTestAnalysis(t, `[1,2,{"progname":"gdu"},
+		[{"name":"/srv/logs/app"},
+		{"name":"c","asize":200,"dsize":4096}]]`)
+
+	root, conflicts := MergeAnalyses([]MergeInput{
+		{Dir: first, Name: "first"},
+		{Dir: second, Name: "second"},
+		{Dir: third, Name: "third"},
+	}, "merged", true)
+
+	assert.Equal(t, "/srv", root.GetPath())
+	assert.Equal(t, 2, len(root.Files))
+
+	data := root.Files[0].(*analyze.Dir)
+	assert.Equal(t, "/srv/data", data.GetPath())
+	assert.Equal(t, 3, len(data.Files))
+	assert.Equal(t, int64(4096), data.Files[1].GetUsage())
+	assert.Equal(t, "/srv/logs/app/c", root.Files[1].GetFiles()[0].GetFiles()[0].GetPath())
+
+	assert.Equal(t, []Conflict{{Path: "/srv/data/same", Kept: "first", Skipped: "second"}}, conflicts)
+	assert.Equal(t, "/srv/data/same is claimed by both first and second, keeping the one from first", conflicts[0].String())
+	assert.Equal(t, int64(4096+4096+4096+4096+8192+4096+4096+4096), root.GetUsage())
+}
+
+func TestMergeAnalysesOverlayWithFileInPath(t *testing.T) {
+	first := readTestAnalysis(t, `[1,2,{"progname":"gdu"},
+		[{"name":"/srv"},
+		{"name":"data","asize":100,"dsize":4096}]]`)
+	second := readTestAnalysis(t, `[1,2,{"progname":"gdu"},
+		[{"name":"/srv/data/x"},
+		{"name":"b","asize":200,"dsize":8192}]]`)
+
+	root, conflicts := MergeAnalyses([]MergeInput{
+		{Dir: first, Name: "first"},
+		{Dir: second, Name: "second"},
+	}, "merged", true)
+
+	assert.Equal(t, 1, len(root.Files))
+	assert.False(t, root.Files[0].IsDir())
+	assert.Equal(t, []Conflict{{Path: "/srv/data", Kept: "first", Skipped: "second"}}, conflicts)
+}
+
+func TestMergeAnalysesKeepsHardLinksSeparate(t *testing.T) {
+	first := readTestAnalysis(t, `[1,2,{"progname":"gdu"},
+		[{"name":"/srv/a"},
+		{"name":"x","ino":10,"hlnkc":true,"asize":100,"dsize":4096},
+		{"name":"y","ino":10,"hlnkc":true,"asize":100,"dsize":4096}]]`)
+	second := readTestAnalysis(t, `[1,2,{"progname":"gdu"},
+		[{"name":"/srv/b"},
+		{"name":"z","ino":10,"hlnkc":true,"asize":100,"dsize":4096}]]`)
+
+	root, conflicts := MergeAnalyses([]MergeInput{
+		{Dir: first, Name: "a"},
+		{Dir: second, Name: "b"},
+	}, "merged", false)
+
+	assert.Empty(t, conflicts)
+	// the link within first analysis is counted once, the file from second analysis is counted too
+	assert.Equal(t, int64(4096+4096+4096+4096+4096), root.GetUsage())
+}
@@ -0,0 +1,309 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dundee/gdu/v5/build"
+	"github.com/dundee/gdu/v5/internal/common"
+	"github.com/dundee/gdu/v5/pkg/device"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// Metadata describes where, when and how the analysis was made
+type Metadata struct {
+	Version    string
+	Hostname   string
+	Root       string
+	Analyzer   string
+	Options    map[string]string
+	Timestamp  time.Time
+	Duration   time.Duration
+	ErrorCount int
+	Device     *device.Device
+}
+
+// MetadataItem is a labeled value of the metadata
+type MetadataItem struct {
+	Name  string
+	Value string
+}
+
+type jsonMetadata struct {
+	Progname   string              `json:"progname"`
+	Progver    string              `json:"progver"`
+	Timestamp  int64               `json:"timestamp"`
+	Hostname   string              `json:"hostname,omitempty"`
+	Root       string              `json:"scan_root,omitempty"`
+	Analyzer   string              `json:"analyzer,omitempty"`
+	Options    map[string]string   `json:"options,omitempty"`
+	Duration   float64             `json:"scan_duration,omitempty"`
+	ErrorCount int                 `json:"errors,omitempty"`
+	Device     *jsonDeviceMetadata `json:"device,omitempty"`
+}
+
+type jsonDeviceMetadata struct {
+	Name       string `json:"name"`
+	MountPoint string `json:"mount_point"`
+	Fstype     string `json:"fstype,omitempty"`
+	Size       int64  `json:"size"`
+	Free       int64  `json:"free"`
+}
+
+// NewMetadata returns metadata of analysis made by this instance of gdu
+func NewMetadata(analyzer string, options map[string]string) *Metadata {
+	hostname, _ := os.Hostname()
+	return &Metadata{
+		Version:  build.Version,
+		Hostname: hostname,
+		Analyzer: analyzer,
+		Options:  options,
+	}
+}
+
+// Finish records the result of finished analysis of dir
+func (m *Metadata) Finish(dir fs.Item, start time.Time, dev *device.Device) {
+	m.Root = dir.GetPath()
+	m.Timestamp = start
+	m.Duration = time.Since(start)
+	m.ErrorCount = CountErrors(dir)
+	m.Device = dev
+}
+
+// CountErrors returns number of directories which could not be read
+func CountErrors(item fs.Item) int {
+	count := 0
+	if item.GetFlag() == '!' {
+		count++
+	}
+	if !item.IsDir() {
+		return count
+	}
+	for _, file := range item.GetFiles() {
+		count += CountErrors(file)
+	}
+	return count
+}
+
+// FormatDuration returns duration rounded to milliseconds, very short durations to microseconds
+func FormatDuration(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+// Items returns metadata as list of labeled values in fixed order
+func (m *Metadata) Items() []MetadataItem {
+	items := make([]MetadataItem, 0, 10)
+	add := func(name, value string) {
+		if value != "" {
+			items = append(items, MetadataItem{Name: name, Value: value})
+		}
+	}
+
+	add("Host", m.Hostname)
+	add("Root", m.Root)
+	if !m.Timestamp.IsZero() {
+		add("Scanned at", m.Timestamp.Format("2006-01-02 15:04:05"))
+	}
+	if m.Duration > 0 {
+		add("Scan duration", FormatDuration(m.Duration))
+	}
+	add("Errors", strconv.Itoa(m.ErrorCount))
+	add("Analyzer", m.Analyzer)
+	add("gdu version", m.Version)
+	if m.Device != nil {
+		add("Device", fmt.Sprintf("%s mounted at %s", m.Device.Name, m.Device.MountPoint))
+		add("Device size", common.FormatNumber(m.Device.Size)+" B")
+		add("Device free", common.FormatNumber(m.Device.Free)+" B")
+	}
+
+	keys := make([]string, 0, len(m.Options))
+	for k := range m.Options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		add("Option "+k, m.Options[k])
+	}
+	return items
+}
+
+func (m *Metadata) toJSON() *jsonMetadata {
+	data := &jsonMetadata{
+		Progname:   "gdu",
+		Progver:    build.Version,
+		Timestamp:  time.Now().Unix(),
+		Hostname:   m.Hostname,
+		Root:       m.Root,
+		Analyzer:   m.Analyzer,
+		Options:    m.Options,
+		Duration:   m.Duration.Seconds(),
+		ErrorCount: m.ErrorCount,
+	}
+	if m.Version != "" {
+		data.Progver = m.Version
+	}
+	if !m.Timestamp.IsZero() {
+		data.Timestamp = m.Timestamp.Unix()
+	}
+	if m.Device != nil {
+		data.Device = &jsonDeviceMetadata{
+			Name:       m.Device.Name,
+			MountPoint: m.Device.MountPoint,
+			Fstype:     m.Device.Fstype,
+			Size:       m.Device.Size,
+			Free:       m.Device.Free,
+		}
+	}
+	return data
+}
+
+func (data *jsonMetadata) toMetadata() *Metadata {
+	m := &Metadata{
+		Version:    data.Progver,
+		Hostname:   data.Hostname,
+		Root:       data.Root,
+		Analyzer:   data.Analyzer,
+		Options:    data.Options,
+		Duration:   time.Duration(data.Duration * float64(time.Second)),
+		ErrorCount: data.ErrorCount,
+	}
+	if data.Timestamp > 0 {
+		m.Timestamp = time.Unix(data.Timestamp, 0)
+	}
+	if data.Device != nil {
+		m.Device = &device.Device{
+			Name:       data.Device.Name,
+			MountPoint: data.Device.MountPoint,
+			Fstype:     data.Device.Fstype,
+			Size:       data.Device.Size,
+			Free:       data.Device.Free,
+		}
+	}
+	return m
+}
+
+// toKeyValues returns metadata as flat key-value pairs stored in the SQLite database
+func (m *Metadata) toKeyValues() map[string]string {
+	data := m.toJSON()
+	values := map[string]string{
+		"progname":  data.Progname,
+		"progver":   data.Progver,
+		"timestamp": strconv.FormatInt(data.Timestamp, 10),
+	}
+	set := func(key, value string) {
+		if value != "" {
+			values[key] = value
+		}
+	}
+
+	// options keep their own keys
+	for k, v := range m.Options {
+		values[k] = v
+	}
+
+	set("hostname", m.Hostname)
+	set("scan_root", m.Root)
+	set("analyzer", m.Analyzer)
+	if m.Duration > 0 {
+		values["scan_duration"] = strconv.FormatFloat(data.Duration, 'f', -1, 64)
+	}
+	if m.ErrorCount > 0 {
+		values["errors"] = strconv.Itoa(m.ErrorCount)
+	}
+	if d := data.Device; d != nil {
+		values["device_name"] = d.Name
+		values["device_mount_point"] = d.MountPoint
+		set("device_fstype", d.Fstype)
+		values["device_size"] = strconv.FormatInt(d.Size, 10)
+		values["device_free"] = strconv.FormatInt(d.Free, 10)
+	}
+	return values
+}
+
+func metadataFromKeyValues(values map[string]string) *Metadata {
+	m := &Metadata{
+		Version:  values["progver"],
+		Hostname: values["hostname"],
+		Root:     values["scan_root"],
+		Analyzer: values["analyzer"],
+		Options:  make(map[string]string),
+	}
+	if ts, err := strconv.ParseInt(values["timestamp"], 10, 64); err == nil {
+		m.Timestamp = time.Unix(ts, 0)
+	}
+	if duration, err := strconv.ParseFloat(values["scan_duration"], 64); err == nil {
+		m.Duration = time.Duration(duration * float64(time.Second))
+	}
+	if count, err := strconv.Atoi(values["errors"]); err == nil {
+		m.ErrorCount = count
+	}
+	if name, ok := values["device_name"]; ok {
+		m.Device = &device.Device{
+			Name:       name,
+			MountPoint: values["device_mount_point"],
+			Fstype:     values["device_fstype"],
+		}
+		m.Device.Size, _ = strconv.ParseInt(values["device_size"], 10, 64)
+		m.Device.Free, _ = strconv.ParseInt(values["device_free"], 10, 64)
+	}
+
+	for k, v := range values {
+		switch k {
+		case "progname", "progver", "timestamp", "hostname", "root", "scan_root", "analyzer",
+			"scan_duration", "errors", "device_name", "device_mount_point",
+			"device_fstype", "device_size", "device_free":
+			continue
+		}
+		m.Options[k] = v
+	}
+	return m
+}
+
+// WriteJSON writes given dir tree into writer as ncdu compatible JSON
+// with metadata of the analysis in the header
+func WriteJSON(writer io.Writer, dir fs.Item, meta *Metadata) error {
+	if meta == nil {
+		meta = &Metadata{}
+	}
+	header, err := json.Marshal(meta.toJSON())
+	if err != nil {
+		return err
+	}
+
+	var buff bytes.Buffer
+
+	buff.Write([]byte(`[1,2,`))
+	buff.Write(header)
+	buff.Write([]byte(",\n"))
+
+	if err := dir.EncodeJSON(&buff, true); err != nil {
+		return err
+	}
+	if _, err := buff.Write([]byte("]\n")); err != nil {
+		return err
+	}
+	_, err = buff.WriteTo(writer)
+	return err
+}
+
+// readJSONMetadata reads metadata from the header of JSON export
+func readJSONMetadata(header interface{}) *Metadata {
+	raw, err := json.Marshal(header)
+	if err != nil {
+		return &Metadata{}
+	}
+	data := &jsonMetadata{}
+	if err := json.Unmarshal(raw, data); err != nil {
+		return &Metadata{}
+	}
+	return data.toMetadata()
+}
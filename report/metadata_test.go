@@ -0,0 +1,126 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/device"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func TestWriteAndReadJSONMetadata(t *testing.T) {
+	dir := &analyze.Dir{
+		File: &analyze.File{
+			Name: "xxx",
+		},
+		BasePath: "/srv",
+	}
+	dir.Files = fs.Files{&analyze.File{Name: "file", Size: 10, Usage: 4096, Parent: dir}}
+
+	meta := &Metadata{
+		Version:    "v5.0.0",
+		Hostname:   "host",
+		Root:       "/srv/xxx",
+		Analyzer:   "sequential",
+		Options:    map[string]string{"ignore-dirs": "/proc,/sys", "since": "2024-01-01"},
+		Timestamp:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Duration:   2500 * time.Millisecond,
+		ErrorCount: 3,
+		Device:     &device.Device{Name: "/dev/sda1", MountPoint: "/srv", Size: 1000, Free: 100},
+	}
+
+	buff := bytes.NewBuffer(nil)
+	err := WriteJSON(buff, dir, meta)
+	assert.Nil(t, err)
+	assert.Contains(t, buff.String(), `"progname":"gdu","progver":"v5.0.0","timestamp":1704164645,"hostname":"host"`)
+
+	read, readMeta, err := ReadAnalysisWithMetadata(buff)
+	assert.Nil(t, err)
+	assert.Equal(t, "/srv/xxx", read.GetPath())
+	assert.Equal(t, meta.Version, readMeta.Version)
+	assert.Equal(t, meta.Hostname, readMeta.Hostname)
+	assert.Equal(t, meta.Root, readMeta.Root)
+	assert.Equal(t, meta.Analyzer, readMeta.Analyzer)
+	assert.Equal(t, meta.Options, readMeta.Options)
+	assert.Equal(t, meta.Timestamp.Unix(), readMeta.Timestamp.Unix())
+	assert.Equal(t, meta.Duration, readMeta.Duration)
+	assert.Equal(t, meta.ErrorCount, readMeta.ErrorCount)
+	assert.Equal(t, *meta.Device, *readMeta.Device)
+}
+
+func TestReadJSONWithoutMetadata(t *testing.T) {
+	input := `[1,2,{"progname":"gdu","progver":"v5.7.0","timestamp":1626806293},
+	[{"name":"/home/xxx"}]]`
+
+	_, meta, err := ReadAnalysisWithMetadata(strings.NewReader(input))
+	assert.Nil(t, err)
+	assert.Equal(t, "v5.7.0", meta.Version)
+	assert.Equal(t, int64(1626806293), meta.Timestamp.Unix())
+	assert.Empty(t, meta.Hostname)
+	assert.Nil(t, meta.Device)
+}
+
+func TestMetadataItems(t *testing.T) {
+	meta := &Metadata{
+		Hostname: "host",
+		Root:     "/srv",
+		Options:  map[string]string{"no-hidden": "true", "follow-symlinks": "true"},
+		Duration: 1234567 * time.Microsecond,
+		Device:   &device.Device{Name: "/dev/sda1", MountPoint: "/srv", Size: 2048, Free: 1024},
+	}
+
+	items := meta.Items()
+
+	assert.Equal(t, MetadataItem{"Host", "host"}, items[0])
+	assert.Equal(t, MetadataItem{"Root", "/srv"}, items[1])
+	assert.Equal(t, MetadataItem{"Scan duration", "1.235s"}, items[2])
+	assert.Equal(t, MetadataItem{"Errors", "0"}, items[3])
+	assert.Equal(t, MetadataItem{"Device", "/dev/sda1 mounted at /srv"}, items[4])
+	assert.Equal(t, MetadataItem{"Device size", "2,048 B"}, items[5])
+	assert.Equal(t, MetadataItem{"Option follow-symlinks", "true"}, items[7])
+	assert.Equal(t, MetadataItem{"Option no-hidden", "true"}, items[8])
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "317µs", FormatDuration(316606*time.Nanosecond))
+	assert.Equal(t, "2.5s", FormatDuration(2500*time.Millisecond))
+}
+
+func TestCountErrors(t *testing.T) {
+	dir := &analyze.Dir{
+		File: &analyze.File{Name: "xxx", Flag: '.'},
+	}
+	subdir := &analyze.Dir{
+		File: &analyze.File{Name: "sub", Flag: '!', Parent: dir},
+	}
+	dir.Files = fs.Files{subdir, &analyze.File{Name: "file", Flag: ' ', Parent: dir}}
+
+	assert.Equal(t, 1, CountErrors(dir))
+}
+
+func TestExportWithMetadata(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	output := bytes.NewBuffer(make([]byte, 10))
+	reportOutput := bytes.NewBuffer(nil)
+
+	ui := CreateExportUI(output, reportOutput, false, false, false, false)
+	ui.SetMetadata(NewMetadata("parallel", map[string]string{"no-cross": "true"}))
+	err := ui.AnalyzePath("test_dir", nil)
+	assert.Nil(t, err)
+
+	_, meta, err := ReadAnalysisWithMetadata(reportOutput)
+	assert.Nil(t, err)
+	assert.Equal(t, "test_dir", meta.Root)
+	assert.Equal(t, "parallel", meta.Analyzer)
+	assert.Equal(t, "true", meta.Options["no-cross"])
+	assert.NotEmpty(t, meta.Hostname)
+	assert.False(t, meta.Timestamp.IsZero())
+}
@@ -0,0 +1,93 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dundee/gdu/v5/pkg/device"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+type promMetric struct {
+	name  string
+	help  string
+	value func(item fs.Item) int64
+}
+
+var promDirMetrics = []promMetric{
+	{
+		name:  "gdu_directory_usage_bytes",
+		help:  "Disk usage of the directory in bytes.",
+		value: func(item fs.Item) int64 { return item.GetUsage() },
+	},
+	{
+		name:  "gdu_directory_apparent_size_bytes",
+		help:  "Apparent size of the directory in bytes.",
+		value: func(item fs.Item) int64 { return item.GetSize() },
+	},
+	{
+		name:  "gdu_directory_items",
+		help:  "Number of items in the directory including the directory itself.",
+		value: func(item fs.Item) int64 { return int64(item.GetItemCount()) },
+	},
+}
+
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WritePrometheus writes metrics of the directories of given dir tree into writer
+// in the Prometheus text exposition format, e.g. for node_exporter's textfile collector.
+// Directories deeper than maxDepth are skipped (0 means no limit).
+// Capacity of the device containing dir is included if dev is not nil.
+func WritePrometheus(writer io.Writer, dir fs.Item, maxDepth int, dev *device.Device, now time.Time) error {
+	var dirs []fs.Item
+	collectPromDirs(dir, 0, maxDepth, &dirs)
+
+	w := bufio.NewWriter(writer)
+	root := promLabelEscaper.Replace(dir.GetPath())
+
+	for _, metric := range promDirMetrics {
+		writePromHeader(w, metric.name, metric.help)
+		for _, item := range dirs {
+			fmt.Fprintf(w, "%s{root=\"%s\",path=\"%s\"} %d\n",
+				metric.name, root, promLabelEscaper.Replace(item.GetPath()), metric.value(item))
+		}
+	}
+
+	if dev != nil {
+		labels := fmt.Sprintf("root=\"%s\",device=\"%s\",mountpoint=\"%s\"",
+			root, promLabelEscaper.Replace(dev.Name), promLabelEscaper.Replace(dev.MountPoint))
+
+		writePromHeader(w, "gdu_device_size_bytes", "Total size of the device containing the root in bytes.")
+		fmt.Fprintf(w, "gdu_device_size_bytes{%s} %d\n", labels, dev.Size)
+		writePromHeader(w, "gdu_device_free_bytes", "Free space on the device containing the root in bytes.")
+		fmt.Fprintf(w, "gdu_device_free_bytes{%s} %d\n", labels, dev.Free)
+	}
+
+	writePromHeader(w, "gdu_scan_timestamp_seconds", "Unix time of the end of the analysis.")
+	fmt.Fprintf(w, "gdu_scan_timestamp_seconds{root=\"%s\"} %d\n", root, now.Unix())
+
+	return w.Flush()
+}
+
+func writePromHeader(w io.Writer, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+}
+
+func collectPromDirs(item fs.Item, depth, maxDepth int, dirs *[]fs.Item) {
+	if maxDepth > 0 && depth > maxDepth {
+		return
+	}
+	*dirs = append(*dirs, item)
+
+	files := item.GetFiles()
+	sort.Sort(sort.Reverse(files))
+	for _, file := range files {
+		if file.IsDir() {
+			collectPromDirs(file, depth+1, maxDepth, dirs)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testdev"
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/device"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	dir := &analyze.Dir{
+		File: &analyze.File{
+			Name:  "xxx",
+			Size:  5,
+			Usage: 12,
+		},
+		BasePath:  "/srv",
+		ItemCount: 3,
+	}
+	subdir := &analyze.Dir{
+		File: &analyze.File{
+			Name:   `we"ird`,
+			Size:   4,
+			Usage:  8,
+			Parent: dir,
+		},
+		ItemCount: 2,
+	}
+	nested := &analyze.Dir{
+		File: &analyze.File{
+			Name:   "nested",
+			Parent: subdir,
+		},
+		ItemCount: 1,
+	}
+	file := &analyze.File{
+		Name:   "file",
+		Size:   1,
+		Usage:  4,
+		Parent: dir,
+	}
+	subdir.Files = fs.Files{nested}
+	dir.Files = fs.Files{subdir, file}
+
+	dev := &device.Device{Name: "/dev/sda1", MountPoint: "/", Size: 1000, Free: 200}
+	buff := bytes.NewBuffer(nil)
+
+	err := WritePrometheus(buff, dir, 1, dev, time.Unix(1700000000, 0))
+	assert.Nil(t, err)
+
+	expected := `# HELP gdu_directory_usage_bytes Disk usage of the directory in bytes.
+# TYPE gdu_directory_usage_bytes gauge
+gdu_directory_usage_bytes{root="/srv/xxx",path="/srv/xxx"} 12
+gdu_directory_usage_bytes{root="/srv/xxx",path="/srv/xxx/we\"ird"} 8
+# HELP gdu_directory_apparent_size_bytes Apparent size of the directory in bytes.
+# TYPE gdu_directory_apparent_size_bytes gauge
+gdu_directory_apparent_size_bytes{root="/srv/xxx",path="/srv/xxx"} 5
+gdu_directory_apparent_size_bytes{root="/srv/xxx",path="/srv/xxx/we\"ird"} 4
+# HELP gdu_directory_items Number of items in the directory including the directory itself.
+# TYPE gdu_directory_items gauge
+gdu_directory_items{root="/srv/xxx",path="/srv/xxx"} 3
+gdu_directory_items{root="/srv/xxx",path="/srv/xxx/we\"ird"} 2
+# HELP gdu_device_size_bytes Total size of the device containing the root in bytes.
+# TYPE gdu_device_size_bytes gauge
+gdu_device_size_bytes{root="/srv/xxx",device="/dev/sda1",mountpoint="/"} 1000
+# HELP gdu_device_free_bytes Free space on the device containing the root in bytes.
+# TYPE gdu_device_free_bytes gauge
+gdu_device_free_bytes{root="/srv/xxx",device="/dev/sda1",mountpoint="/"} 200
+# HELP gdu_scan_timestamp_seconds Unix time of the end of the analysis.
+# TYPE gdu_scan_timestamp_seconds gauge
+gdu_scan_timestamp_seconds{root="/srv/xxx"} 1700000000
+`
+	assert.Equal(t, expected, buff.String())
+}
+
+func TestExportPrometheus(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	abs, err := filepath.Abs("test_dir")
+	assert.Nil(t, err)
+
+	output := bytes.NewBuffer(make([]byte, 10))
+	reportOutput := bytes.NewBuffer(nil)
+
+	ui := CreateExportUI(output, reportOutput, false, false, false, false)
+	err = ui.SetFormat("prometheus")
+	assert.Nil(t, err)
+	ui.SetDevicesInfoGetter(testdev.DevicesInfoGetterMock{
+		Devices: device.Devices{{Name: "/dev/root", MountPoint: filepath.Dir(abs), Size: 100, Free: 10}},
+	})
+	err = ui.AnalyzePath(abs, nil)
+	assert.Nil(t, err)
+
+	out := reportOutput.String()
+	assert.Contains(t, out, `path="`+filepath.Join(abs, "nested", "subnested")+`"`)
+	assert.NotContains(t, out, `/file"`)
+	assert.Contains(t, out, `gdu_device_free_bytes{root="`+abs+`",device="/dev/root"`)
+}
+
+func TestExportPrometheusToAtomicFile(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	path := filepath.Join(t.TempDir(), "gdu.prom")
+	err := os.WriteFile(path, []byte("old"), 0o600)
+	assert.Nil(t, err)
+
+	f, err := CreateAtomicFile(path)
+	assert.Nil(t, err)
+
+	output := bytes.NewBuffer(make([]byte, 10))
+	ui := CreateExportUI(output, f, false, false, false, false)
+	err = ui.SetFormat("prometheus")
+	assert.Nil(t, err)
+
+	content, _ := os.ReadFile(path)
+	assert.Equal(t, "old", string(content))
+
+	err = ui.AnalyzePath("test_dir", nil)
+	assert.Nil(t, err)
+
+	content, err = os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "# HELP gdu_directory_usage_bytes"))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestAtomicFileDiscard(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gdu.prom")
+
+	f, err := CreateAtomicFile(path)
+	assert.Nil(t, err)
+	_, err = f.WriteString("partial")
+	assert.Nil(t, err)
+
+	err = f.Discard()
+	assert.Nil(t, err)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestAtomicFileDiscardAfterClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gdu.prom")
+
+	f, err := CreateAtomicFile(path)
+	assert.Nil(t, err)
+	_, err = f.WriteString("complete")
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	assert.Nil(t, f.Discard())
+
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "complete", string(data))
+}
@@ -0,0 +1,276 @@
+package report
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	// SQLite driver written in pure Go
+	_ "modernc.org/sqlite"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+const sqliteSchema = `
+CREATE TABLE metadata (
+	key TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE items (
+	id INTEGER PRIMARY KEY,
+	parent_id INTEGER REFERENCES items(id),
+	name TEXT NOT NULL,
+	path TEXT NOT NULL,
+	is_dir INTEGER NOT NULL,
+	size INTEGER NOT NULL,
+	usage INTEGER NOT NULL,
+	item_count INTEGER NOT NULL,
+	mtime INTEGER,
+	flags TEXT,
+	inode INTEGER
+);
+CREATE INDEX items_parent_id ON items(parent_id);
+CREATE INDEX items_path ON items(path);
+CREATE INDEX items_size ON items(size);
+CREATE INDEX items_usage ON items(usage);
+`
+
+var sqliteMagic = []byte("SQLite format 3\x00")
+
+// IsSQLite returns true if the file at given path is SQLite database
+func IsSQLite(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	header := make([]byte, len(sqliteMagic))
+	if _, err := io.ReadFull(f, header); err != nil {
+		return false
+	}
+	return bytes.Equal(header, sqliteMagic)
+}
+
+// WriteSQLite writes given dir tree into new SQLite database created at path.
+// Metadata of the analysis are stored into the metadata table together with path of the exported tree.
+func WriteSQLite(path string, dir fs.Item, metadata *Metadata) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return fmt.Errorf("creating schema: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if metadata == nil {
+		metadata = &Metadata{}
+	}
+	meta := metadata.toKeyValues()
+	meta["root"] = dir.GetPath()
+	for k, v := range meta {
+		if _, err := tx.Exec("INSERT INTO metadata (key, value) VALUES (?, ?)", k, v); err != nil {
+			return err
+		}
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO items
+		(id, parent_id, name, path, is_dir, size, usage, item_count, mtime, flags, inode)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	var lastID int64
+	if err := insertSQLiteItem(stmt, dir, nil, &lastID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertSQLiteItem(stmt *sql.Stmt, item fs.Item, parentID any, lastID *int64) error {
+	*lastID++
+	id := *lastID
+
+	var mtime, flags, inode any
+	if !item.GetMtime().IsZero() {
+		mtime = item.GetMtime().Unix()
+	}
+	if f := item.GetFlag(); f != 0 && f != ' ' {
+		flags = string(f)
+	}
+	if item.GetMultiLinkedInode() > 0 {
+		inode = int64(item.GetMultiLinkedInode())
+	}
+
+	_, err := stmt.Exec(
+		id, parentID, item.GetName(), item.GetPath(), item.IsDir(),
+		item.GetSize(), item.GetUsage(), item.GetItemCount(), mtime, flags, inode,
+	)
+	if err != nil {
+		return err
+	}
+
+	if !item.IsDir() {
+		return nil
+	}
+
+	files := item.GetFiles()
+	sort.Sort(sort.Reverse(files))
+	for _, file := range files {
+		if err := insertSQLiteItem(stmt, file, id, lastID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadSQLite reads analysis from SQLite database created by WriteSQLite and returns directory item
+func ReadSQLite(path string) (*analyze.Dir, error) {
+	dir, _, err := ReadSQLiteWithMetadata(path)
+	return dir, err
+}
+
+// ReadSQLiteWithMetadata reads analysis from SQLite database created by WriteSQLite
+// and returns directory item together with metadata of the analysis
+func ReadSQLiteWithMetadata(path string) (*analyze.Dir, *Metadata, error) {
+	// do not let the driver create new empty database
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil, err
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer db.Close()
+
+	root, err := readSQLiteItems(db)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta, err := readSQLiteMetadata(db)
+	if err != nil {
+		return nil, nil, err
+	}
+	return root, meta, nil
+}
+
+func readSQLiteMetadata(db *sql.DB) (*Metadata, error) {
+	rows, err := db.Query("SELECT key, value FROM metadata")
+	if err != nil {
+		return nil, fmt.Errorf("reading metadata: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return metadataFromKeyValues(values), nil
+}
+
+func readSQLiteItems(db *sql.DB) (*analyze.Dir, error) {
+	rows, err := db.Query(`SELECT id, parent_id, name, path, is_dir, size, usage, mtime, flags, inode
+		FROM items ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("reading items: %w", err)
+	}
+	defer rows.Close()
+
+	var root *analyze.Dir
+	dirs := make(map[int64]*analyze.Dir)
+
+	for rows.Next() {
+		var (
+			id, size, usage int64
+			parentID        sql.NullInt64
+			mtime, inode    sql.NullInt64
+			flags           sql.NullString
+			name, itemPath  string
+			isDir           bool
+		)
+		if err := rows.Scan(&id, &parentID, &name, &itemPath, &isDir, &size, &usage, &mtime, &flags, &inode); err != nil {
+			return nil, err
+		}
+
+		file := &analyze.File{
+			Name:  name,
+			Size:  size,
+			Usage: usage,
+			Flag:  ' ',
+		}
+		if mtime.Valid {
+			file.Mtime = time.Unix(mtime.Int64, 0)
+		}
+		if flags.Valid && flags.String != "" {
+			file.Flag = []rune(flags.String)[0]
+		}
+		if inode.Valid {
+			file.Mli = uint64(inode.Int64)
+		}
+
+		var item fs.Item = file
+		if isDir {
+			dir := &analyze.Dir{
+				File:  file,
+				Files: make(fs.Files, 0),
+			}
+			dirs[id] = dir
+			item = dir
+		}
+
+		if !parentID.Valid {
+			if root != nil || !isDir {
+				return nil, errors.New("database must contain exactly one root directory")
+			}
+			root = dirs[id]
+			slashPos := strings.LastIndex(itemPath, "/")
+			if slashPos > -1 {
+				root.Name = itemPath[slashPos+1:]
+				root.BasePath = itemPath[:slashPos+1]
+			}
+			continue
+		}
+
+		parent, ok := dirs[parentID.Int64]
+		if !ok {
+			return nil, fmt.Errorf("parent of item %d not found", id)
+		}
+		file.Parent = parent
+		parent.AddFile(item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if root == nil {
+		return nil, errors.New("database does not contain any items")
+	}
+	return root, nil
+}
@@ -0,0 +1,159 @@
+package report
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/device"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func TestWriteAndReadSQLite(t *testing.T) {
+	dir := &analyze.Dir{
+		File: &analyze.File{
+			Name:  "xxx",
+			Size:  5,
+			Usage: 12,
+			Mtime: time.Date(2021, 8, 19, 0, 40, 0, 0, time.UTC),
+			Flag:  ' ',
+		},
+		BasePath:  "/srv",
+		ItemCount: 3,
+	}
+	subdir := &analyze.Dir{
+		File: &analyze.File{
+			Name:   "sub",
+			Size:   4,
+			Usage:  8,
+			Flag:   '!',
+			Parent: dir,
+		},
+		ItemCount: 2,
+	}
+	file := &analyze.File{
+		Name:   "file",
+		Size:   1,
+		Usage:  4,
+		Flag:   'H',
+		Mli:    1234,
+		Parent: subdir,
+	}
+	subdir.Files = fs.Files{file}
+	dir.Files = fs.Files{subdir}
+
+	path := filepath.Join(t.TempDir(), "gdu.sqlite")
+	meta := &Metadata{
+		Hostname:   "host",
+		Root:       "/srv",
+		Options:    map[string]string{"no-hidden": "true"},
+		Timestamp:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Duration:   1500 * time.Millisecond,
+		ErrorCount: 2,
+		Device:     &device.Device{Name: "/dev/sda1", MountPoint: "/srv", Size: 1000, Free: 100},
+	}
+	err := WriteSQLite(path, dir, meta)
+	assert.Nil(t, err)
+	assert.True(t, IsSQLite(path))
+
+	db, err := sql.Open("sqlite", path)
+	assert.Nil(t, err)
+	var value string
+	err = db.QueryRow("SELECT value FROM metadata WHERE key = 'no-hidden'").Scan(&value)
+	assert.Nil(t, err)
+	assert.Equal(t, "true", value)
+	err = db.QueryRow("SELECT value FROM metadata WHERE key = 'root'").Scan(&value)
+	assert.Nil(t, err)
+	assert.Equal(t, "/srv/xxx", value)
+	var count int
+	err = db.QueryRow("SELECT COUNT(*) FROM items WHERE path LIKE '/srv/xxx/sub%' AND is_dir = 0").Scan(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	assert.Nil(t, db.Close())
+
+	read, readMeta, err := ReadSQLiteWithMetadata(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "/srv/xxx", read.GetPath())
+	assert.Equal(t, "host", readMeta.Hostname)
+	assert.Equal(t, "/srv", readMeta.Root)
+	assert.Equal(t, map[string]string{"no-hidden": "true"}, readMeta.Options)
+	assert.Equal(t, meta.Timestamp.Unix(), readMeta.Timestamp.Unix())
+	assert.Equal(t, meta.Duration, readMeta.Duration)
+	assert.Equal(t, 2, readMeta.ErrorCount)
+	assert.Equal(t, *meta.Device, *readMeta.Device)
+	assert.Equal(t, 2021, read.GetMtime().Year())
+
+	readSubdir := read.Files[0].(*analyze.Dir)
+	assert.Equal(t, "/srv/xxx/sub", readSubdir.GetPath())
+	assert.Equal(t, '!', readSubdir.GetFlag())
+
+	readFile := readSubdir.Files[0].(*analyze.File)
+	assert.Equal(t, "/srv/xxx/sub/file", readFile.GetPath())
+	assert.Equal(t, int64(1), readFile.GetSize())
+	assert.Equal(t, int64(4), readFile.GetUsage())
+	assert.Equal(t, 'H', readFile.GetFlag())
+	assert.Equal(t, uint64(1234), readFile.GetMultiLinkedInode())
+	assert.True(t, readFile.GetMtime().IsZero())
+}
+
+func TestReadSQLiteNotExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.sqlite")
+
+	_, err := ReadSQLite(path)
+	assert.NotNil(t, err)
+	assert.False(t, IsSQLite(path))
+
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestReadSQLiteWithoutItems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.sqlite")
+	db, err := sql.Open("sqlite", path)
+	assert.Nil(t, err)
+	_, err = db.Exec(sqliteSchema)
+	assert.Nil(t, err)
+	assert.Nil(t, db.Close())
+
+	_, err = ReadSQLite(path)
+	assert.ErrorContains(t, err, "database does not contain any items")
+}
+
+func TestIsSQLiteWithJSON(t *testing.T) {
+	assert.False(t, IsSQLite("../internal/testdata/test.json"))
+}
+
+func TestExportSQLite(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	output := bytes.NewBuffer(make([]byte, 10))
+	reportOutput := bytes.NewBuffer(nil)
+
+	ui := CreateExportUI(output, reportOutput, false, false, false, false)
+	err := ui.SetFormat("sqlite")
+	assert.Nil(t, err)
+	ui.SetMetadata(NewMetadata("parallel", map[string]string{"no-hidden": "true"}))
+	err = ui.AnalyzePath("test_dir", nil)
+	assert.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "gdu.sqlite")
+	err = os.WriteFile(path, reportOutput.Bytes(), 0o600)
+	assert.Nil(t, err)
+
+	dir, meta, err := ReadSQLiteWithMetadata(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "test_dir", dir.GetName())
+	assert.Equal(t, "parallel", meta.Analyzer)
+	assert.Equal(t, "test_dir", meta.Root)
+	assert.Equal(t, "true", meta.Options["no-hidden"])
+	assert.Equal(t, "nested", dir.Files[0].GetName())
+	assert.Equal(t, 2, len(dir.Files[0].GetFiles()))
+}
@@ -0,0 +1,55 @@
+package stdout
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/diff"
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/stretchr/testify/assert"
+)
+
+func createDiff() *diff.Item {
+	old := &analyze.Dir{File: &analyze.File{Name: "old"}, BasePath: "/mnt"}
+	old.AddFile(&analyze.File{Name: "shrunk", Size: 3000, Usage: 4096, Parent: old})
+	old.AddFile(&analyze.File{Name: "same", Size: 10, Usage: 4096, Parent: old})
+	old.UpdateStats(make(fs.HardLinkedItems))
+
+	new := &analyze.Dir{File: &analyze.File{Name: "new"}, BasePath: "/mnt"}
+	sub := &analyze.Dir{File: &analyze.File{Name: "sub", Parent: new}}
+	sub.AddFile(&analyze.File{Name: "file", Size: 10000, Usage: 12288, Parent: sub})
+	new.AddFile(sub)
+	new.AddFile(&analyze.File{Name: "same", Size: 10, Usage: 4096, Parent: new})
+	new.UpdateStats(make(fs.HardLinkedItems))
+
+	return diff.Compare(old, new, false)
+}
+
+func TestShowDiff(t *testing.T) {
+	output := bytes.NewBuffer(nil)
+
+	ui := CreateStdoutUI(output, false, false, false, false, false, false, false, false, "", 0, false)
+	err := ui.ShowDiff(createDiff())
+	assert.Nil(t, err)
+
+	assert.Equal(t, ""+
+		" +16.0 KiB added     /mnt/new/sub/\n"+
+		"  -4.0 KiB removed   /mnt/new/shrunk\n"+
+		" +12.0 KiB total     /mnt/new (12.0 KiB -> 24.0 KiB)\n",
+		output.String(),
+	)
+}
+
+func TestShowDiffTopWithApparentSize(t *testing.T) {
+	output := bytes.NewBuffer(nil)
+
+	ui := CreateStdoutUI(output, true, false, true, false, false, false, false, true, "", 1, false)
+	err := ui.ShowDiff(createDiff())
+	assert.Nil(t, err)
+
+	assert.Contains(t, output.String(), "14096")
+	assert.Contains(t, output.String(), "/mnt/new/sub/")
+	assert.NotContains(t, output.String(), "shrunk")
+	assert.Contains(t, output.String(), "(7106 -> 18202)")
+}
@@ -12,7 +12,9 @@ import (
 	"github.com/dundee/gdu/v5/internal/common"
 	"github.com/dundee/gdu/v5/pkg/analyze"
 	"github.com/dundee/gdu/v5/pkg/device"
+	"github.com/dundee/gdu/v5/pkg/diff"
 	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/theme"
 	"github.com/dundee/gdu/v5/report"
 	"github.com/fatih/color"
 )
@@ -24,14 +26,19 @@ type UI struct {
 	red         *color.Color
 	orange      *color.Color
 	blue        *color.Color
+	green       *color.Color
 	top         int
 	summarize   bool
 	noPrefix    bool
 	fixedBase   float64
 	fixedSuffix string
 	reverseSort bool
+	theme       *theme.Theme
+	sizePadding int
 }
 
+const defaultDiffTop = 20
+
 var (
 	progressRunes      = []rune(`⠇⠏⠋⠙⠹⠸⠼⠴⠦⠧`)
 	progressRunesOld   = []rune(`-\\|/`)
@@ -75,6 +82,7 @@ func CreateStdoutUI(
 	ui.red = color.New(color.FgRed).Add(color.Bold)
 	ui.orange = color.New(color.FgYellow).Add(color.Bold)
 	ui.blue = color.New(color.FgBlue).Add(color.Bold)
+	ui.green = color.New(color.FgGreen).Add(color.Bold)
 
 	if !useColors {
 		color.NoColor = true
@@ -131,7 +139,7 @@ func (ui *UI) ListDevices(getter device.DevicesInfoGetter) error {
 
 	var sizeLength, percentLength int
 	if ui.UseColors {
-		sizeLength = 20
+		sizeLength = 20 + ui.sizePadding
 		percentLength = 16
 	} else {
 		sizeLength = 9
@@ -220,11 +228,19 @@ func (ui *UI) ReadFromStorage(storagePath, path string) error {
 	closeFn := storage.Open()
 	defer closeFn()
 
+	if err := storage.SelectScan(path); err != nil {
+		return err
+	}
 	dir, err := storage.GetDirForPath(path)
 	if err != nil {
 		return err
 	}
 
+	return ui.ShowAnalysis(dir)
+}
+
+// ShowAnalysis prints already loaded analysis
+func (ui *UI) ShowAnalysis(dir fs.Item) error {
 	switch {
 	case ui.top > 0:
 		ui.printTopFiles(dir)
@@ -258,7 +274,7 @@ func (ui *UI) printTopFiles(file fs.Item) {
 func (ui *UI) printTotalItem(file fs.Item) {
 	var lineFormat string
 	if ui.UseColors {
-		lineFormat = "%20s %s\n"
+		lineFormat = fmt.Sprintf("%%%ds %%s\n", 20+ui.sizePadding)
 	} else {
 		lineFormat = "%9s %s\n"
 	}
@@ -281,7 +297,7 @@ func (ui *UI) printTotalItem(file fs.Item) {
 func (ui *UI) printItem(file fs.Item) {
 	var lineFormat string
 	if ui.UseColors {
-		lineFormat = "%s %20s %s\n"
+		lineFormat = fmt.Sprintf("%%s %%%ds %%s\n", 20+ui.sizePadding)
 	} else {
 		lineFormat = "%s %9s %s\n"
 	}
@@ -293,25 +309,28 @@ func (ui *UI) printItem(file fs.Item) {
 		size = file.GetUsage()
 	}
 
+	name := file.GetName()
 	if file.IsDir() {
-		fmt.Fprintf(ui.output,
-			lineFormat,
-			string(file.GetFlag()),
-			ui.formatSize(size),
-			ui.blue.Sprint("/"+file.GetName()))
-	} else {
-		fmt.Fprintf(ui.output,
-			lineFormat,
-			string(file.GetFlag()),
-			ui.formatSize(size),
-			file.GetName())
+		name = "/" + name
+	}
+	switch ruleColor := ui.getRuleColor(file, size); {
+	case ruleColor != nil:
+		name = ruleColor.Sprint(name)
+	case file.IsDir():
+		name = ui.blue.Sprint(name)
 	}
+
+	fmt.Fprintf(ui.output,
+		lineFormat,
+		string(file.GetFlag()),
+		ui.formatSize(size),
+		name)
 }
 
 func (ui *UI) printItemPath(file fs.Item) {
 	var lineFormat string
 	if ui.UseColors {
-		lineFormat = "%20s %s\n"
+		lineFormat = fmt.Sprintf("%%%ds %%s\n", 20+ui.sizePadding)
 	} else {
 		lineFormat = "%9s %s\n"
 	}
@@ -323,10 +342,61 @@ func (ui *UI) printItemPath(file fs.Item) {
 		size = file.GetUsage()
 	}
 
+	path := file.GetPath()
+	if ruleColor := ui.getRuleColor(file, size); ruleColor != nil {
+		path = ruleColor.Sprint(path)
+	}
+
 	fmt.Fprintf(ui.output,
 		lineFormat,
 		ui.formatSize(size),
-		file.GetPath())
+		path)
+}
+
+// ShowDiff prints the biggest changes between two analyses followed by the total change.
+// Number of printed changes is given by top (20 by default).
+func (ui *UI) ShowDiff(root *diff.Item) error {
+	top := ui.top
+	if top <= 0 {
+		top = defaultDiffTop
+	}
+
+	for _, item := range diff.Top(root, top, ui.ShowApparentSize) {
+		path := item.GetPath()
+		if item.IsDir {
+			path = ui.blue.Sprint(path + "/")
+		}
+		ui.printDiffLine(item, item.Status.String(), path)
+	}
+
+	oldSize, newSize := root.OldUsage, root.NewUsage
+	if ui.ShowApparentSize {
+		oldSize, newSize = root.OldSize, root.NewSize
+	}
+	ui.printDiffLine(
+		root,
+		"total",
+		fmt.Sprintf("%s (%s -> %s)", root.GetPath(), ui.formatSize(oldSize), ui.formatSize(newSize)),
+	)
+	return nil
+}
+
+func (ui *UI) printDiffLine(item *diff.Item, status, path string) {
+	var lineFormat string
+	if ui.UseColors {
+		lineFormat = fmt.Sprintf("%%%ds %%-9s %%s\n", 32+ui.sizePadding)
+	} else {
+		lineFormat = "%10s %-9s %s\n"
+	}
+
+	delta := item.Delta(ui.ShowApparentSize)
+	sign := ui.red.Sprint("+")
+	if delta < 0 {
+		sign = ui.green.Sprint("-")
+		delta = -delta
+	}
+
+	fmt.Fprintf(ui.output, lineFormat, sign+ui.formatSize(delta), status, path)
 }
 
 // ReadAnalysis reads analysis report from JSON file
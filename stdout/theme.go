@@ -0,0 +1,58 @@
+package stdout
+
+import (
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/theme"
+)
+
+// SetTheme sets colours of sizes and directories from the theme,
+// names of items are coloured by size and age rules of the theme
+func (ui *UI) SetTheme(t *theme.Theme) {
+	ui.theme = t
+	if c := getColor(t.ResultRow.NumberColor); c != nil {
+		// sizes are aligned including escape sequences of their colour
+		ui.sizePadding = len(c.Sprint("")) - len(ui.orange.Sprint(""))
+		ui.orange = c
+	}
+	if c := getColor(t.ResultRow.DirectoryColor); c != nil {
+		ui.blue = c
+	}
+}
+
+// getRuleColor returns colour of the item given by size and age rules of the theme
+func (ui *UI) getRuleColor(file fs.Item, size int64) *color.Color {
+	if ui.theme == nil || !ui.theme.HasRules() {
+		return nil
+	}
+	return getColor(ui.theme.RuleColor(size, file.GetMtime(), time.Now()))
+}
+
+// getColor converts colour of the theme to bold terminal colour,
+// nil is returned for the default colour
+func getColor(name string) *color.Color {
+	if !theme.IsSet(name) {
+		return nil
+	}
+	c := tcell.GetColor(strings.ToLower(name))
+	if c == tcell.ColorDefault {
+		return nil
+	}
+	if !c.IsRGB() {
+		// keep basic colours of the palette so they follow the terminal's colour scheme
+		index := int(c - tcell.ColorValid)
+		switch {
+		case index < 8:
+			return color.New(color.Attribute(int(color.FgBlack)+index), color.Bold)
+		case index < 16:
+			return color.New(color.Attribute(int(color.FgHiBlack)+index-8), color.Bold)
+		}
+	}
+	r, g, b := c.RGB()
+	return color.RGB(int(r), int(g), int(b)).Add(color.Bold)
+}
@@ -0,0 +1,54 @@
+package stdout
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/theme"
+)
+
+func TestGetColor(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	assert.Nil(t, getColor(""))
+	assert.Nil(t, getColor("default"))
+	assert.True(t, strings.HasPrefix(getColor("maroon").Sprint("x"), "\x1b[31;1mx"))
+	assert.True(t, strings.HasPrefix(getColor("red").Sprint("x"), "\x1b[91;1mx"))
+	assert.True(t, strings.HasPrefix(getColor("#fb4934").Sprint("x"), "\x1b[38;2;251;73;52;1mx"))
+}
+
+func TestPrintItemWithTheme(t *testing.T) {
+	output := &bytes.Buffer{}
+	ui := CreateStdoutUI(output, true, false, false, false, false, false, false, false, "", 0, false)
+
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	th, err := theme.Get("gruvbox", nil)
+	assert.Nil(t, err)
+	ui.SetTheme(th)
+
+	now := time.Now()
+	dir := &analyze.Dir{File: &analyze.File{Name: "dir", Usage: 20 << 30, Mtime: now, Flag: ' '}}
+	file := &analyze.File{Name: "small", Usage: 1 << 10, Mtime: now, Flag: ' '}
+	old := &analyze.File{Name: "old", Usage: 1 << 10, Mtime: now.AddDate(-2, 0, 0), Flag: ' '}
+
+	ui.printItem(dir)
+	ui.printItem(file)
+	ui.printItem(old)
+	lines := strings.Split(output.String(), "\n")
+
+	number, big, gray := getColor("#fe8019"), getColor("#fb4934"), getColor("#928374")
+	assert.Equal(t, "  "+number.Sprint("20.0")+" GiB "+big.Sprint("/dir"), lines[0])
+	assert.Equal(t, "  "+number.Sprint("1.0")+" KiB small", lines[1])
+	assert.Equal(t, "  "+number.Sprint("1.0")+" KiB "+gray.Sprint("old"), lines[2])
+}
@@ -1,14 +1,11 @@
 package tui
 
 import (
-	"bytes"
 	"fmt"
 	"io"
-	"os"
 	"os/exec"
 	"runtime"
 	"runtime/debug"
-	"strconv"
 	"strings"
 	"time"
 
@@ -31,6 +28,7 @@ const (
 
 	actionEmpty  = "empty"
 	actionDelete = "delete"
+	actionTrash  = "move to trash"
 
 	actingEmpty  = "emptying"
 	actingDelete = "deleting"
@@ -69,6 +67,8 @@ func (ui *UI) AnalyzePath(path string, parentDir fs.Item) error {
 
 	go ui.updateProgress()
 
+	start := time.Now()
+
 	go func() {
 		defer debug.FreeOSMemory()
 		currentDir := ui.Analyzer.AnalyzeDir(path, ui.CreateIgnoreFunc(), ui.ConstGC)
@@ -84,6 +84,10 @@ func (ui *UI) AnalyzePath(path string, parentDir fs.Item) error {
 
 		ui.topDir.UpdateStats(ui.linkedItems)
 
+		if parentDir == nil && ui.metadata != nil {
+			ui.metadata.Finish(currentDir, start, ui.getDevice(path))
+		}
+
 		ui.app.QueueUpdateDraw(func() {
 			ui.currentDir = currentDir
 			ui.showDir()
@@ -117,7 +121,7 @@ func (ui *UI) ReadAnalysis(input io.Reader) error {
 
 	go func() {
 		var err error
-		ui.currentDir, err = report.ReadAnalysis(input)
+		ui.currentDir, ui.metadata, err = report.ReadAnalysisWithMetadata(input)
 		if err != nil {
 			ui.app.QueueUpdateDraw(func() {
 				ui.pages.RemovePage("progress")
@@ -137,6 +141,7 @@ func (ui *UI) ReadAnalysis(input io.Reader) error {
 		ui.topDir.UpdateStats(links)
 
 		ui.app.QueueUpdateDraw(func() {
+			ui.showMetadataHeader()
 			ui.showDir()
 			ui.pages.RemovePage("progress")
 		})
@@ -155,15 +160,24 @@ func (ui *UI) ReadFromStorage(storagePath, path string) error {
 	closeFn := storage.Open()
 	defer closeFn()
 
+	if err := storage.SelectScan(path); err != nil {
+		return err
+	}
 	dir, err := storage.GetDirForPath(path)
 	if err != nil {
 		return err
 	}
 
+	return ui.ShowAnalysis(dir)
+}
+
+// ShowAnalysis shows already loaded analysis
+func (ui *UI) ShowAnalysis(dir fs.Item) error {
 	ui.currentDir = dir
 	ui.topDirPath = ui.currentDir.GetPath()
 	ui.topDir = ui.currentDir
 
+	ui.showMetadataHeader()
 	ui.showDir()
 	return nil
 }
@@ -199,6 +213,7 @@ func (ui *UI) deleteSelected(shouldEmpty bool) {
 			tview.Escape(selectedItem.GetName()) +
 			"...",
 	)
+	ui.styleModal(modal, tview.Styles.ContrastBackgroundColor)
 	ui.pages.AddPage(acting, modal, true, true)
 
 	var currentDir fs.Item
@@ -209,7 +224,7 @@ func (ui *UI) deleteSelected(shouldEmpty bool) {
 			deleteItems = append(deleteItems, file)
 		}
 	} else {
-		currentDir = ui.currentDir
+		currentDir = ui.getParentDir(selectedItem)
 		deleteItems = append(deleteItems, selectedItem)
 	}
 
@@ -333,82 +348,6 @@ func (ui *UI) openItem() {
 	}
 }
 
-func (ui *UI) confirmExport() *tview.Form {
-	form := tview.NewForm().
-		AddInputField("File name", "export.json", 30, nil, func(v string) {
-			ui.exportName = v
-		}).
-		AddButton("Export", ui.exportAnalysis).
-		SetButtonsAlign(tview.AlignCenter)
-	form.SetBorder(true).
-		SetTitle(" Export data to JSON ").
-		SetInputCapture(func(key *tcell.EventKey) *tcell.EventKey {
-			if key.Key() == tcell.KeyEsc {
-				ui.pages.RemovePage("export")
-				ui.app.SetFocus(ui.table)
-				return nil
-			}
-			return key
-		})
-	flex := modal(form, 50, 7)
-	ui.pages.AddPage("export", flex, true, true)
-	ui.app.SetFocus(form)
-	return form
-}
-
-func (ui *UI) exportAnalysis() {
-	ui.pages.RemovePage("export")
-
-	text := tview.NewTextView().SetText("Export in progress...").SetTextAlign(tview.AlignCenter)
-	text.SetBorder(true).SetTitle(" Export data to JSON ")
-	flex := modal(text, 50, 3)
-	ui.pages.AddPage("exporting", flex, true, true)
-
-	go func() {
-		var err error
-		defer ui.app.QueueUpdateDraw(func() {
-			ui.pages.RemovePage("exporting")
-			if err == nil {
-				ui.app.SetFocus(ui.table)
-			}
-		})
-		if ui.done != nil {
-			defer func() {
-				ui.done <- struct{}{}
-			}()
-		}
-
-		var buff bytes.Buffer
-
-		buff.Write([]byte(`[1,2,{"progname":"gdu","progver":"`))
-		buff.Write([]byte(build.Version))
-		buff.Write([]byte(`","timestamp":`))
-		buff.Write([]byte(strconv.FormatInt(time.Now().Unix(), 10)))
-		buff.Write([]byte("},\n"))
-
-		file, err := os.Create(ui.exportName)
-		if err != nil {
-			ui.showErrFromGo("Error creating file", err)
-			return
-		}
-		defer file.Close()
-
-		if err = ui.topDir.EncodeJSON(&buff, true); err != nil {
-			ui.showErrFromGo("Error encoding JSON", err)
-			return
-		}
-
-		if _, err = buff.Write([]byte("]\n")); err != nil {
-			ui.showErrFromGo("Error writing to buffer", err)
-			return
-		}
-		if _, err = buff.WriteTo(file); err != nil {
-			ui.showErrFromGo("Error writing to file", err)
-			return
-		}
-	}()
-}
-
 func (ui *UI) isInArchive() bool {
 	if ui.currentDir == nil {
 		return false
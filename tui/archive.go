@@ -0,0 +1,176 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/archive"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// getDefaultArchivePath returns path of the archive next to the archived items
+func (ui *UI) getDefaultArchivePath(items []fs.Item, format string) string {
+	dir := ui.getParentDir(items[0]).GetPath()
+	name := filepath.Base(dir)
+	if len(items) == 1 {
+		name = items[0].GetName()
+	}
+	return filepath.Join(dir, name+"."+format)
+}
+
+func (ui *UI) showArchiveForm() {
+	if ui.currentDir == nil {
+		return
+	}
+	if ui.noDelete {
+		ui.showErr("Write operations are disabled", nil)
+		return
+	}
+	if ui.isInArchive() {
+		ui.showErr("Archiving is not supported in archives", nil)
+		return
+	}
+
+	items := ui.getSelectedOrMarkedItems()
+	if len(items) == 0 {
+		return
+	}
+
+	title := " Archive " + tview.Escape(items[0].GetName()) + " "
+	if len(items) > 1 {
+		title = fmt.Sprintf(" Archive %d marked items ", len(items))
+	}
+
+	format := archive.Formats[0]
+	removeOriginals := false
+	destination := tview.NewInputField().
+		SetLabel("Destination").
+		SetText(ui.getDefaultArchivePath(items, format)).
+		SetFieldWidth(60)
+
+	form := tview.NewForm().
+		AddDropDown("Format", archive.Formats, 0, func(option string, _ int) {
+			// keep extension of the destination in sync with the format
+			if path, ok := strings.CutSuffix(destination.GetText(), "."+format); ok {
+				destination.SetText(path + "." + option)
+			}
+			format = option
+		}).
+		AddFormItem(destination).
+		AddCheckbox("Remove originals", false, func(checked bool) {
+			removeOriginals = checked
+		})
+	form.AddButton("Create", func() {
+		if destination.GetText() == "" {
+			return
+		}
+		ui.closeArchiveForm()
+		ui.archiveItems(items, ui.resolvePath(destination.GetText()), format, removeOriginals)
+	}).
+		AddButton("Cancel", ui.closeArchiveForm).
+		SetButtonsAlign(tview.AlignCenter)
+	form.SetBorder(true).
+		SetTitle(title).
+		SetInputCapture(func(key *tcell.EventKey) *tcell.EventKey {
+			if key.Key() == tcell.KeyEsc {
+				ui.closeArchiveForm()
+				return nil
+			}
+			return key
+		})
+
+	ui.addInputPage("archive", modal(form, 80, 11))
+	ui.app.SetFocus(form)
+}
+
+func (ui *UI) closeArchiveForm() {
+	ui.pages.RemovePage("archive")
+	ui.app.SetFocus(ui.table)
+}
+
+// archiveItems packs items into archive in the background
+func (ui *UI) archiveItems(items []fs.Item, dest, format string, removeOriginals bool) {
+	parents := make([]fs.Item, 0, len(items))
+	paths := make([]string, 0, len(items))
+	var total int64
+	for _, item := range items {
+		parents = append(parents, ui.getParentDir(item))
+		paths = append(paths, item.GetPath())
+		total += item.GetSize()
+	}
+	ui.markedRows = make(map[int]struct{})
+
+	name := filepath.Base(dest)
+	label := "Archiving " + name
+	ui.startJob(dest, label, total)
+
+	go func() {
+		err := createArchive(dest, format, paths, func(read int64) {
+			ui.setJobProgress(dest, read)
+		})
+		ui.finishJob(dest)
+		if err != nil {
+			ui.showErrFromGo("Can't create archive "+tview.Escape(name), err)
+			if ui.done != nil {
+				ui.done <- struct{}{}
+			}
+			return
+		}
+
+		if removeOriginals {
+			for i, item := range items {
+				if err := ui.remover(parents[i], item); err != nil {
+					ui.showErrFromGo("Can't delete "+tview.Escape(item.GetName()), err)
+					break
+				}
+			}
+		}
+		ui.addArchiveToTree(dest)
+
+		ui.app.QueueUpdateDraw(func() {
+			row, _ := ui.table.GetSelection()
+			ui.showDir()
+			ui.table.Select(min(row, ui.table.GetRowCount()-1), 0)
+		})
+		if ui.done != nil {
+			ui.done <- struct{}{}
+		}
+	}()
+}
+
+// createArchive creates the archive and verifies it, broken archive is removed
+func createArchive(dest, format string, paths []string, progress func(int64)) error {
+	entries, err := archive.Create(dest, format, paths, progress)
+	if err != nil {
+		return err
+	}
+
+	verified, err := archive.Verify(dest, format)
+	if err == nil && verified != entries {
+		err = fmt.Errorf("archive contains %d entries instead of %d", verified, entries)
+	}
+	if err != nil {
+		os.Remove(dest)
+		return fmt.Errorf("verification failed: %w", err)
+	}
+	return nil
+}
+
+// addArchiveToTree adds the created archive to the analyzed tree if it's part of it
+func (ui *UI) addArchiveToTree(path string) {
+	dir, ok := ui.findItemByPath(filepath.Dir(path)).(*analyze.Dir)
+	if !ok {
+		return
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return
+	}
+	dir.AddFileWithStats(analyze.NewFile(info, dir))
+}
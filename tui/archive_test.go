@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/archive"
+)
+
+func TestArchiveSelected(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	size := ui.topDir.GetSize()
+
+	ui.table.Select(0, 0)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'z', 0))
+	assert.True(t, ui.pages.HasPage("archive"))
+
+	// keys are handled by the form
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'q', 0))
+	assert.True(t, ui.pages.HasPage("archive"))
+	ui.closeArchiveForm()
+
+	items := ui.getSelectedOrMarkedItems()
+	assert.Equal(t, filepath.Join(ui.topDirPath, "nested.tar.gz"), ui.getDefaultArchivePath(items, archive.FormatTarGz))
+
+	ui.archiveItems(items, filepath.Join(ui.topDirPath, "nested.zip"), archive.FormatZip, false)
+	<-ui.done
+	drawUpdates(ui)
+
+	assert.FileExists(t, "test_dir/nested.zip")
+	assert.DirExists(t, "test_dir/nested")
+	_, ok := ui.topDir.GetFiles().FindByName("nested.zip")
+	assert.True(t, ok)
+	assert.Greater(t, ui.topDir.GetSize(), size)
+	assert.Equal(t, 2, ui.table.GetRowCount())
+}
+
+func TestArchiveMarkedAndRemoveOriginals(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	assert.True(t, ui.openPath(filepath.Join(ui.topDirPath, "nested")))
+	ui.markedRows[1] = struct{}{}
+	ui.markedRows[2] = struct{}{}
+
+	items := ui.getSelectedOrMarkedItems()
+	assert.Len(t, items, 2)
+	dest := ui.getDefaultArchivePath(items, archive.FormatTarXz)
+	assert.Equal(t, filepath.Join(ui.topDirPath, "nested", "nested.tar.xz"), dest)
+
+	ui.archiveItems(items, dest, archive.FormatTarXz, true)
+	assert.Empty(t, ui.markedRows)
+	<-ui.done
+	drawUpdates(ui)
+
+	assert.FileExists(t, "test_dir/nested/nested.tar.xz")
+	assert.NoDirExists(t, "test_dir/nested/subnested")
+	assert.NoFileExists(t, "test_dir/nested/file2")
+	assert.Len(t, ui.currentDir.GetFiles(), 1)
+	assert.Equal(t, "nested.tar.xz", ui.currentDir.GetFiles()[0].GetName())
+}
+
+func TestArchiveError(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.table.Select(0, 0)
+
+	// archive can't be created inside of itself
+	ui.archiveItems(ui.getSelectedOrMarkedItems(), filepath.Join(ui.topDirPath, "nested", "nested.zip"), archive.FormatZip, true)
+	<-ui.done
+	drawUpdates(ui)
+
+	assert.True(t, ui.pages.HasPage("error"))
+	assert.DirExists(t, "test_dir/nested")
+	assert.NoFileExists(t, "test_dir/nested/nested.zip")
+	assert.Empty(t, ui.jobs)
+}
+
+func TestArchiveWithNoDelete(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.SetNoDelete()
+	ui.table.Select(0, 0)
+
+	ui.showArchiveForm()
+	assert.False(t, ui.pages.HasPage("archive"))
+	assert.True(t, ui.pages.HasPage("error"))
+}
+
+func TestJobsStatus(t *testing.T) {
+	ui := &UI{jobs: map[string]*backgroundJob{
+		"/a/b.zip":    {label: "Archiving b.zip", done: 50, total: 200},
+		"/a/a.tar.gz": {label: "Archiving a.tar.gz", done: 10, total: 0},
+		"custom-1":    {label: "Running du", total: -1},
+	}}
+	assert.Equal(t, "Archiving a.tar.gz 100%, Archiving b.zip 25%, Running du", ui.getJobsStatus())
+}
@@ -55,7 +55,7 @@ func (ui *UI) deleteItem(item fs.Item, shouldEmpty bool) {
 			deleteItems = append(deleteItems, file)
 		}
 	} else {
-		parentDir = ui.currentDir
+		parentDir = ui.getParentDir(item)
 		deleteItems = append(deleteItems, item)
 	}
 
@@ -73,7 +73,7 @@ func (ui *UI) deleteItem(item fs.Item, shouldEmpty bool) {
 		}
 	}
 
-	if item.GetParent().GetPath() == ui.currentDir.GetPath() {
+	if ui.treeView || item.GetParent().GetPath() == ui.currentDir.GetPath() {
 		ui.app.QueueUpdateDraw(func() {
 			row, _ := ui.table.GetSelection()
 			x, y := ui.table.GetOffset()
@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// SetBookmarks sets named bookmarks of directories and function saving them when changed
+func (ui *UI) SetBookmarks(bookmarks map[string]string, save func(map[string]string) error) {
+	ui.bookmarks = make(map[string]string, len(bookmarks))
+	for name, path := range bookmarks {
+		ui.bookmarks[name] = path
+	}
+	ui.saveBookmarks = save
+}
+
+func (ui *UI) getBookmarkNames() []string {
+	names := make([]string, 0, len(ui.bookmarks))
+	for name := range ui.bookmarks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (ui *UI) showBookmarks() {
+	if ui.currentDir == nil {
+		return
+	}
+
+	table := tview.NewTable().SetSelectable(true, false)
+	table.SetBackgroundColor(tcell.ColorDefault)
+	table.SetSelectedStyle(ui.getSelectedStyle())
+	table.SetBorder(true).
+		SetTitle(" Bookmarks (" + formatKeyHints(
+			keyHint{"Enter", "goes to"},
+			keyHint{"a", "adds current directory"},
+			keyHint{ui.keymap.keyName(keyActionDelete), "deletes"},
+			keyHint{"Esc", "closes"},
+		) + ") ")
+
+	names := ui.getBookmarkNames()
+	if len(names) == 0 {
+		table.SetCell(0, 0, tview.NewTableCell(" No bookmarks yet").SetSelectable(false))
+	}
+	for row, name := range names {
+		table.SetCell(row, 0, tview.NewTableCell(" [::b]"+tview.Escape(name)+"[::-] ").SetReference(name))
+		table.SetCell(row, 1, tview.NewTableCell(tview.Escape(ui.bookmarks[name])))
+	}
+
+	table.SetSelectedFunc(func(row, column int) {
+		name, ok := table.GetCell(row, 0).GetReference().(string)
+		if !ok {
+			return
+		}
+		ui.closeBookmarks()
+		ui.goToBookmark(name)
+	})
+	table.SetInputCapture(func(key *tcell.EventKey) *tcell.EventKey {
+		action := ui.keymap.action(key)
+		switch {
+		case key.Key() == tcell.KeyEsc || action == keyActionQuit:
+			ui.closeBookmarks()
+			return nil
+		case key.Rune() == 'a':
+			ui.showAddBookmark()
+			return nil
+		case action == keyActionDelete:
+			row, _ := table.GetSelection()
+			if name, ok := table.GetCell(row, 0).GetReference().(string); ok {
+				ui.deleteBookmark(name)
+				ui.closeBookmarks()
+				ui.showBookmarks()
+			}
+			return nil
+		}
+		return key
+	})
+
+	ui.addInputPage("bookmarks", modal(table, 80, 15))
+	ui.app.SetFocus(table)
+}
+
+func (ui *UI) closeBookmarks() {
+	ui.pages.RemovePage("bookmarks")
+	ui.app.SetFocus(ui.table)
+}
+
+func (ui *UI) showAddBookmark() {
+	form := tview.NewForm()
+	form.AddInputField("Name", filepath.Base(ui.currentDirPath), 30, nil, nil).
+		AddButton("Add", func() {
+			name := form.GetFormItemByLabel("Name").(*tview.InputField).GetText()
+			if name == "" {
+				return
+			}
+			ui.pages.RemovePage("addbookmark")
+			ui.addBookmark(name, ui.currentDirPath)
+			ui.closeBookmarks()
+			ui.showBookmarks()
+		}).
+		SetButtonsAlign(tview.AlignCenter)
+	form.SetBorder(true).
+		SetTitle(" Add bookmark of " + tview.Escape(ui.currentDirPath) + " ").
+		SetInputCapture(func(key *tcell.EventKey) *tcell.EventKey {
+			if key.Key() == tcell.KeyEsc {
+				ui.pages.RemovePage("addbookmark")
+				ui.closeBookmarks()
+				ui.showBookmarks()
+				return nil
+			}
+			return key
+		})
+
+	ui.addInputPage("addbookmark", modal(form, 60, 7))
+	ui.app.SetFocus(form)
+}
+
+func (ui *UI) addBookmark(name, path string) {
+	ui.bookmarks[name] = path
+	ui.storeBookmarks()
+}
+
+func (ui *UI) deleteBookmark(name string) {
+	delete(ui.bookmarks, name)
+	ui.storeBookmarks()
+}
+
+func (ui *UI) storeBookmarks() {
+	if ui.saveBookmarks == nil {
+		return
+	}
+	if err := ui.saveBookmarks(ui.bookmarks); err != nil {
+		ui.showErr("Error saving bookmarks", err)
+	}
+}
+
+func (ui *UI) goToBookmark(name string) {
+	path, ok := ui.bookmarks[name]
+	if !ok {
+		return
+	}
+	if !ui.openPath(ui.resolvePath(path)) {
+		ui.showErr(fmt.Sprintf("Bookmarked path %s is not in the analyzed tree", path), nil)
+	}
+}
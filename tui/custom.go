@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// customActionRuns numbers runs of custom actions so each of them has its own job
+var customActionRuns atomic.Int64
+
+func (ui *UI) handleCustomActions(key *tcell.EventKey) *tcell.EventKey {
+	action := ui.keymap.customAction(key)
+	if action == nil {
+		return key
+	}
+	ui.runCustomAction(action)
+	return nil
+}
+
+func (ui *UI) runCustomAction(action *CustomAction) {
+	if ui.currentDir == nil {
+		return
+	}
+	if ui.isInArchive() {
+		ui.showErr("Custom actions are not supported in archives", nil)
+		return
+	}
+	if ui.noSpawnShell {
+		ui.showHeaderFeedback(" Custom actions are disabled!")
+		return
+	}
+
+	// the current directory is used when the cursor is on the parent directory
+	var selected fs.Item = ui.currentDir
+	row, column := ui.table.GetSelection()
+	if item, ok := ui.table.GetCell(row, column).GetReference().(fs.Item); ok && item != ui.currentDir.GetParent() {
+		selected = item
+	}
+	items := ui.getSelectedOrMarkedItems()
+	if len(items) == 0 {
+		items = []fs.Item{selected}
+	}
+	command := expandCommand(action.Command, selected, ui.currentDirPath, items)
+
+	if action.Foreground {
+		if err := ui.runInForeground(command, ui.currentDirPath); err != nil {
+			ui.showErr("Error running "+tview.Escape(action.Label), err)
+			return
+		}
+		if action.Rescan {
+			ui.rescanDir()
+		}
+		return
+	}
+	ui.runInBackground(action, command, ui.currentDirPath)
+}
+
+// expandCommand replaces placeholders in the command by quoted values
+func expandCommand(command string, selected fs.Item, dir string, items []fs.Item) string {
+	paths := make([]string, 0, len(items))
+	for _, item := range items {
+		paths = append(paths, quoteShellArg(item.GetPath()))
+	}
+	return strings.NewReplacer(
+		"{path}", quoteShellArg(selected.GetPath()),
+		"{name}", quoteShellArg(selected.GetName()),
+		"{dir}", quoteShellArg(dir),
+		"{marked}", strings.Join(paths, " "),
+	).Replace(command)
+}
+
+// runInBackground runs the command in the given directory and shows its output when it finishes
+func (ui *UI) runInBackground(action *CustomAction, command, dir string) {
+	jobKey := fmt.Sprintf("custom-action-%d", customActionRuns.Add(1))
+	ui.startJob(jobKey, "Running "+action.Label, -1)
+
+	go func() {
+		cmd := newShellCommand(command)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		ui.finishJob(jobKey)
+
+		ui.app.QueueUpdateDraw(func() {
+			ui.showCustomActionOutput(action, dir, string(output), err)
+		})
+		if ui.done != nil {
+			ui.done <- struct{}{}
+		}
+	}()
+}
+
+func (ui *UI) showCustomActionOutput(action *CustomAction, dir, output string, err error) {
+	content := tview.Escape(output)
+	if strings.TrimSpace(output) == "" {
+		content = "Finished without output\n"
+	}
+	if err != nil {
+		content += "\n[::b]Error: " + tview.Escape(err.Error())
+	}
+
+	text := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(content)
+	text.SetBorder(true).
+		SetBorderPadding(0, 0, 1, 1).
+		SetTitle(" " + tview.Escape(action.Label) + " (Esc closes) ")
+	text.SetInputCapture(func(key *tcell.EventKey) *tcell.EventKey {
+		if key.Key() == tcell.KeyEsc || key.Key() == tcell.KeyEnter || ui.keymap.action(key) == keyActionQuit {
+			ui.closeCustomActionOutput(action, dir)
+			return nil
+		}
+		return key
+	})
+
+	ui.addInputPage("customoutput", modal(text, 100, 20))
+	ui.app.SetFocus(text)
+}
+
+func (ui *UI) closeCustomActionOutput(action *CustomAction, dir string) {
+	ui.pages.RemovePage("customoutput")
+	ui.app.SetFocus(ui.table)
+
+	if !action.Rescan {
+		return
+	}
+	item := ui.findItemByPath(dir)
+	if item == nil || !item.IsDir() {
+		return // directory is not part of the analyzed tree anymore
+	}
+	ui.Analyzer.ResetProgress()
+	ui.linkedItems = make(fs.HardLinkedItems)
+	if err := ui.AnalyzePath(item.GetPath(), item.GetParent()); err != nil {
+		ui.showErr("Error rescanning path", err)
+	}
+}
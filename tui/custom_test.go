@@ -0,0 +1,162 @@
+//go:build !windows
+
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func setCustomActions(t *testing.T, ui *UI, actions ...CustomAction) {
+	t.Helper()
+
+	keymap := DefaultKeymap()
+	assert.Nil(t, keymap.AddCustomActions(actions))
+	ui.SetKeymap(keymap)
+}
+
+func getCustomActionOutput(ui *UI) string {
+	page := ui.pages.GetPage("customoutput").(*tview.Flex)
+	column := page.GetItem(1).(*tview.Flex)
+	return column.GetItem(1).(*tview.TextView).GetText(true)
+}
+
+func TestExpandCommand(t *testing.T) {
+	dir := &analyze.Dir{File: &analyze.File{Name: "/tmp/a b"}}
+	file := &analyze.File{Name: "it's", Parent: dir}
+	other := &analyze.File{Name: "other", Parent: dir}
+
+	command := expandCommand("cmd {path} {name} {dir} {marked}", file, "/tmp/a b", []fs.Item{file, other})
+
+	assert.Equal(t, `cmd '/tmp/a b/it'\''s' 'it'\''s' '/tmp/a b' '/tmp/a b/it'\''s' '/tmp/a b/other'`, command)
+}
+
+func TestCustomActionInBackground(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	setCustomActions(t, ui, CustomAction{
+		Key:     "y",
+		Label:   "Create file",
+		Command: "touch created && echo {name}",
+		Rescan:  true,
+	})
+	ui.table.Select(0, 0)
+
+	key := ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'y', 0))
+	assert.Nil(t, key)
+	<-ui.done
+	drawUpdates(ui)
+
+	assert.FileExists(t, "test_dir/created")
+	assert.True(t, ui.pages.HasPage("customoutput"))
+	assert.Equal(t, "nested\n", getCustomActionOutput(ui))
+	assert.Empty(t, ui.jobs)
+
+	// keys are sent to the output modal
+	assert.NotNil(t, ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'd', 0)))
+	assert.False(t, ui.pages.HasPage("confirm"))
+
+	// directory is rescanned after the output is closed
+	assert.Nil(t, ui.findItemByPath(filepath.Join(ui.topDirPath, "created")))
+	ui.closeCustomActionOutput(ui.keymap.custom[0], ui.topDirPath)
+	<-ui.done
+	drawUpdates(ui)
+
+	assert.False(t, ui.pages.HasPage("customoutput"))
+	assert.NotNil(t, ui.findItemByPath(filepath.Join(ui.topDirPath, "created")))
+}
+
+func TestCustomActionWithError(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	setCustomActions(t, ui, CustomAction{Key: "y", Command: "exit 3"})
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'y', 0))
+	<-ui.done
+	drawUpdates(ui)
+
+	assert.Equal(t, "Finished without output\n\nError: exit status 3", getCustomActionOutput(ui))
+}
+
+func TestCustomActionOnMarkedItems(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	setCustomActions(t, ui, CustomAction{Key: "ctrl+y", Label: "List", Command: "ls -d {marked}"})
+	ui.fileItemSelected(0, 0) // go to nested dir
+	ui.markedRows[1] = struct{}{}
+	ui.markedRows[2] = struct{}{}
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyCtrlY, 0, tcell.ModCtrl))
+	<-ui.done
+	drawUpdates(ui)
+
+	nested := filepath.Join(ui.topDirPath, "nested")
+	assert.Equal(t,
+		filepath.Join(nested, "file2")+"\n"+filepath.Join(nested, "subnested")+"\n",
+		getCustomActionOutput(ui),
+	)
+}
+
+func TestCustomActionInForeground(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	defer os.Chdir(wd)
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	setCustomActions(t, ui, CustomAction{
+		Key:        "y",
+		Command:    "ranger {path}",
+		Foreground: true,
+		Rescan:     true,
+	})
+	var args []string
+	ui.exec = func(argv0 string, argv, envv []string) error {
+		args = append([]string{argv0}, argv...)
+		return nil
+	}
+	ui.table.Select(0, 0)
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'y', 0))
+	<-ui.done // rescan
+	drawUpdates(ui)
+
+	assert.Equal(t, []string{"/bin/sh", "-c", "ranger '" + filepath.Join(ui.topDirPath, "nested") + "'"}, args)
+	assert.Equal(t, "nested", getSelectedName(ui))
+}
+
+func TestCustomActionWithNoSpawnShell(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.SetNoSpawnShell()
+	setCustomActions(t, ui, CustomAction{Key: "y", Label: "Run", Command: "true", Foreground: true})
+	called := false
+	ui.exec = func(argv0 string, argv, envv []string) error {
+		called = true
+		return nil
+	}
+
+	assert.Nil(t, ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'y', 0)))
+	assert.False(t, called)
+	assert.Contains(t, ui.header.GetText(false), "Custom actions are disabled!")
+	assert.Contains(t, ui.formatHelpTextFor(), "Run (disabled)")
+}
@@ -0,0 +1,203 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/dundee/gdu/v5/pkg/diff"
+)
+
+// ShowDiff shows browsable tree of differences between two analyses
+func (ui *UI) ShowDiff(root *diff.Item) error {
+	ui.diffTable = tview.NewTable().SetSelectable(true, false)
+	ui.diffTable.SetBackgroundColor(tcell.ColorDefault)
+	ui.diffTable.SetSelectedStyle(ui.getSelectedStyle())
+	ui.diffTable.SetSelectedFunc(ui.diffItemSelected)
+	ui.diffTable.SetInputCapture(ui.diffKeyPressed)
+
+	ui.header.SetText(" gdu ~ Comparing analyses, use arrow keys to navigate, " + formatKeyHints(
+		keyHint{ui.keymap.keyName(keyActionToggleApparentSize), "toggles apparent size"},
+		keyHint{"u", "hides unchanged items"},
+		keyHint{ui.keymap.keyName(keyActionQuit), "quits"},
+	) + " ")
+
+	grid := tview.NewGrid().SetRows(1, 1, 0, 1).SetColumns(0)
+	grid.AddItem(ui.header, 0, 0, 1, 1, 0, 0, false).
+		AddItem(ui.currentDirLabel, 1, 0, 1, 1, 0, 0, false).
+		AddItem(ui.diffTable, 2, 0, 1, 1, 0, 0, true).
+		AddItem(ui.footerLabel, 3, 0, 1, 1, 0, 0, false)
+
+	ui.pages.HidePage("background")
+	ui.addInputPage("diff", grid)
+
+	ui.showDiffDir(root, nil)
+	ui.app.SetFocus(ui.diffTable)
+	return nil
+}
+
+func (ui *UI) diffKeyPressed(key *tcell.EventKey) *tcell.EventKey {
+	action := ui.keymap.action(key)
+	switch {
+	case action == keyActionQuit:
+		ui.app.Stop()
+		return nil
+	case action == keyActionGoToParent || key.Key() == tcell.KeyLeft:
+		if ui.diffDir.Parent != nil {
+			ui.showDiffDir(ui.diffDir.Parent, ui.diffDir)
+		}
+		return nil
+	case action == keyActionGoInto || key.Key() == tcell.KeyRight:
+		row, column := ui.diffTable.GetSelection()
+		if ui.diffDir.Parent == nil || row > 0 { // do not select /..
+			ui.diffItemSelected(row, column)
+		}
+		return nil
+	case action == keyActionToggleApparentSize:
+		ui.ShowApparentSize = !ui.ShowApparentSize
+		ui.showDiffDir(ui.diffDir, nil)
+		return nil
+	case key.Rune() == 'u':
+		ui.hideUnchanged = !ui.hideUnchanged
+		ui.showDiffDir(ui.diffDir, nil)
+		return nil
+	}
+	return key
+}
+
+func (ui *UI) diffItemSelected(row, column int) {
+	item, ok := ui.diffTable.GetCell(row, column).GetReference().(*diff.Item)
+	if !ok || !item.IsDir {
+		return
+	}
+	if item == ui.diffDir.Parent {
+		ui.showDiffDir(item, ui.diffDir)
+		return
+	}
+	ui.showDiffDir(item, nil)
+}
+
+// showDiffDir shows content of given item of diff tree and selects row with previous item
+func (ui *UI) showDiffDir(item, selected *diff.Item) {
+	ui.diffDir = item
+	ui.diffTable.Clear()
+
+	ui.currentDirLabel.SetText("[::b] --- " + tview.Escape(item.GetPath()) + " ---").
+		SetDynamicColors(true)
+
+	rowIndex := 0
+	if item.Parent != nil {
+		cell := tview.NewTableCell("                                                [::b]/..")
+		cell.SetReference(item.Parent)
+		cell.SetStyle(tcell.Style{}.Foreground(tcell.ColorDefault))
+		ui.diffTable.SetCell(0, 0, cell)
+		rowIndex++
+	}
+
+	selectedRow := 0
+	var added, removed, changed int
+	item.SortChildren(ui.ShowApparentSize)
+	for _, child := range item.Children {
+		switch child.Status {
+		case diff.Added:
+			added++
+		case diff.Removed:
+			removed++
+		case diff.Changed:
+			changed++
+		case diff.Unchanged:
+			if ui.hideUnchanged {
+				continue
+			}
+		}
+
+		if child == selected {
+			selectedRow = rowIndex
+		}
+		cell := tview.NewTableCell(ui.formatDiffRow(child))
+		cell.SetReference(child)
+		cell.SetStyle(tcell.Style{}.Foreground(tcell.ColorDefault))
+		ui.diffTable.SetCell(rowIndex, 0, cell)
+		rowIndex++
+	}
+
+	footerNumberColor, footerTextColor := ui.getFooterColors()
+	oldSize, newSize := item.OldUsage, item.NewUsage
+	if ui.ShowApparentSize {
+		oldSize, newSize = item.OldSize, item.NewSize
+	}
+	ui.footerLabel.SetText(
+		footerTextColor +
+			" Old: " + footerNumberColor + ui.formatSize(oldSize, true, false) +
+			" New: " + footerNumberColor + ui.formatSize(newSize, true, false) +
+			" Change: " + footerNumberColor + ui.formatDelta(item.Delta(ui.ShowApparentSize), true) +
+			" Added: " + footerNumberColor + strconv.Itoa(added) + footerTextColor +
+			" Removed: " + footerNumberColor + strconv.Itoa(removed) + footerTextColor +
+			" Changed: " + footerNumberColor + strconv.Itoa(changed) + footerTextColor)
+
+	ui.diffTable.Select(selectedRow, 0)
+}
+
+func (ui *UI) formatDiffRow(item *diff.Item) string {
+	oldSize, newSize := item.OldUsage, item.NewUsage
+	if ui.ShowApparentSize {
+		oldSize, newSize = item.OldSize, item.NewSize
+	}
+
+	numberColor := defaultColorBold
+	if ui.UseColors {
+		numberColor = fmt.Sprintf("[%s::b]", ui.resultRow.NumberColor)
+	}
+
+	row := ui.getDeltaColor(item.Delta(ui.ShowApparentSize))
+	row += fmt.Sprintf("%16s", ui.formatDelta(item.Delta(ui.ShowApparentSize), false))
+	row += numberColor + fmt.Sprintf("%15s", ui.formatSize(oldSize, false, true))
+	row += numberColor + fmt.Sprintf("%15s", ui.formatSize(newSize, false, true))
+
+	switch item.Status {
+	case diff.Added:
+		row += ui.getDeltaColor(1) + " + "
+	case diff.Removed:
+		row += ui.getDeltaColor(-1) + " - "
+	case diff.Changed:
+		row += defaultColorBold + " ~ "
+	default:
+		row += defaultColorBold + "   "
+	}
+
+	if item.IsDir {
+		if ui.UseColors {
+			row += fmt.Sprintf("[%s::b]/", ui.resultRow.DirectoryColor)
+		} else {
+			row += defaultColorBold + "/"
+		}
+	} else {
+		row += "[-::-]"
+	}
+	row += tview.Escape(item.Name)
+	return row
+}
+
+// formatDelta formats size change with explicit sign
+func (ui *UI) formatDelta(delta int64, reverseColor bool) string {
+	sign := "+"
+	if delta < 0 {
+		sign = "-"
+		delta = -delta
+	}
+	return sign + ui.formatSize(delta, reverseColor, true)
+}
+
+// getDeltaColor returns red color for growth and green for shrinking
+func (ui *UI) getDeltaColor(delta int64) string {
+	switch {
+	case !ui.UseColors || delta == 0:
+		return defaultColorBold
+	case delta > 0:
+		return "[red::b]"
+	default:
+		return "[green::b]"
+	}
+}
@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dundee/gdu/v5/internal/testapp"
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/diff"
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func createDiff() *diff.Item {
+	old := &analyze.Dir{File: &analyze.File{Name: "old"}, BasePath: "/mnt"}
+	oldSub := &analyze.Dir{File: &analyze.File{Name: "sub", Parent: old}}
+	oldSub.AddFile(&analyze.File{Name: "file", Size: 100, Usage: 4096, Parent: oldSub})
+	old.AddFile(oldSub)
+	old.AddFile(&analyze.File{Name: "same", Size: 10, Usage: 4096, Parent: old})
+	old.AddFile(&analyze.File{Name: "gone", Size: 10, Usage: 4096, Parent: old})
+	old.UpdateStats(make(fs.HardLinkedItems))
+
+	new := &analyze.Dir{File: &analyze.File{Name: "new"}, BasePath: "/mnt"}
+	newSub := &analyze.Dir{File: &analyze.File{Name: "sub", Parent: new}}
+	newSub.AddFile(&analyze.File{Name: "file", Size: 10000, Usage: 12288, Parent: newSub})
+	new.AddFile(newSub)
+	new.AddFile(&analyze.File{Name: "same", Size: 10, Usage: 4096, Parent: new})
+	new.UpdateStats(make(fs.HardLinkedItems))
+
+	return diff.Compare(old, new, false)
+}
+
+func TestShowDiff(t *testing.T) {
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(false)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, true, false, false, false, false)
+
+	err := ui.ShowDiff(createDiff())
+	assert.Nil(t, err)
+
+	assert.True(t, ui.pages.HasPage("diff"))
+	assert.Equal(t, 3, ui.diffTable.GetRowCount())
+	assert.Contains(t, ui.diffTable.GetCell(0, 0).Text, "sub")
+	assert.Contains(t, ui.diffTable.GetCell(0, 0).Text, "[red::b]   +8.0")
+	assert.Contains(t, ui.diffTable.GetCell(1, 0).Text, "[green::b]   -4.0")
+	assert.Contains(t, ui.diffTable.GetCell(1, 0).Text, " - [-::-]gone")
+	assert.Contains(t, ui.diffTable.GetCell(2, 0).Text, "same")
+	assert.Contains(t, ui.footerLabel.GetText(true), "Added: 0 Removed: 1 Changed: 1")
+
+	// keys are passed to the diff table
+	assert.NotNil(t, ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'l', 0)))
+}
+
+func TestDiffNavigation(t *testing.T) {
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(false)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, false, false, false, false, false)
+
+	err := ui.ShowDiff(createDiff())
+	assert.Nil(t, err)
+
+	ui.diffKeyPressed(tcell.NewEventKey(tcell.KeyRight, 0, 0))
+	assert.Equal(t, "sub", ui.diffDir.Name)
+	assert.Contains(t, ui.diffTable.GetCell(0, 0).Text, "/..")
+	assert.Contains(t, ui.diffTable.GetCell(1, 0).Text, "file")
+	assert.Contains(t, ui.currentDirLabel.GetText(true), "/mnt/new/sub")
+
+	// /.. is not entered by right key
+	ui.diffKeyPressed(tcell.NewEventKey(tcell.KeyRune, 'l', 0))
+	assert.Equal(t, "sub", ui.diffDir.Name)
+
+	// files are not entered
+	ui.diffTable.Select(1, 0)
+	ui.diffKeyPressed(tcell.NewEventKey(tcell.KeyRight, 0, 0))
+	assert.Equal(t, "sub", ui.diffDir.Name)
+
+	ui.diffKeyPressed(tcell.NewEventKey(tcell.KeyRune, 'h', 0))
+	assert.Equal(t, "/mnt/new", ui.diffDir.Name)
+	row, _ := ui.diffTable.GetSelection()
+	assert.Equal(t, 0, row)
+
+	// already at the top
+	ui.diffKeyPressed(tcell.NewEventKey(tcell.KeyLeft, 0, 0))
+	assert.Equal(t, "/mnt/new", ui.diffDir.Name)
+}
+
+func TestDiffToggles(t *testing.T) {
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(false)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, false, false, false, false, false)
+
+	err := ui.ShowDiff(createDiff())
+	assert.Nil(t, err)
+
+	ui.diffKeyPressed(tcell.NewEventKey(tcell.KeyRune, 'u', 0))
+	assert.Equal(t, 2, ui.diffTable.GetRowCount())
+
+	ui.diffKeyPressed(tcell.NewEventKey(tcell.KeyRune, 'a', 0))
+	assert.True(t, ui.ShowApparentSize)
+	assert.Contains(t, ui.diffTable.GetCell(0, 0).Text, "+9.7")
+
+	assert.Nil(t, ui.diffKeyPressed(tcell.NewEventKey(tcell.KeyRune, 'q', 0)))
+	assert.NotNil(t, ui.diffKeyPressed(tcell.NewEventKey(tcell.KeyDown, 0, 0)))
+}
+
+func TestDiffWithReboundKeys(t *testing.T) {
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	keymap, err := ParseKeymap(map[string][]string{
+		"toggle-apparent-size": {"A"},
+		"quit":                 {"ctrl+q"},
+	})
+	assert.Nil(t, err)
+
+	app := testapp.CreateMockedApp(false)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, false, false, false, false, false)
+	ui.SetKeymap(keymap)
+
+	err = ui.ShowDiff(createDiff())
+	assert.Nil(t, err)
+	assert.Contains(t, ui.header.GetText(true), "A toggles apparent size, u hides unchanged items, ctrl+q quits")
+
+	assert.NotNil(t, ui.diffKeyPressed(tcell.NewEventKey(tcell.KeyRune, 'a', 0)))
+	assert.False(t, ui.ShowApparentSize)
+	ui.diffKeyPressed(tcell.NewEventKey(tcell.KeyRune, 'A', 0))
+	assert.True(t, ui.ShowApparentSize)
+
+	assert.NotNil(t, ui.diffKeyPressed(tcell.NewEventKey(tcell.KeyRune, 'q', 0)))
+	assert.Nil(t, ui.diffKeyPressed(tcell.NewEventKey(tcell.KeyCtrlQ, 0, tcell.ModCtrl)))
+}
@@ -4,7 +4,9 @@ package tui
 
 import (
 	"os"
+	"os/exec"
 	"os/signal"
+	"strings"
 	"syscall"
 )
 
@@ -33,6 +35,35 @@ func (ui *UI) spawnShell() {
 	})
 }
 
+// getShellCommand returns command line running the command by the shell
+func getShellCommand(command string) (string, []string) {
+	return "/bin/sh", []string{"-c", command}
+}
+
+// newShellCommand returns command running the command line by the shell
+func newShellCommand(command string) *exec.Cmd {
+	argv0, argv := getShellCommand(command)
+	return exec.Command(argv0, argv...)
+}
+
+// quoteShellArg quotes the argument so it's passed to the shell as it is
+func quoteShellArg(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// runInForeground suspends the UI and runs the command in the given directory
+func (ui *UI) runInForeground(command, dir string) error {
+	var err error
+	ui.app.Suspend(func() {
+		if err = os.Chdir(dir); err != nil {
+			return
+		}
+		argv0, argv := getShellCommand(command)
+		err = ui.exec(argv0, argv, os.Environ())
+	})
+	return err
+}
+
 func stopProcess() error {
 	// chan for signal
 	sigChan := make(chan os.Signal, 1)
@@ -2,6 +2,9 @@ package tui
 
 import (
 	"os"
+	"os/exec"
+	"strings"
+	"syscall"
 )
 
 func getShellBin() string {
@@ -28,6 +31,43 @@ func (ui *UI) spawnShell() {
 	}
 }
 
+// newShellCommand returns command running the command line by the shell.
+// The command line is passed to cmd.exe as it is,
+// because escaping of arguments done by exec.Command breaks quoting of cmd.exe.
+func newShellCommand(command string) *exec.Cmd {
+	cmd := exec.Command(getShellBin())
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: getShellCommandLine(command)}
+	return cmd
+}
+
+// getShellCommandLine returns command line running the command by the shell,
+// with /S only the outer quotes are removed from the command
+func getShellCommandLine(command string) string {
+	return `"` + getShellBin() + `" /S /C "` + command + `"`
+}
+
+// quoteShellArg quotes the argument so it's passed to the shell as it is.
+// Percent signs are escaped outside of the quotes, so no variables are expanded.
+func quoteShellArg(arg string) string {
+	arg = strings.ReplaceAll(arg, `"`, `""`)
+	return `"` + strings.ReplaceAll(arg, "%", `"^%"`) + `"`
+}
+
+// runInForeground stops the UI and runs the command in the given directory
+func (ui *UI) runInForeground(command, dir string) error {
+	ui.app.Stop()
+
+	if err := os.Chdir(dir); err != nil {
+		return err
+	}
+	cmd := newShellCommand(command)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	cmd.Env = os.Environ()
+	return cmd.Run()
+}
+
 func stopProcess() error {
 	return nil
 }
@@ -3,9 +3,13 @@
 package tui
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
 )
 
 func TestExecute(t *testing.T) {
@@ -14,3 +18,29 @@ func TestExecute(t *testing.T) {
 
 	assert.Nil(t, err)
 }
+
+func TestQuoteShellArg(t *testing.T) {
+	assert.Equal(t, `"a b"`, quoteShellArg("a b"))
+	assert.Equal(t, `"a"^%"PATH"^%"b"`, quoteShellArg("a%PATH%b"))
+}
+
+func TestGetShellCommandLine(t *testing.T) {
+	assert.Equal(t, `"`+getShellBin()+`" /S /C "type "a b""`, getShellCommandLine(`type "a b"`))
+}
+
+func TestShellCommandWithPlaceholderContainingSpaces(t *testing.T) {
+	dir := t.TempDir()
+	name := "with space %PATH% & more.txt"
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte("content"), 0o600))
+
+	parent := &analyze.Dir{
+		File:     &analyze.File{Name: filepath.Base(dir)},
+		BasePath: filepath.Dir(dir),
+	}
+	file := &analyze.File{Name: name, Parent: parent}
+
+	cmd := newShellCommand(expandCommand("type {path}", file, dir, nil))
+	output, err := cmd.CombinedOutput()
+	assert.Nil(t, err, string(output))
+	assert.Equal(t, "content", string(output))
+}
@@ -0,0 +1,295 @@
+package tui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/report"
+)
+
+// Scopes of the export
+const (
+	exportScopeWhole = iota
+	exportScopeCurrent
+	exportScopeMarked
+	exportScopeFiltered
+)
+
+var exportScopes = []string{"Whole tree", "Current directory", "Marked items", "Filtered items"}
+
+var exportFormats = []string{
+	report.FormatJSON,
+	report.FormatCSV,
+	report.FormatTSV,
+	report.FormatHTML,
+	report.FormatFolded,
+	report.FormatSQLite,
+}
+
+func (ui *UI) confirmExport() *tview.Form {
+	form := tview.NewForm()
+	form.AddInputField("File name", ui.exportName, 30, nil, func(v string) {
+		ui.exportName = v
+	}).
+		AddDropDown("Scope", exportScopes, ui.exportScope, func(_ string, index int) {
+			ui.exportScope = index
+		}).
+		AddDropDown("Format", exportFormats, formatIndex(ui.exportFormat), func(format string, _ int) {
+			if format == ui.exportFormat {
+				return
+			}
+			ui.exportName = replaceExportExt(ui.exportName, ui.exportFormat, format)
+			ui.exportFormat = format
+			if item, ok := form.GetFormItemByLabel("File name").(*tview.InputField); ok {
+				item.SetText(ui.exportName)
+			}
+		}).
+		AddCheckbox("Exclude ignored", ui.exportExcludeIgnored, func(checked bool) {
+			ui.exportExcludeIgnored = checked
+		}).
+		AddButton("Export", ui.exportAnalysis).
+		SetButtonsAlign(tview.AlignCenter)
+	form.SetBorder(true).
+		SetTitle(" Export data ").
+		SetInputCapture(func(key *tcell.EventKey) *tcell.EventKey {
+			if key.Key() == tcell.KeyEsc {
+				ui.pages.RemovePage("export")
+				ui.app.SetFocus(ui.table)
+				return nil
+			}
+			return key
+		})
+	flex := modal(form, 50, 13)
+	ui.addInputPage("export", flex)
+	ui.app.SetFocus(form)
+	return form
+}
+
+func (ui *UI) exportAnalysis() {
+	ui.pages.RemovePage("export")
+
+	dir, err := ui.getExportedDir()
+	if err != nil {
+		ui.showErr("Error exporting data", err)
+		return
+	}
+
+	text := tview.NewTextView().SetText("Export in progress...").SetTextAlign(tview.AlignCenter)
+	text.SetBorder(true).SetTitle(" Export data ")
+	flex := modal(text, 50, 3)
+	ui.pages.AddPage("exporting", flex, true, true)
+
+	go func() {
+		var err error
+		defer ui.app.QueueUpdateDraw(func() {
+			ui.pages.RemovePage("exporting")
+			if err == nil {
+				ui.app.SetFocus(ui.table)
+			}
+		})
+		if ui.done != nil {
+			defer func() {
+				ui.done <- struct{}{}
+			}()
+		}
+
+		if ui.exportFormat == report.FormatSQLite {
+			// SQLite database can't be written into already opened file
+			if err = os.Remove(ui.exportName); err != nil && !os.IsNotExist(err) {
+				ui.showErrFromGo("Error creating file", err)
+				return
+			}
+			if err = report.WriteSQLite(ui.exportName, dir, ui.metadata); err != nil {
+				ui.showErrFromGo("Error writing to file", err)
+			}
+			return
+		}
+
+		file, err := os.Create(ui.exportName)
+		if err != nil {
+			ui.showErrFromGo("Error creating file", err)
+			return
+		}
+		defer file.Close()
+
+		if err = ui.writeExport(file, dir); err != nil {
+			ui.showErrFromGo("Error writing to file", err)
+			return
+		}
+	}()
+}
+
+func (ui *UI) writeExport(file *os.File, dir fs.Item) error {
+	switch ui.exportFormat {
+	case report.FormatCSV:
+		return report.WriteCSV(file, dir, ',', 0, false)
+	case report.FormatTSV:
+		return report.WriteCSV(file, dir, '\t', 0, false)
+	case report.FormatHTML:
+		return report.WriteHTML(file, dir, 0, ui.ShowApparentSize, ui.UseSIPrefix)
+	case report.FormatFolded:
+		return report.WriteFolded(file, dir, 0, ui.ShowApparentSize)
+	}
+
+	return report.WriteJSON(file, dir, ui.metadata)
+}
+
+// getExportedDir returns directory with items in the chosen scope of the export.
+// Directories are copied if some of their items are left out, so the analyzed tree stays untouched.
+func (ui *UI) getExportedDir() (fs.Item, error) {
+	if ui.topDir == nil || ui.currentDir == nil {
+		return nil, errors.New("no analyzed directory to export")
+	}
+
+	var ignored map[fs.Item]struct{}
+	if ui.exportExcludeIgnored && len(ui.ignoredRows) > 0 {
+		ignored = make(map[fs.Item]struct{}, len(ui.ignoredRows))
+		for row := range ui.ignoredRows {
+			if item := ui.getListedItem(row); item != nil {
+				ignored[item] = struct{}{}
+			}
+		}
+	}
+
+	switch ui.exportScope {
+	case exportScopeCurrent:
+		if ignored == nil {
+			return ui.currentDir, nil
+		}
+		return copyDirWithFiles(ui.currentDir, withoutItems(ui.currentDir.GetFiles(), ignored), true), nil
+	case exportScopeMarked:
+		if len(ui.markedRows) == 0 {
+			return nil, errors.New("no items are marked")
+		}
+		files := make(fs.Files, 0, len(ui.markedRows))
+		for row := 0; row < ui.table.GetRowCount(); row++ {
+			if _, ok := ui.markedRows[row]; !ok {
+				continue
+			}
+			if item := ui.getListedItem(row); item != nil {
+				files = append(files, item)
+			}
+		}
+		return copyDirWithFiles(ui.currentDir, withoutItems(files, ignored), true), nil
+	case exportScopeFiltered:
+		files := make(fs.Files, 0, ui.table.GetRowCount())
+		for row := 0; row < ui.table.GetRowCount(); row++ {
+			if item := ui.getListedItem(row); item != nil {
+				files = append(files, item)
+			}
+		}
+		return copyDirWithFiles(ui.currentDir, withoutItems(files, ignored), true), nil
+	}
+
+	if ignored == nil {
+		return ui.topDir, nil
+	}
+
+	// copy all directories from the current one up to the top one
+	var dir fs.Item = ui.currentDir
+	copied := copyDirWithFiles(dir, withoutItems(dir.GetFiles(), ignored), dir.GetPath() == ui.topDirPath)
+	for dir.GetPath() != ui.topDirPath && dir.GetParent() != nil {
+		parent := dir.GetParent()
+		files := make(fs.Files, 0, len(parent.GetFiles()))
+		for _, file := range parent.GetFiles() {
+			if file == dir {
+				files = append(files, copied)
+			} else {
+				files = append(files, file)
+			}
+		}
+		copied = copyDirWithFiles(parent, files, parent.GetPath() == ui.topDirPath)
+		dir = parent
+	}
+	return copied, nil
+}
+
+// getListedItem returns item of the current directory listed in given row
+// or nil if the row contains link to parent directory
+func (ui *UI) getListedItem(row int) fs.Item {
+	if row == 0 && ui.currentDirPath != ui.topDirPath {
+		return nil
+	}
+	item, ok := ui.table.GetCell(row, 0).GetReference().(fs.Item)
+	if !ok {
+		return nil
+	}
+	// collapsed path points to the deepest directory
+	for item.GetParent() != nil && item.GetParent().GetPath() != ui.currentDir.GetPath() {
+		item = item.GetParent()
+	}
+	return item
+}
+
+func withoutItems(files fs.Files, excluded map[fs.Item]struct{}) fs.Files {
+	if excluded == nil {
+		return files
+	}
+	res := make(fs.Files, 0, len(files))
+	for _, file := range files {
+		if _, ok := excluded[file]; !ok {
+			res = append(res, file)
+		}
+	}
+	return res
+}
+
+// copyDirWithFiles returns shallow copy of dir containing only given files.
+// Sizes of the copy are lowered by sizes of the left out files.
+// Top level copy has no parent, so it can be exported alone.
+func copyDirWithFiles(dir fs.Item, files fs.Files, topLevel bool) *analyze.Dir {
+	var size, usage int64
+	var itemCount int
+	for _, file := range dir.GetFiles() {
+		size += file.GetSize()
+		usage += file.GetUsage()
+		itemCount += file.GetItemCount()
+	}
+	for _, file := range files {
+		size -= file.GetSize()
+		usage -= file.GetUsage()
+		itemCount -= file.GetItemCount()
+	}
+
+	copied := &analyze.Dir{
+		File: &analyze.File{
+			Name:  dir.GetName(),
+			Size:  dir.GetSize() - size,
+			Usage: dir.GetUsage() - usage,
+			Mtime: dir.GetMtime(),
+			Flag:  dir.GetFlag(),
+		},
+		ItemCount: dir.GetItemCount() - itemCount,
+		Files:     files,
+	}
+	if topLevel {
+		copied.BasePath = filepath.Dir(dir.GetPath())
+	} else if parent := dir.GetParent(); parent != nil {
+		copied.Parent = parent
+	}
+	return copied
+}
+
+func formatIndex(format string) int {
+	for i, f := range exportFormats {
+		if f == format {
+			return i
+		}
+	}
+	return 0
+}
+
+// replaceExportExt changes extension of the file name if it matches the previous format
+func replaceExportExt(name, oldFormat, newFormat string) string {
+	if !strings.HasSuffix(name, "."+oldFormat) {
+		return name
+	}
+	return strings.TrimSuffix(name, "."+oldFormat) + "." + newFormat
+}
@@ -3,6 +3,7 @@ package tui
 import (
 	"bytes"
 	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"github.com/dundee/gdu/v5/internal/testapp"
 	"github.com/dundee/gdu/v5/pkg/analyze"
 	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/report"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/stretchr/testify/assert"
@@ -221,3 +223,174 @@ func TestExportAnalysisWithoutRights(t *testing.T) {
 
 	assert.True(t, ui.pages.HasPage("error"))
 }
+
+func createExportScopeUI(t *testing.T) (*UI, *analyze.Dir, *analyze.Dir) {
+	t.Helper()
+
+	top := &analyze.Dir{
+		File: &analyze.File{
+			Name:  "top",
+			Size:  4096 + 4096 + 600 + 50,
+			Usage: 4096 + 4096 + 3*4096 + 4096,
+		},
+		BasePath:  "/srv",
+		ItemCount: 6,
+	}
+	sub := &analyze.Dir{
+		File: &analyze.File{
+			Name:   "sub",
+			Size:   4096 + 600,
+			Usage:  4096 + 3*4096,
+			Parent: top,
+		},
+		ItemCount: 4,
+	}
+	for i, name := range []string{"a", "b", "c"} {
+		sub.Files = append(sub.Files, &analyze.File{
+			Name:   name,
+			Size:   int64(100 * (i + 1)),
+			Usage:  4096,
+			Parent: sub,
+		})
+	}
+	top.Files = fs.Files{sub, &analyze.File{Name: "x", Size: 50, Usage: 4096, Parent: top}}
+
+	simScreen := testapp.CreateSimScreen()
+	t.Cleanup(simScreen.Fini)
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, false, true, false, false, false)
+	ui.done = make(chan struct{})
+	ui.topDir = top
+	ui.topDirPath = top.GetPath()
+	ui.currentDir = sub
+	ui.ShowApparentSize = true
+	ui.showDir()
+
+	return ui, top, sub
+}
+
+func TestGetExportedDirWholeTree(t *testing.T) {
+	ui, top, _ := createExportScopeUI(t)
+
+	dir, err := ui.getExportedDir()
+
+	assert.Nil(t, err)
+	assert.Equal(t, top, dir)
+}
+
+func TestGetExportedDirCurrent(t *testing.T) {
+	ui, _, sub := createExportScopeUI(t)
+	ui.exportScope = exportScopeCurrent
+
+	dir, err := ui.getExportedDir()
+
+	assert.Nil(t, err)
+	assert.Equal(t, sub, dir)
+}
+
+func TestGetExportedDirWholeTreeWithoutIgnored(t *testing.T) {
+	ui, top, sub := createExportScopeUI(t)
+	ui.exportExcludeIgnored = true
+	ui.ignoredRows[1] = struct{}{} // c
+
+	dir, err := ui.getExportedDir()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/srv/top", dir.GetPath())
+	assert.Nil(t, dir.GetParent())
+	assert.Equal(t, top.GetSize()-300, dir.GetSize())
+	assert.Equal(t, top.GetItemCount()-1, dir.GetItemCount())
+
+	copiedSub := dir.GetFiles()[0]
+	assert.Equal(t, "/srv/top/sub", copiedSub.GetPath())
+	assert.Equal(t, 2, len(copiedSub.GetFiles()))
+	assert.Equal(t, sub.GetUsage()-4096, copiedSub.GetUsage())
+
+	// analyzed tree stays untouched
+	assert.Equal(t, 3, len(sub.GetFiles()))
+	assert.Equal(t, sub, top.GetFiles()[0])
+}
+
+func TestGetExportedDirMarked(t *testing.T) {
+	ui, _, _ := createExportScopeUI(t)
+	ui.exportScope = exportScopeMarked
+	ui.exportExcludeIgnored = true
+	ui.markedRows[1] = struct{}{} // c
+	ui.markedRows[3] = struct{}{} // a
+	ui.ignoredRows[3] = struct{}{}
+
+	dir, err := ui.getExportedDir()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/srv/top/sub", dir.GetPath())
+	assert.Nil(t, dir.GetParent())
+	assert.Equal(t, 1, len(dir.GetFiles()))
+	assert.Equal(t, "/srv/top/sub/c", dir.GetFiles()[0].GetPath())
+	assert.Equal(t, int64(4096+300), dir.GetSize())
+}
+
+func TestGetExportedDirWithoutMarked(t *testing.T) {
+	ui, _, _ := createExportScopeUI(t)
+	ui.exportScope = exportScopeMarked
+
+	_, err := ui.getExportedDir()
+
+	assert.ErrorContains(t, err, "no items are marked")
+}
+
+func TestExportFilteredToCSV(t *testing.T) {
+	ui, _, _ := createExportScopeUI(t)
+	ui.filterValue = "b"
+	ui.showDir()
+
+	ui.exportName = filepath.Join(t.TempDir(), "export.csv")
+	ui.exportScope = exportScopeFiltered
+	ui.exportFormat = "csv"
+
+	ui.exportAnalysis()
+	<-ui.done
+
+	data, err := os.ReadFile(ui.exportName)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "/srv/top/sub,Directory,4296,")
+	assert.Contains(t, string(data), "/srv/top/sub/b,File,200,")
+	assert.NotContains(t, string(data), "/srv/top/sub/c")
+
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+}
+
+func TestExportCurrentToSQLite(t *testing.T) {
+	ui, _, _ := createExportScopeUI(t)
+	ui.exportName = filepath.Join(t.TempDir(), "export.sqlite")
+	ui.exportScope = exportScopeCurrent
+	ui.exportFormat = "sqlite"
+
+	ui.exportAnalysis()
+	<-ui.done
+
+	dir, err := report.ReadSQLite(ui.exportName)
+	assert.Nil(t, err)
+	assert.Equal(t, "/srv/top/sub", dir.GetPath())
+	assert.Equal(t, 3, len(dir.Files))
+}
+
+func TestExportFormatChangesExtension(t *testing.T) {
+	ui, _, _ := createExportScopeUI(t)
+
+	form := ui.confirmExport()
+	format := form.GetFormItemByLabel("Format").(*tview.DropDown)
+	format.SetCurrentOption(3)
+
+	assert.Equal(t, "html", ui.exportFormat)
+	assert.Equal(t, "export.html", ui.exportName)
+	assert.Equal(t, "export.html", form.GetFormItemByLabel("File name").(*tview.InputField).GetText())
+
+	form.GetFormItemByLabel("Scope").(*tview.DropDown).SetCurrentOption(2)
+	assert.Equal(t, exportScopeMarked, ui.exportScope)
+
+	form.GetFormItemByLabel("Exclude ignored").(*tview.Checkbox).SetChecked(true)
+	assert.True(t, ui.exportExcludeIgnored)
+}
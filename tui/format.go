@@ -18,6 +18,11 @@ const (
 )
 
 func (ui *UI) formatFileRow(item fs.Item, maxUsage, maxSize int64, marked, ignored bool) string {
+	return ui.formatTreeFileRow(item, maxUsage, maxSize, marked, ignored, "")
+}
+
+// formatTreeFileRow formats row of the item with guide of the tree view in front of its name
+func (ui *UI) formatTreeFileRow(item fs.Item, maxUsage, maxSize int64, marked, ignored bool, guide string) string {
 	part := 0
 	if !ignored {
 		if ui.ShowApparentSize {
@@ -31,12 +36,10 @@ func (ui *UI) formatFileRow(item fs.Item, maxUsage, maxSize int64, marked, ignor
 		}
 	}
 
-	row := string(item.GetFlag())
+	ruleColor := ui.getRuleColor(item, marked, ignored)
+	row := ui.formatFlag(item, marked, ignored)
 
-	numberColor := fmt.Sprintf(
-		"[%s::b]",
-		ui.resultRow.NumberColor,
-	)
+	numberColor := ui.getNumberColor(ruleColor)
 
 	if ui.UseColors && !marked && !ignored {
 		row += numberColor
@@ -51,9 +54,9 @@ func (ui *UI) formatFileRow(item fs.Item, maxUsage, maxSize int64, marked, ignor
 	}
 
 	if ui.useOldSizeBar {
-		row += " " + getUsageGraphOld(part) + " "
+		row += " " + ui.formatBar(getUsageGraphOld(part), marked, ignored) + " "
 	} else {
-		row += getUsageGraph(part)
+		row += ui.formatBar(getUsageGraph(part), marked, ignored)
 	}
 
 	if ui.showItemCount {
@@ -91,12 +94,17 @@ func (ui *UI) formatFileRow(item fs.Item, maxUsage, maxSize int64, marked, ignor
 		row += " "
 	}
 
-	if item.IsDir() {
-		if ui.UseColors && !marked && !ignored {
-			row += fmt.Sprintf("[%s::b]/", ui.resultRow.DirectoryColor)
-		} else {
-			row += defaultColorBold + "/"
-		}
+	row += guide
+
+	switch {
+	case item.IsDir() && ruleColor != "":
+		row += fmt.Sprintf("[%s::b]/", ruleColor)
+	case item.IsDir() && ui.UseColors && !marked && !ignored:
+		row += fmt.Sprintf("[%s::b]/", ui.resultRow.DirectoryColor)
+	case item.IsDir():
+		row += defaultColorBold + "/"
+	case ruleColor != "":
+		row += "[" + ruleColor + "::]"
 	}
 	row += tview.Escape(item.GetName())
 	return row
@@ -104,6 +112,13 @@ func (ui *UI) formatFileRow(item fs.Item, maxUsage, maxSize int64, marked, ignor
 
 // formatCollapsedRow formats a collapsed directory path for display
 func (ui *UI) formatCollapsedRow(collapsedPath *CollapsedPath, maxUsage, maxSize int64, marked, ignored bool) string {
+	return ui.formatTreeCollapsedRow(collapsedPath, maxUsage, maxSize, marked, ignored, "")
+}
+
+// formatTreeCollapsedRow formats a collapsed directory path with guide of the tree view in front of it
+func (ui *UI) formatTreeCollapsedRow(
+	collapsedPath *CollapsedPath, maxUsage, maxSize int64, marked, ignored bool, guide string,
+) string {
 	// Use the deepest directory's stats for display
 	item := collapsedPath.DeepestDir
 
@@ -120,12 +135,10 @@ func (ui *UI) formatCollapsedRow(collapsedPath *CollapsedPath, maxUsage, maxSize
 		}
 	}
 
-	row := string(item.GetFlag())
+	ruleColor := ui.getRuleColor(item, marked, ignored)
+	row := ui.formatFlag(item, marked, ignored)
 
-	numberColor := fmt.Sprintf(
-		"[%s::b]",
-		ui.resultRow.NumberColor,
-	)
+	numberColor := ui.getNumberColor(ruleColor)
 
 	if ui.UseColors && !marked && !ignored {
 		row += numberColor
@@ -140,9 +153,9 @@ func (ui *UI) formatCollapsedRow(collapsedPath *CollapsedPath, maxUsage, maxSize
 	}
 
 	if ui.useOldSizeBar {
-		row += " " + getUsageGraphOld(part) + " "
+		row += " " + ui.formatBar(getUsageGraphOld(part), marked, ignored) + " "
 	} else {
-		row += getUsageGraph(part)
+		row += ui.formatBar(getUsageGraph(part), marked, ignored)
 	}
 
 	if ui.showItemCount {
@@ -180,8 +193,12 @@ func (ui *UI) formatCollapsedRow(collapsedPath *CollapsedPath, maxUsage, maxSize
 		row += " "
 	}
 
+	row += guide
+
 	// Always display as directory with special formatting for collapsed path
-	if ui.UseColors && !marked && !ignored {
+	if ruleColor != "" {
+		row += fmt.Sprintf("[%s::b]/", ruleColor)
+	} else if ui.UseColors && !marked && !ignored {
 		row += fmt.Sprintf("[%s::b]/", ui.resultRow.DirectoryColor)
 	} else {
 		row += defaultColorBold + "/"
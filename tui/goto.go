@@ -0,0 +1,189 @@
+package tui
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// maximal number of completion candidates shown under the go-to-path prompt
+const pathCandidatesLimit = 20
+
+// openDir shows content of the directory and selects the given item in it
+func (ui *UI) openDir(dir, selected fs.Item) {
+	ui.currentDir = dir
+	ui.hideFilterInput()
+	ui.markedRows = make(map[int]struct{})
+	ui.ignoredRows = make(map[int]struct{})
+	ui.showDir()
+	if selected != nil {
+		ui.selectItemRow(selected)
+	}
+}
+
+// openPath opens directory with the given path,
+// for files the parent directory is opened with the file selected
+func (ui *UI) openPath(path string) bool {
+	item := ui.findItemByPath(path)
+	if item == nil {
+		return false
+	}
+	if item.IsDir() {
+		ui.openDir(item, nil)
+	} else {
+		ui.openDir(item.GetParent(), item)
+	}
+	return true
+}
+
+// resolvePath returns absolute path, relative paths are relative to the current directory
+func (ui *UI) resolvePath(path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(ui.currentDirPath, path)
+	}
+	return filepath.Clean(path)
+}
+
+// findItemByPath returns item of the analyzed tree with the given absolute path
+func (ui *UI) findItemByPath(path string) fs.Item {
+	if ui.topDir == nil {
+		return nil
+	}
+	rootPath := ui.topDir.GetPath()
+	rel, err := filepath.Rel(rootPath, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+
+	item := ui.topDir
+	if rel == "." {
+		return item
+	}
+	for _, name := range strings.Split(rel, string(filepath.Separator)) {
+		if !item.IsDir() {
+			return nil
+		}
+		files := item.GetFilesLocked()
+		index, ok := files.FindByName(name)
+		if !ok {
+			return nil
+		}
+		item = files[index]
+	}
+	return item
+}
+
+// completePath completes the last component of the path by names of items in the analyzed tree.
+// It returns the path extended by the common prefix of all candidates and the candidates.
+func (ui *UI) completePath(path string) (string, []string) {
+	sep := string(filepath.Separator)
+	index := strings.LastIndex(path, sep)
+	dirPart, prefix := path[:index+1], path[index+1:]
+
+	dir := ui.findItemByPath(ui.resolvePath(dirPart))
+	if dir == nil || !dir.IsDir() {
+		return path, nil
+	}
+
+	candidates := make([]string, 0)
+	for _, item := range dir.GetFilesLocked() {
+		if !strings.HasPrefix(item.GetName(), prefix) {
+			continue
+		}
+		name := item.GetName()
+		if item.IsDir() {
+			name += sep
+		}
+		candidates = append(candidates, name)
+	}
+	if len(candidates) == 0 {
+		return path, nil
+	}
+	sort.Strings(candidates)
+
+	common := candidates[0]
+	for _, candidate := range candidates[1:] {
+		for !strings.HasPrefix(candidate, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	return dirPart + common, candidates
+}
+
+func (ui *UI) showGoToPath() {
+	if ui.currentDir == nil {
+		return
+	}
+
+	input := tview.NewInputField().
+		SetLabel("Path: ").
+		SetText(ui.currentDirPath + string(filepath.Separator))
+	if !ui.UseColors {
+		input.SetFieldBackgroundColor(tcell.NewRGBColor(100, 100, 100))
+		input.SetFieldTextColor(tcell.NewRGBColor(255, 255, 255))
+	}
+	hint := tview.NewTextView().SetDynamicColors(true)
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(hint, 0, 1, false)
+	flex.SetBorder(true).SetTitle(" Go to path (Tab completes) ")
+
+	closePrompt := func() {
+		ui.pages.RemovePage("goto")
+		ui.app.SetFocus(ui.table)
+	}
+
+	input.SetInputCapture(func(key *tcell.EventKey) *tcell.EventKey {
+		switch key.Key() {
+		case tcell.KeyTab:
+			text, candidates := ui.completePath(input.GetText())
+			input.SetText(text)
+			if len(candidates) > 1 {
+				if len(candidates) > pathCandidatesLimit {
+					candidates = append(candidates[:pathCandidatesLimit], "...")
+				}
+				hint.SetText(tview.Escape(strings.Join(candidates, "  ")))
+			} else {
+				hint.Clear()
+			}
+			return nil
+		case tcell.KeyEsc:
+			closePrompt()
+			return nil
+		case tcell.KeyEnter:
+			path := ui.resolvePath(input.GetText())
+			if ui.findItemByPath(path) == nil {
+				hint.SetText("[red::]" + tview.Escape(path) + " is not in the analyzed tree")
+				return nil
+			}
+			closePrompt()
+			ui.openPath(path)
+			return nil
+		}
+		return key
+	})
+
+	ui.addInputPage("goto", modal(flex, 80, 6))
+	ui.app.SetFocus(input)
+}
+
+// goToPreviousDir returns to the previously visited directory
+func (ui *UI) goToPreviousDir() {
+	if ui.currentDir == nil || ui.previousDirPath == "" {
+		return
+	}
+	dir := ui.findItemByPath(ui.previousDirPath)
+	if dir == nil {
+		ui.showErr("Previous directory is not in the analyzed tree anymore", nil)
+		return
+	}
+
+	// select the directory we are coming from if it's shown
+	ui.openDir(dir, ui.currentDir)
+}
@@ -0,0 +1,153 @@
+package tui
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func getSelectedName(ui *UI) string {
+	row, _ := ui.table.GetSelection()
+	return ui.table.GetCell(row, 0).GetReference().(fs.Item).GetName()
+}
+
+func TestFindItemByPath(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	top := ui.topDir.GetPath()
+
+	assert.Equal(t, ui.topDir, ui.findItemByPath(top))
+	assert.Equal(t, "file", ui.findItemByPath(filepath.Join(top, "nested", "subnested", "file")).GetName())
+	assert.Nil(t, ui.findItemByPath(filepath.Join(top, "nested", "xxx")))
+	assert.Nil(t, ui.findItemByPath(filepath.Join(top, "nested", "file2", "xxx")))
+	assert.Nil(t, ui.findItemByPath(filepath.Dir(top)))
+}
+
+func TestCompletePath(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	sep := string(filepath.Separator)
+
+	// relative to the current directory
+	path, candidates := ui.completePath("ne")
+	assert.Equal(t, "nested"+sep, path)
+	assert.Equal(t, []string{"nested" + sep}, candidates)
+
+	path, candidates = ui.completePath(ui.topDirPath + sep + "nested" + sep)
+	assert.Equal(t, ui.topDirPath+sep+"nested"+sep, path)
+	assert.Equal(t, []string{"file2", "subnested" + sep}, candidates)
+
+	path, candidates = ui.completePath("nested" + sep + "s")
+	assert.Equal(t, "nested"+sep+"subnested"+sep, path)
+	assert.Len(t, candidates, 1)
+
+	path, candidates = ui.completePath("xxx" + sep + "s")
+	assert.Equal(t, "xxx"+sep+"s", path)
+	assert.Empty(t, candidates)
+}
+
+func TestGoToPath(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'p', 0))
+	assert.True(t, ui.pages.HasPage("goto"))
+
+	ui.pages.RemovePage("goto")
+
+	// the prompt works with relative paths too
+	assert.True(t, ui.openPath(ui.resolvePath(filepath.Join("nested", "subnested"))))
+	assert.Equal(t, "subnested", ui.currentDir.GetName())
+
+	// files are selected in their directory
+	assert.True(t, ui.openPath(ui.resolvePath(filepath.Join("..", "file2"))))
+	assert.Equal(t, "nested", ui.currentDir.GetName())
+	assert.Equal(t, "file2", getSelectedName(ui))
+
+	assert.False(t, ui.openPath(ui.resolvePath("xxx")))
+}
+
+func TestGoToPreviousDir(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, '-', 0))
+	assert.Equal(t, "test_dir", ui.currentDir.GetName())
+
+	ui.openPath(filepath.Join(ui.topDirPath, "nested", "subnested"))
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, '-', 0))
+	assert.Equal(t, "test_dir", ui.currentDir.GetName())
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, '-', 0))
+	assert.Equal(t, "subnested", ui.currentDir.GetName())
+
+	// going up selects the directory we are coming from
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyLeft, 0, 0))
+	assert.Equal(t, "nested", ui.currentDir.GetName())
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, '-', 0))
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, '-', 0))
+	assert.Equal(t, "nested", ui.currentDir.GetName())
+	assert.Equal(t, "subnested", getSelectedName(ui))
+}
+
+func TestBookmarks(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	var saved map[string]string
+	ui.SetBookmarks(
+		map[string]string{"sub": filepath.Join(ui.topDirPath, "nested", "subnested")},
+		func(bookmarks map[string]string) error {
+			saved = bookmarks
+			return nil
+		},
+	)
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, '\'', 0))
+	assert.True(t, ui.pages.HasPage("bookmarks"))
+	ui.closeBookmarks()
+	ui.goToBookmark("sub")
+	assert.Equal(t, "subnested", ui.currentDir.GetName())
+
+	ui.addBookmark("top", ui.topDirPath)
+	assert.Equal(t, []string{"sub", "top"}, ui.getBookmarkNames())
+	assert.Equal(t, ui.topDirPath, saved["top"])
+
+	ui.goToBookmark("top")
+	assert.Equal(t, "test_dir", ui.currentDir.GetName())
+
+	ui.deleteBookmark("sub")
+	assert.Equal(t, []string{"top"}, ui.getBookmarkNames())
+	assert.Len(t, saved, 1)
+
+	ui.addBookmark("missing", "/xxx")
+	ui.goToBookmark("missing")
+	assert.True(t, ui.pages.HasPage("error"))
+}
+
+func TestBookmarksSaveError(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.SetBookmarks(nil, func(map[string]string) error {
+		return errors.New("read-only")
+	})
+
+	ui.addBookmark("top", ui.topDirPath)
+	assert.True(t, ui.pages.HasPage("error"))
+}
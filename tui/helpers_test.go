@@ -0,0 +1,10 @@
+package tui
+
+import "github.com/dundee/gdu/v5/internal/testapp"
+
+// drawUpdates runs update draws queued since the last call
+func drawUpdates(ui *UI) {
+	for _, f := range ui.app.(*testapp.MockedApp).PopUpdateDraws() {
+		f()
+	}
+}
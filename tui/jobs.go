@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// backgroundJob is progress of long running operation shown in the status bar
+type backgroundJob struct {
+	label string
+	done  int64
+	total int64 // negative if the progress is not known
+}
+
+// startJob registers job with the given unique key and starts showing its progress
+func (ui *UI) startJob(key, label string, total int64) {
+	ui.workersMut.Lock()
+	ui.jobs[key] = &backgroundJob{label: label, total: total}
+	ui.workersMut.Unlock()
+
+	ui.runStatusWorker()
+}
+
+func (ui *UI) setJobProgress(key string, done int64) {
+	ui.workersMut.Lock()
+	defer ui.workersMut.Unlock()
+	if job, ok := ui.jobs[key]; ok {
+		job.done = done
+	}
+}
+
+func (ui *UI) finishJob(key string) {
+	ui.workersMut.Lock()
+	defer ui.workersMut.Unlock()
+	delete(ui.jobs, key)
+}
+
+// getJobsStatus returns progress of running jobs,
+// it must be called with workersMut locked
+func (ui *UI) getJobsStatus() string {
+	keys := make([]string, 0, len(ui.jobs))
+	for key := range ui.jobs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		job := ui.jobs[key]
+		if job.total < 0 {
+			parts = append(parts, tview.Escape(job.label))
+			continue
+		}
+		percent := 100
+		if job.total > 0 {
+			percent = int(min(100, job.done*100/job.total))
+		}
+		parts = append(parts, fmt.Sprintf("%s %d%%", tview.Escape(job.label), percent))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,500 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// keyAction is name of the action which can be bound to keys in the keymap
+type keyAction string
+
+// Actions which can be bound to keys
+const (
+	keyActionGoInto             keyAction = "go-into"
+	keyActionGoToParent         keyAction = "go-to-parent"
+	keyActionGoToPath           keyAction = "go-to-path"
+	keyActionPreviousDir        keyAction = "previous-dir"
+	keyActionBookmarks          keyAction = "bookmarks"
+	keyActionRescan             keyAction = "rescan"
+	keyActionExport             keyAction = "export"
+	keyActionFilter             keyAction = "filter"
+	keyActionToggleApparentSize keyAction = "toggle-apparent-size"
+	keyActionToggleRelativeSize keyAction = "toggle-relative-size"
+	keyActionToggleItemCount    keyAction = "toggle-item-count"
+	keyActionToggleMtime        keyAction = "toggle-mtime"
+	keyActionTreemap            keyAction = "treemap"
+	keyActionTreeView           keyAction = "tree-view"
+	keyActionSearch             keyAction = "search"
+	keyActionTrash              keyAction = "trash"
+	keyActionShell              keyAction = "shell"
+	keyActionQuit               keyAction = "quit"
+	keyActionQuitPrintPath      keyAction = "quit-print-path"
+	keyActionHelp               keyAction = "help"
+	keyActionDelete             keyAction = "delete"
+	keyActionEmpty              keyAction = "empty"
+	keyActionArchive            keyAction = "archive"
+	keyActionMove               keyAction = "move"
+	keyActionMark               keyAction = "mark"
+	keyActionIgnore             keyAction = "ignore"
+	keyActionView               keyAction = "view"
+	keyActionOpen               keyAction = "open"
+	keyActionInfo               keyAction = "info"
+	keyActionScanInfo           keyAction = "scan-info"
+	keyActionTrend              keyAction = "trend"
+	keyActionSortByName         keyAction = "sort-by-name"
+	keyActionSortBySize         keyAction = "sort-by-size"
+	keyActionSortByCount        keyAction = "sort-by-count"
+	keyActionSortByMtime        keyAction = "sort-by-mtime"
+)
+
+// keyActionDef describes action of the keymap and its default keys
+type keyActionDef struct {
+	action      keyAction
+	keys        []string
+	builtinKeys string // keys handled by the table itself, shown in help only
+	description string
+	section     int
+}
+
+// Sections of the help page
+const (
+	sectionNavigation = iota
+	sectionGeneral
+	sectionItem
+	sectionSorting
+)
+
+const customActionsTitle = "Custom actions:"
+
+var sectionTitles = map[int]string{
+	sectionItem:    "Item under cursor:",
+	sectionSorting: "Sort by (twice toggles asc/desc):",
+}
+
+// keyActionDefs lists all actions in the order of the help page
+var keyActionDefs = []keyActionDef{
+	{action: keyActionGoInto, keys: []string{"l"}, builtinKeys: "enter, right", description: "Go to directory/device"},
+	{action: keyActionGoToParent, keys: []string{"h"}, builtinKeys: "left", description: "Go to parent directory"},
+	{action: keyActionGoToPath, keys: []string{"p"}, description: "Go to path"},
+	{action: keyActionPreviousDir, keys: []string{"-"}, description: "Go to previously visited directory"},
+	{action: keyActionBookmarks, keys: []string{"'"}, description: "Show/add bookmarks"},
+
+	{action: keyActionRescan, keys: []string{"r"}, description: "Rescan current directory", section: sectionGeneral},
+	{action: keyActionExport, keys: []string{"E"}, description: "Export analysis data to file", section: sectionGeneral},
+	{action: keyActionFilter, keys: []string{"/"}, description: "Search items by name", section: sectionGeneral},
+	{action: keyActionToggleApparentSize, keys: []string{"a"}, description: "Toggle between showing disk usage and apparent size", section: sectionGeneral},
+	{action: keyActionToggleRelativeSize, keys: []string{"B"}, description: "Toggle bar alignment to biggest file or directory", section: sectionGeneral},
+	{action: keyActionToggleItemCount, keys: []string{"c"}, description: "Show/hide file count", section: sectionGeneral},
+	{action: keyActionToggleMtime, keys: []string{"m"}, description: "Show/hide latest mtime", section: sectionGeneral},
+	{action: keyActionTreemap, keys: []string{"w"}, description: "Show/hide treemap of current directory", section: sectionGeneral},
+	{action: keyActionTreeView, keys: []string{"t"}, description: "Show/hide tree view of directories", section: sectionGeneral},
+	{action: keyActionSearch, keys: []string{"f"}, description: "Search in the whole analyzed tree", section: sectionGeneral},
+	{action: keyActionTrash, keys: []string{"D"}, description: "Show items moved to the trash", section: sectionGeneral},
+	{action: keyActionShell, keys: []string{"b"}, description: "Spawn shell in current directory", section: sectionGeneral},
+	{action: keyActionQuit, keys: []string{"q"}, description: "Quit gdu", section: sectionGeneral},
+	{action: keyActionQuitPrintPath, keys: []string{"Q"}, description: "Quit gdu and print current directory path", section: sectionGeneral},
+	{action: keyActionHelp, keys: []string{"?"}, description: "Show/hide this help", section: sectionGeneral},
+
+	{action: keyActionDelete, keys: []string{"d"}, description: "Delete file or directory", section: sectionItem},
+	{action: keyActionEmpty, keys: []string{"e"}, description: "Empty file or directory", section: sectionItem},
+	{action: keyActionArchive, keys: []string{"z"}, description: "Pack file, directory or marked items into archive", section: sectionItem},
+	{action: keyActionMove, keys: []string{"x"}, description: "Move file, directory or marked items to another path", section: sectionItem},
+	{action: keyActionMark, keys: []string{"space"}, description: "Mark file or directory for deletion", section: sectionItem},
+	{action: keyActionIgnore, keys: []string{"I"}, description: "Ignore file or directory", section: sectionItem},
+	{action: keyActionView, keys: []string{"v"}, description: "Show content of file", section: sectionItem},
+	{action: keyActionOpen, keys: []string{"o"}, description: "Open file or directory in external program", section: sectionItem},
+	{action: keyActionInfo, keys: []string{"i"}, description: "Show info about item", section: sectionItem},
+	{action: keyActionScanInfo, keys: []string{"S"}, description: "Show info about the analysis", section: sectionItem},
+	{action: keyActionTrend, keys: []string{"T"}, description: "Show usage trend from storage snapshots", section: sectionItem},
+
+	{action: keyActionSortByName, keys: []string{"n"}, description: "Sort by name (asc/desc)", section: sectionSorting},
+	{action: keyActionSortBySize, keys: []string{"s"}, description: "Sort by size (asc/desc)", section: sectionSorting},
+	{action: keyActionSortByCount, keys: []string{"C"}, description: "Sort by file count (asc/desc)", section: sectionSorting},
+	{action: keyActionSortByMtime, keys: []string{"M"}, description: "Sort by mtime (asc/desc)", section: sectionSorting},
+}
+
+// dialogKeyDef describes key handled by a dialog itself, it can't be changed in the keymap
+type dialogKeyDef struct {
+	dialog      string
+	key         string
+	description string
+}
+
+// dialogKeyDefs lists keys handled by the dialogs in the order of the help page
+var dialogKeyDefs = []dialogKeyDef{
+	{dialog: "trash", key: "r", description: "Restore item in trash"},
+	{dialog: "trash", key: "E", description: "Empty trash"},
+	{dialog: "bookmarks", key: "a", description: "Add current directory to bookmarks"},
+	{dialog: "diff", key: "u", description: "Show/hide unchanged items in comparison"},
+}
+
+// dialogActions lists actions of the keymap handled also by the dialogs,
+// they can't be bound to the keys handled by the dialog itself
+var dialogActions = map[string][]keyAction{
+	"trash":     {keyActionQuit, keyActionDelete},
+	"bookmarks": {keyActionQuit, keyActionDelete},
+	"diff":      {keyActionQuit, keyActionGoToParent, keyActionGoInto, keyActionToggleApparentSize},
+}
+
+const dialogKeysTitle = "In dialogs:"
+
+// reservedKeys are handled by the table or the UI itself and can't be bound
+var reservedKeys = []string{
+	"up", "down", "left", "right", "pgup", "pgdn", "home", "end",
+	"j", "k", "g", "G", "enter", "tab", "esc", "ctrl+z",
+}
+
+// namedKeys maps names of the special keys used in the keymap to tcell keys
+var namedKeys = map[string]tcell.Key{
+	"enter":     tcell.KeyEnter,
+	"tab":       tcell.KeyTab,
+	"backtab":   tcell.KeyBacktab,
+	"esc":       tcell.KeyEsc,
+	"backspace": tcell.KeyBackspace2,
+	"delete":    tcell.KeyDelete,
+	"insert":    tcell.KeyInsert,
+	"home":      tcell.KeyHome,
+	"end":       tcell.KeyEnd,
+	"pgup":      tcell.KeyPgUp,
+	"pgdn":      tcell.KeyPgDn,
+	"up":        tcell.KeyUp,
+	"down":      tcell.KeyDown,
+	"left":      tcell.KeyLeft,
+	"right":     tcell.KeyRight,
+}
+
+// keyPress is a single key with modifiers
+type keyPress struct {
+	key tcell.Key
+	ch  rune
+	mod tcell.ModMask
+}
+
+// parseKey parses key written as e.g. "d", "space", "f5", "ctrl+d" or "alt+shift+left"
+func parseKey(s string) (keyPress, error) {
+	var mod tcell.ModMask
+	name := s
+	for {
+		prefix, rest, found := strings.Cut(name, "+")
+		if !found || rest == "" {
+			break
+		}
+		switch strings.ToLower(prefix) {
+		case "ctrl":
+			mod |= tcell.ModCtrl
+		case "alt":
+			mod |= tcell.ModAlt
+		case "shift":
+			mod |= tcell.ModShift
+		default:
+			return keyPress{}, fmt.Errorf("unknown modifier in key %q", s)
+		}
+		name = rest
+	}
+
+	if utf8.RuneCountInString(name) == 1 {
+		ch, _ := utf8.DecodeRuneInString(name)
+		return runeKeyPress(ch, mod, s)
+	}
+
+	lower := strings.ToLower(name)
+	if lower == "space" {
+		return runeKeyPress(' ', mod, s)
+	}
+	if key, ok := namedKeys[lower]; ok {
+		if key == tcell.KeyTab && mod == tcell.ModShift {
+			return keyPress{key: tcell.KeyBacktab}, nil
+		}
+		return keyPress{key: key, mod: mod}, nil
+	}
+	if n, err := strconv.Atoi(strings.TrimPrefix(lower, "f")); err == nil &&
+		strings.HasPrefix(lower, "f") && n >= 1 && n <= 12 {
+		return keyPress{key: tcell.KeyF1 + tcell.Key(n-1), mod: mod}, nil
+	}
+	return keyPress{}, fmt.Errorf("unknown key %q", s)
+}
+
+func runeKeyPress(ch rune, mod tcell.ModMask, s string) (keyPress, error) {
+	switch {
+	case mod&tcell.ModCtrl != 0:
+		if mod&tcell.ModShift != 0 {
+			return keyPress{}, fmt.Errorf("ctrl and shift can't be combined in key %q", s)
+		}
+		lower := ch | 0x20
+		if lower < 'a' || lower > 'z' {
+			return keyPress{}, fmt.Errorf("only letters can be combined with ctrl in key %q", s)
+		}
+		return keyPress{key: tcell.KeyCtrlA + tcell.Key(lower-'a'), mod: mod & tcell.ModAlt}, nil
+	case mod&tcell.ModShift != 0:
+		upper := []rune(strings.ToUpper(string(ch)))[0]
+		if upper == ch {
+			return keyPress{}, fmt.Errorf("shift can be combined only with letters in key %q", s)
+		}
+		ch = upper
+	}
+	return keyPress{key: tcell.KeyRune, ch: ch, mod: mod & tcell.ModAlt}, nil
+}
+
+// matches checks if the event is the key press
+func (k keyPress) matches(event *tcell.EventKey) bool {
+	switch {
+	case k.key == tcell.KeyRune:
+		return event.Key() == tcell.KeyRune && event.Rune() == k.ch &&
+			event.Modifiers()&tcell.ModAlt == k.mod
+	case k.key >= tcell.KeyCtrlA && k.key <= tcell.KeyCtrlZ:
+		return event.Key() == k.key && event.Modifiers()&tcell.ModAlt == k.mod
+	case k.key == tcell.KeyBackspace2:
+		return (event.Key() == tcell.KeyBackspace || event.Key() == tcell.KeyBackspace2) &&
+			event.Modifiers() == k.mod
+	}
+	return event.Key() == k.key && event.Modifiers() == k.mod
+}
+
+// CustomAction is a user defined command run on the selected item
+type CustomAction struct {
+	// Key runs the action, it's written the same way as keys of the keymap
+	Key   string
+	Label string
+	// Command is run by the shell, placeholders {path}, {name}, {dir} and {marked}
+	// are replaced by quoted paths of the selected item, its name, the current directory
+	// and the marked items (or the selected item if nothing is marked)
+	Command string
+	// Foreground suspends the UI while the command runs,
+	// otherwise it runs in the background and its output is shown afterwards
+	Foreground bool
+	// Rescan rescans the current directory after the command finishes
+	Rescan bool
+}
+
+// keyBinding binds the key to the action or to the custom action
+type keyBinding struct {
+	key    keyPress
+	action keyAction
+	custom *CustomAction
+}
+
+func (b keyBinding) name() string {
+	if b.custom != nil {
+		return b.custom.Label
+	}
+	return string(b.action)
+}
+
+// Keymap maps keys to actions of the interactive mode
+type Keymap struct {
+	bindings []keyBinding
+	keys     map[keyAction][]string
+	custom   []*CustomAction
+}
+
+// DefaultKeymap returns keymap with the default key bindings
+func DefaultKeymap() *Keymap {
+	keymap, err := ParseKeymap(nil)
+	if err != nil {
+		panic(err) // default keymap is always valid
+	}
+	return keymap
+}
+
+// ParseKeymap creates keymap from the default bindings overridden by the given ones.
+// Keys of the map are names of the actions, values are lists of keys bound to the action,
+// empty list unbinds the action.
+// Error is returned for unknown actions, invalid keys and keys bound to more actions.
+func ParseKeymap(overrides map[string][]string) (*Keymap, error) {
+	keymap := &Keymap{keys: make(map[keyAction][]string)}
+	for _, def := range keyActionDefs {
+		keymap.keys[def.action] = def.keys
+	}
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		action := keyAction(name)
+		if _, ok := keymap.keys[action]; !ok {
+			return nil, fmt.Errorf("unknown action %q", name)
+		}
+		keymap.keys[action] = overrides[name]
+	}
+
+	var errs []string
+	for _, def := range keyActionDefs {
+		for _, name := range keymap.keys[def.action] {
+			key, err := parseKey(name)
+			if err != nil {
+				return nil, fmt.Errorf("action %q: %w", def.action, err)
+			}
+			errs = append(errs, keymap.bind(name, keyBinding{key: key, action: def.action})...)
+		}
+	}
+	errs = append(errs, keymap.dialogConflicts()...)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("conflicting key bindings: %s", strings.Join(errs, ", "))
+	}
+	return keymap, nil
+}
+
+// AddCustomActions binds keys to the custom actions.
+// Error is returned for actions without command, invalid keys and keys already bound.
+func (k *Keymap) AddCustomActions(actions []CustomAction) error {
+	var errs []string
+	for _, action := range actions {
+		if action.Command == "" {
+			return fmt.Errorf("custom action %q has no command", action.Label)
+		}
+		if action.Label == "" {
+			action.Label = action.Command
+		}
+		key, err := parseKey(action.Key)
+		if err != nil {
+			return fmt.Errorf("custom action %q: %w", action.Label, err)
+		}
+		custom := &action
+		k.custom = append(k.custom, custom)
+		errs = append(errs, k.bind(action.Key, keyBinding{key: key, custom: custom})...)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("conflicting key bindings: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// bind adds the binding and returns descriptions of its conflicts with reserved or already bound keys
+func (k *Keymap) bind(name string, binding keyBinding) []string {
+	var conflicts []string
+	for _, reserved := range reservedKeys {
+		if key, err := parseKey(reserved); err == nil && key == binding.key {
+			conflicts = append(conflicts, fmt.Sprintf(
+				"key %q of action %q is reserved for %q", name, binding.name(), reserved,
+			))
+		}
+	}
+	for _, other := range k.bindings {
+		if other.key == binding.key {
+			conflicts = append(conflicts, fmt.Sprintf(
+				"key %q is bound to both %q and %q", name, other.name(), binding.name(),
+			))
+		}
+	}
+	k.bindings = append(k.bindings, binding)
+	return conflicts
+}
+
+// dialogConflicts returns descriptions of actions bound to keys handled by the dialogs using the actions
+func (k *Keymap) dialogConflicts() []string {
+	var conflicts []string
+	for _, def := range dialogKeyDefs {
+		dialogKey, err := parseKey(def.key)
+		if err != nil {
+			continue
+		}
+		for _, action := range dialogActions[def.dialog] {
+			for _, name := range k.keys[action] {
+				if key, err := parseKey(name); err == nil && key == dialogKey {
+					conflicts = append(conflicts, fmt.Sprintf(
+						"key %q of action %q is reserved for %q in %s", name, action, def.description, def.dialog,
+					))
+				}
+			}
+		}
+	}
+	return conflicts
+}
+
+// keyName returns the first key bound to the action or empty string if the action is unbound
+func (k *Keymap) keyName(action keyAction) string {
+	if keys := k.keys[action]; len(keys) > 0 {
+		return keys[0]
+	}
+	return ""
+}
+
+// keyHint describes key in the title of a dialog
+type keyHint struct {
+	key  string
+	text string
+}
+
+// formatKeyHints joins the hints, hints of unbound keys are left out
+func formatKeyHints(hints ...keyHint) string {
+	parts := make([]string, 0, len(hints))
+	for _, hint := range hints {
+		if hint.key != "" {
+			parts = append(parts, hint.key+" "+hint.text)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// action returns the action bound to the key of the event or empty string
+func (k *Keymap) action(event *tcell.EventKey) keyAction {
+	for _, binding := range k.bindings {
+		if binding.key.matches(event) {
+			return binding.action
+		}
+	}
+	return ""
+}
+
+// customAction returns the custom action bound to the key of the event or nil
+func (k *Keymap) customAction(event *tcell.EventKey) *CustomAction {
+	for _, binding := range k.bindings {
+		if binding.key.matches(event) {
+			return binding.custom
+		}
+	}
+	return nil
+}
+
+// formatHelp returns the help text listing keys of all actions
+func (k *Keymap) formatHelp() string {
+	lines := []string{
+		formatHelpLine("up/down, k/j", "Move cursor up/down"),
+		formatHelpLine("pgup/pgdn, g/G", "Move cursor top/bottom"),
+	}
+	section := sectionNavigation
+	for _, def := range keyActionDefs {
+		if def.section != section {
+			lines = append(lines, "")
+			if title, ok := sectionTitles[def.section]; ok {
+				lines = append(lines, title)
+			}
+			section = def.section
+		}
+
+		keys := k.keys[def.action]
+		if def.builtinKeys != "" {
+			keys = append([]string{def.builtinKeys}, keys...)
+		}
+		if len(keys) == 0 {
+			keys = []string{"(unbound)"}
+		}
+		lines = append(lines, formatHelpLine(strings.Join(keys, ", "), def.description))
+	}
+
+	lines = append(lines, "", dialogKeysTitle)
+	for _, def := range dialogKeyDefs {
+		lines = append(lines, formatHelpLine(def.key, def.description))
+	}
+
+	if len(k.custom) > 0 {
+		lines = append(lines, "", customActionsTitle)
+		for _, action := range k.custom {
+			lines = append(lines, formatHelpLine(action.Key, tview.Escape(action.Label)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatHelpLine(keys, description string) string {
+	return fmt.Sprintf("%s[::b]%s     [white:black:-]%s",
+		strings.Repeat(" ", max(0, 16-len(keys))), keys, description)
+}
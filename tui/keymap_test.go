@@ -0,0 +1,196 @@
+package tui
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testapp"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		event *tcell.EventKey
+	}{
+		{"d", tcell.NewEventKey(tcell.KeyRune, 'd', 0)},
+		{"D", tcell.NewEventKey(tcell.KeyRune, 'D', 0)},
+		{"shift+d", tcell.NewEventKey(tcell.KeyRune, 'D', tcell.ModShift)},
+		{"space", tcell.NewEventKey(tcell.KeyRune, ' ', 0)},
+		{"alt+d", tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModAlt)},
+		{"ctrl+d", tcell.NewEventKey(tcell.KeyCtrlD, 0, tcell.ModCtrl)},
+		{"Ctrl+D", tcell.NewEventKey(tcell.KeyCtrlD, 0, tcell.ModCtrl)},
+		{"delete", tcell.NewEventKey(tcell.KeyDelete, 0, 0)},
+		{"shift+delete", tcell.NewEventKey(tcell.KeyDelete, 0, tcell.ModShift)},
+		{"backspace", tcell.NewEventKey(tcell.KeyBackspace, 0, 0)},
+		{"f5", tcell.NewEventKey(tcell.KeyF5, 0, 0)},
+		{"+", tcell.NewEventKey(tcell.KeyRune, '+', 0)},
+		{"alt++", tcell.NewEventKey(tcell.KeyRune, '+', tcell.ModAlt)},
+	}
+
+	for _, tt := range tests {
+		key, err := parseKey(tt.name)
+		assert.Nil(t, err, tt.name)
+		assert.True(t, key.matches(tt.event), tt.name)
+	}
+}
+
+func TestParseKeyDoesNotMatchOtherKeys(t *testing.T) {
+	key, err := parseKey("d")
+	assert.Nil(t, err)
+	assert.False(t, key.matches(tcell.NewEventKey(tcell.KeyRune, 'D', 0)))
+	assert.False(t, key.matches(tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModAlt)))
+	assert.False(t, key.matches(tcell.NewEventKey(tcell.KeyCtrlD, 0, tcell.ModCtrl)))
+
+	key, err = parseKey("delete")
+	assert.Nil(t, err)
+	assert.False(t, key.matches(tcell.NewEventKey(tcell.KeyDelete, 0, tcell.ModCtrl)))
+}
+
+func TestParseInvalidKey(t *testing.T) {
+	for _, name := range []string{"", "foo", "hyper+d", "ctrl+1", "shift+1", "ctrl+shift+d", "f13"} {
+		_, err := parseKey(name)
+		assert.NotNil(t, err, name)
+	}
+}
+
+func TestParseKeymap(t *testing.T) {
+	keymap, err := ParseKeymap(map[string][]string{
+		"delete": {"ctrl+d", "delete"},
+		"move":   {"d"},
+		"empty":  {},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, keyActionDelete, keymap.action(tcell.NewEventKey(tcell.KeyCtrlD, 0, tcell.ModCtrl)))
+	assert.Equal(t, keyActionDelete, keymap.action(tcell.NewEventKey(tcell.KeyDelete, 0, 0)))
+	assert.Equal(t, keyActionMove, keymap.action(tcell.NewEventKey(tcell.KeyRune, 'd', 0)))
+	assert.Equal(t, keyAction(""), keymap.action(tcell.NewEventKey(tcell.KeyRune, 'e', 0)))
+	assert.Equal(t, keyActionSortBySize, keymap.action(tcell.NewEventKey(tcell.KeyRune, 's', 0)))
+}
+
+func TestParseKeymapWithUnknownAction(t *testing.T) {
+	_, err := ParseKeymap(map[string][]string{"fly": {"y"}})
+	assert.ErrorContains(t, err, `unknown action "fly"`)
+}
+
+func TestParseKeymapWithInvalidKey(t *testing.T) {
+	_, err := ParseKeymap(map[string][]string{"delete": {"foo"}})
+	assert.ErrorContains(t, err, `action "delete": unknown key "foo"`)
+}
+
+func TestParseKeymapWithConflicts(t *testing.T) {
+	_, err := ParseKeymap(map[string][]string{
+		"delete": {"x"},
+		"view":   {"j"},
+	})
+	assert.ErrorContains(t, err, `key "x" is bound to both "delete" and "move"`)
+	assert.ErrorContains(t, err, `key "j" of action "view" is reserved for "j"`)
+}
+
+func TestParseKeymapWithDialogConflicts(t *testing.T) {
+	_, err := ParseKeymap(map[string][]string{
+		"delete": {"r"},
+		"rescan": {"R"},
+		"quit":   {"u"},
+	})
+	assert.ErrorContains(t, err, `key "r" of action "delete" is reserved for "Restore item in trash" in trash`)
+	assert.ErrorContains(t, err, `key "u" of action "quit" is reserved for "Show/hide unchanged items in comparison" in diff`)
+
+	// keys of dialogs can be bound to actions not used by the dialog
+	_, err = ParseKeymap(map[string][]string{
+		"rescan":       {"R"},
+		"tree-view":    {"r"},
+		"toggle-mtime": {"u"},
+	})
+	assert.Nil(t, err)
+}
+
+func TestHelpFromKeymap(t *testing.T) {
+	keymap, err := ParseKeymap(map[string][]string{
+		"delete": {"ctrl+d", "delete"},
+		"empty":  {},
+	})
+	assert.Nil(t, err)
+
+	app := testapp.CreateMockedApp(false)
+	ui := CreateUI(app, nil, &bytes.Buffer{}, false, true, false, false, false)
+	ui.SetKeymap(keymap)
+
+	help := ui.formatHelpTextFor()
+	assert.Contains(t, help, "[::b]ctrl+d, delete     [white:black:-]Delete file or directory")
+	assert.Contains(t, help, "[::b](unbound)     [white:black:-]Empty file or directory")
+	assert.Contains(t, help, "[::b]enter, right, l     [white:black:-]Go to directory/device")
+	assert.Contains(t, help, "In dialogs:\n")
+	assert.Contains(t, help, "[::b]E     [white:black:-]Empty trash")
+}
+
+func TestReboundKeys(t *testing.T) {
+	keymap, err := ParseKeymap(map[string][]string{
+		"sort-by-name": {"N"},
+		"quit":         {"ctrl+q"},
+	})
+	assert.Nil(t, err)
+
+	app := testapp.CreateMockedApp(false)
+	ui := CreateUI(app, nil, &bytes.Buffer{}, false, true, false, false, false)
+	ui.SetKeymap(keymap)
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'N', 0))
+	assert.Equal(t, "name", ui.sortBy)
+
+	assert.NotNil(t, ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'q', 0)))
+	assert.Nil(t, ui.keyPressed(tcell.NewEventKey(tcell.KeyCtrlQ, 0, tcell.ModCtrl)))
+}
+
+func TestKeysSentToInputPage(t *testing.T) {
+	app := testapp.CreateMockedApp(false)
+	ui := CreateUI(app, nil, &bytes.Buffer{}, false, true, false, false, false)
+
+	ui.addInputPage("form", tview.NewBox())
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'n', 0))
+	assert.Equal(t, "size", ui.sortBy)
+
+	ui.pages.RemovePage("form")
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'n', 0))
+	assert.Equal(t, "name", ui.sortBy)
+}
+
+func TestAddCustomActions(t *testing.T) {
+	keymap := DefaultKeymap()
+	err := keymap.AddCustomActions([]CustomAction{
+		{Key: "y", Label: "Open in ranger", Command: "ranger {path}"},
+		{Key: "alt+d", Command: "docker system df"},
+	})
+	assert.Nil(t, err)
+
+	action := keymap.customAction(tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModAlt))
+	assert.Equal(t, "docker system df", action.Label)
+	assert.Nil(t, keymap.customAction(tcell.NewEventKey(tcell.KeyRune, 'd', 0)))
+	assert.Equal(t, keyAction(""), keymap.action(tcell.NewEventKey(tcell.KeyRune, 'y', 0)))
+
+	help := keymap.formatHelp()
+	assert.Contains(t, help, "Custom actions:\n")
+	assert.Contains(t, help, "[::b]y     [white:black:-]Open in ranger")
+}
+
+func TestAddConflictingCustomActions(t *testing.T) {
+	err := DefaultKeymap().AddCustomActions([]CustomAction{
+		{Key: "x", Label: "Upload", Command: "upload {path}"},
+		{Key: "y", Label: "Ranger", Command: "ranger"},
+		{Key: "y", Label: "Df", Command: "df"},
+	})
+	assert.ErrorContains(t, err, `key "x" is bound to both "move" and "Upload"`)
+	assert.ErrorContains(t, err, `key "y" is bound to both "Ranger" and "Df"`)
+}
+
+func TestAddInvalidCustomActions(t *testing.T) {
+	err := DefaultKeymap().AddCustomActions([]CustomAction{{Key: "y", Label: "Upload"}})
+	assert.ErrorContains(t, err, `custom action "Upload" has no command`)
+
+	err = DefaultKeymap().AddCustomActions([]CustomAction{{Key: "foo", Label: "Upload", Command: "upload"}})
+	assert.ErrorContains(t, err, `custom action "Upload": unknown key "foo"`)
+}
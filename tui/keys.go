@@ -14,7 +14,7 @@ func (ui *UI) keyPressed(key *tcell.EventKey) *tcell.EventKey {
 		return nil
 	}
 
-	if ui.pages.HasPage("file") || ui.pages.HasPage("export") {
+	if ui.isInputPageOpen() {
 		return key // send event to primitive
 	}
 	if ui.filtering {
@@ -48,11 +48,24 @@ func (ui *UI) keyPressed(key *tcell.EventKey) *tcell.EventKey {
 	if key == nil {
 		return nil
 	}
+	key = ui.handleScanInfo(key)
+	if key == nil {
+		return nil
+	}
+	key = ui.handleTrend(key)
+	if key == nil {
+		return nil
+	}
 
-	if ui.pages.HasPage("help") {
+	if ui.pages.HasPage("help") || ui.pages.HasPage("scaninfo") || ui.pages.HasPage("trend") {
 		return key
 	}
 
+	key = ui.handleTreemap(key)
+	if key == nil {
+		return nil
+	}
+
 	key = ui.handleShell(key)
 	if key == nil {
 		return nil
@@ -68,11 +81,33 @@ func (ui *UI) keyPressed(key *tcell.EventKey) *tcell.EventKey {
 		return nil
 	}
 
+	key = ui.handleCustomActions(key)
+	if key == nil {
+		return nil
+	}
+
 	return ui.handleMainActions(key)
 }
 
+// addInputPage shows page which handles keys by itself,
+// all keys are sent to it while it's open
+func (ui *UI) addInputPage(name string, item tview.Primitive) {
+	ui.inputPages[name] = struct{}{}
+	ui.pages.AddPage(name, item, true, true)
+}
+
+// isInputPageOpen returns true if any page added by addInputPage is open
+func (ui *UI) isInputPageOpen() bool {
+	for name := range ui.inputPages {
+		if ui.pages.HasPage(name) {
+			return true
+		}
+	}
+	return false
+}
+
 func (ui *UI) handleClosingModals(key *tcell.EventKey) *tcell.EventKey {
-	if key.Key() == tcell.KeyEsc || key.Rune() == 'q' {
+	if key.Key() == tcell.KeyEsc || ui.keymap.action(key) == keyActionQuit {
 		if ui.pages.HasPage("help") {
 			ui.pages.RemovePage("help")
 			ui.app.SetFocus(ui.table)
@@ -83,15 +118,25 @@ func (ui *UI) handleClosingModals(key *tcell.EventKey) *tcell.EventKey {
 			ui.app.SetFocus(ui.table)
 			return nil
 		}
+		if ui.pages.HasPage("scaninfo") {
+			ui.pages.RemovePage("scaninfo")
+			ui.app.SetFocus(ui.table)
+			return nil
+		}
+		if ui.pages.HasPage("trend") {
+			ui.pages.RemovePage("trend")
+			ui.app.SetFocus(ui.table)
+			return nil
+		}
 	}
 	return key
 }
 
 func (ui *UI) handleConfirmation(key *tcell.EventKey) *tcell.EventKey {
-	if key.Rune() == 'h' {
+	switch ui.keymap.action(key) {
+	case keyActionGoToParent:
 		return tcell.NewEventKey(tcell.KeyLeft, 0, 0)
-	}
-	if key.Rune() == 'l' {
+	case keyActionGoInto:
 		return tcell.NewEventKey(tcell.KeyRight, 0, 0)
 	}
 	return key
@@ -99,12 +144,12 @@ func (ui *UI) handleConfirmation(key *tcell.EventKey) *tcell.EventKey {
 
 func (ui *UI) handleInfoPageEvents(key *tcell.EventKey) *tcell.EventKey {
 	if ui.pages.HasPage("info") {
-		switch key.Rune() {
-		case 'i':
+		switch ui.keymap.action(key) {
+		case keyActionInfo:
 			ui.pages.RemovePage("info")
 			ui.app.SetFocus(ui.table)
 			return nil
-		case '?':
+		case keyActionHelp:
 			return nil
 		}
 
@@ -146,12 +191,12 @@ func (ui *UI) handleCtrlZ(key *tcell.EventKey) *tcell.EventKey {
 }
 
 func (ui *UI) handleQuit(key *tcell.EventKey) *tcell.EventKey {
-	switch key.Rune() {
-	case 'Q':
+	switch ui.keymap.action(key) {
+	case keyActionQuitPrintPath:
 		ui.app.Stop()
 		fmt.Fprintf(ui.output, "%s\n", ui.currentDirPath)
 		return nil
-	case 'q':
+	case keyActionQuit:
 		ui.app.Stop()
 		return nil
 	}
@@ -159,7 +204,7 @@ func (ui *UI) handleQuit(key *tcell.EventKey) *tcell.EventKey {
 }
 
 func (ui *UI) handleHelp(key *tcell.EventKey) *tcell.EventKey {
-	if key.Rune() == '?' {
+	if ui.keymap.action(key) == keyActionHelp {
 		if ui.pages.HasPage("help") {
 			ui.pages.RemovePage("help")
 			ui.app.SetFocus(ui.table)
@@ -171,26 +216,40 @@ func (ui *UI) handleHelp(key *tcell.EventKey) *tcell.EventKey {
 	return key
 }
 
+func (ui *UI) handleScanInfo(key *tcell.EventKey) *tcell.EventKey {
+	if ui.keymap.action(key) == keyActionScanInfo && !ui.pages.HasPage("help") {
+		if ui.pages.HasPage("scaninfo") {
+			ui.pages.RemovePage("scaninfo")
+			ui.app.SetFocus(ui.table)
+			return nil
+		}
+		ui.showScanInfo()
+		return nil
+	}
+	return key
+}
+
+func (ui *UI) handleTrend(key *tcell.EventKey) *tcell.EventKey {
+	if ui.keymap.action(key) == keyActionTrend && !ui.pages.HasPage("help") && !ui.pages.HasPage("scaninfo") {
+		if ui.pages.HasPage("trend") {
+			ui.pages.RemovePage("trend")
+			ui.app.SetFocus(ui.table)
+			return nil
+		}
+		ui.showTrend()
+		return nil
+	}
+	return key
+}
+
 func (ui *UI) handleShell(key *tcell.EventKey) *tcell.EventKey {
-	if key.Rune() == 'b' {
+	if ui.keymap.action(key) == keyActionShell {
 		if ui.isInArchive() {
 			ui.showErr("Spawning shell is not supported in archives", nil)
 			return nil
 		}
 		if ui.noSpawnShell {
-			previousHeaderText := ui.header.GetText(false)
-
-			// show feedback to user
-			ui.header.SetText(" Shell spawning is disabled!")
-
-			go func() {
-				time.Sleep(2 * time.Second)
-				ui.app.QueueUpdateDraw(func() {
-					ui.header.Clear()
-					ui.header.SetText(previousHeaderText)
-				})
-			}()
-
+			ui.showHeaderFeedback(" Shell spawning is disabled!")
 			return nil
 		}
 		ui.spawnShell()
@@ -199,14 +258,37 @@ func (ui *UI) handleShell(key *tcell.EventKey) *tcell.EventKey {
 	return key
 }
 
+// showHeaderFeedback shows the text in the header for a while
+func (ui *UI) showHeaderFeedback(text string) {
+	previousHeaderText := ui.header.GetText(false)
+	ui.header.SetText(text)
+
+	go func() {
+		time.Sleep(2 * time.Second)
+		ui.app.QueueUpdateDraw(func() {
+			ui.header.Clear()
+			ui.header.SetText(previousHeaderText)
+		})
+	}()
+}
+
 func (ui *UI) handleLeftRight(key *tcell.EventKey) *tcell.EventKey {
-	if key.Rune() == 'h' || key.Key() == tcell.KeyLeft {
-		ui.handleLeft()
+	action := ui.keymap.action(key)
+	if action == keyActionGoToParent || key.Key() == tcell.KeyLeft {
+		if ui.treeView && ui.currentDir != nil {
+			ui.handleTreeLeft()
+		} else {
+			ui.handleLeft()
+		}
 		return nil
 	}
 
-	if key.Rune() == 'l' || key.Key() == tcell.KeyRight {
-		ui.handleRight()
+	if action == keyActionGoInto || key.Key() == tcell.KeyRight {
+		if ui.treeView && ui.currentDir != nil {
+			ui.handleTreeRight()
+		} else {
+			ui.handleRight()
+		}
 		return nil
 	}
 	return key
@@ -223,75 +305,87 @@ func (ui *UI) handleFiltering(key *tcell.EventKey) *tcell.EventKey {
 
 // nolint: funlen // Why: there's a lot of options to handle
 func (ui *UI) handleMainActions(key *tcell.EventKey) *tcell.EventKey {
-	switch key.Rune() {
-	case 'd':
+	switch action := ui.keymap.action(key); action {
+	case keyActionDelete:
 		if ui.isInArchive() {
 			ui.showErr("Deletion is not supported in archives", nil)
 			return nil
 		}
 		ui.handleDelete(false)
-	case 'e':
+	case keyActionEmpty:
 		if ui.isInArchive() {
 			ui.showErr("Deletion is not supported in archives", nil)
 			return nil
 		}
 		ui.handleDelete(true)
-	case 'v':
+	case keyActionArchive:
+		ui.showArchiveForm()
+		return nil
+	case keyActionMove:
+		ui.showMoveForm()
+		return nil
+	case keyActionView:
 		if ui.isInArchive() {
 			ui.showErr("Viewing content is not supported in archives", nil)
 			return nil
 		}
 		ui.showFile()
-	case 'o':
+	case keyActionOpen:
 		if ui.noSpawnShell {
-			previousHeaderText := ui.header.GetText(false)
-
-			// show feedback to user
-			ui.header.SetText(" Opening items is disabled!")
-
-			go func() {
-				time.Sleep(2 * time.Second)
-				ui.app.QueueUpdateDraw(func() {
-					ui.header.Clear()
-					ui.header.SetText(previousHeaderText)
-				})
-			}()
+			ui.showHeaderFeedback(" Opening items is disabled!")
 			return nil
 		}
 		ui.openItem()
-	case 'i':
+	case keyActionInfo:
 		ui.showInfo()
-	case 'a', 'B', 'c', 'm':
-		ui.handleToggles(key)
-	case 'r':
+	case keyActionToggleApparentSize, keyActionToggleRelativeSize,
+		keyActionToggleItemCount, keyActionToggleMtime:
+		ui.handleToggles(action)
+	case keyActionRescan:
 		if ui.currentDir != nil {
 			ui.rescanDir()
 		}
-	case 'E':
+	case keyActionExport:
 		ui.confirmExport()
 		return nil
-	case 's', 'C', 'n', 'M':
-		ui.handleSorting(key)
-	case '/':
+	case keyActionSortBySize, keyActionSortByCount, keyActionSortByName, keyActionSortByMtime:
+		ui.handleSorting(action)
+	case keyActionFilter:
 		ui.showFilterInput()
 		return nil
-	case ' ':
+	case keyActionSearch:
+		ui.showSearch()
+		return nil
+	case keyActionGoToPath:
+		ui.showGoToPath()
+		return nil
+	case keyActionBookmarks:
+		ui.showBookmarks()
+		return nil
+	case keyActionPreviousDir:
+		ui.goToPreviousDir()
+	case keyActionTrash:
+		ui.showTrash()
+		return nil
+	case keyActionMark:
 		ui.handleMark()
-	case 'I':
+	case keyActionIgnore:
 		ui.ignoreItem()
+	case keyActionTreeView:
+		ui.toggleTreeView()
 	}
 	return key
 }
 
-func (ui *UI) handleToggles(key *tcell.EventKey) {
-	switch key.Rune() {
-	case 'a':
+func (ui *UI) handleToggles(action keyAction) {
+	switch action {
+	case keyActionToggleApparentSize:
 		ui.ShowApparentSize = !ui.ShowApparentSize
-	case 'B':
+	case keyActionToggleRelativeSize:
 		ui.ShowRelativeSize = !ui.ShowRelativeSize
-	case 'c':
+	case keyActionToggleItemCount:
 		ui.showItemCount = !ui.showItemCount
-	case 'm':
+	case keyActionToggleMtime:
 		ui.showMtime = !ui.showMtime
 	}
 	if ui.currentDir != nil {
@@ -301,15 +395,15 @@ func (ui *UI) handleToggles(key *tcell.EventKey) {
 	}
 }
 
-func (ui *UI) handleSorting(key *tcell.EventKey) {
-	switch key.Rune() {
-	case 's':
+func (ui *UI) handleSorting(action keyAction) {
+	switch action {
+	case keyActionSortBySize:
 		ui.setSorting("size")
-	case 'C':
+	case keyActionSortByCount:
 		ui.setSorting("itemCount")
-	case 'n':
+	case keyActionSortByName:
 		ui.setSorting("name")
-	case 'M':
+	case keyActionSortByMtime:
 		ui.setSorting("mtime")
 	}
 }
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"sort"
 	"strconv"
 
 	"golang.org/x/text/cases"
@@ -46,6 +47,7 @@ func (ui *UI) deleteMarked(shouldEmpty bool) {
 	}
 
 	modal := tview.NewModal()
+	ui.styleModal(modal, tview.Styles.ContrastBackgroundColor)
 	ui.pages.AddPage(acting, modal, true, true)
 
 	currentRow, _ := ui.table.GetSelection()
@@ -76,7 +78,7 @@ func (ui *UI) deleteMarked(shouldEmpty bool) {
 					deleteItems = append(deleteItems, file)
 				}
 			} else {
-				currentDir = ui.currentDir
+				currentDir = ui.getParentDir(one)
 				deleteItems = append(deleteItems, one)
 			}
 
@@ -116,7 +118,7 @@ func (ui *UI) confirmDeletionMarked(shouldEmpty bool) {
 	if shouldEmpty {
 		action = actionEmpty
 	} else {
-		action = actionDelete
+		action = ui.getDeleteAction()
 	}
 
 	modal := tview.NewModal().
@@ -138,12 +140,32 @@ func (ui *UI) confirmDeletionMarked(shouldEmpty bool) {
 			ui.pages.RemovePage("confirm")
 		})
 
-	if !ui.UseColors {
-		modal.SetBackgroundColor(tcell.ColorGray)
-	} else {
-		modal.SetBackgroundColor(tcell.ColorBlack)
-	}
 	modal.SetBorderColor(tcell.ColorDefault)
+	ui.styleModal(modal, tcell.ColorBlack)
 
 	ui.pages.AddPage("confirm", modal, true, true)
 }
+
+// getSelectedOrMarkedItems returns marked items or the selected item if nothing is marked
+func (ui *UI) getSelectedOrMarkedItems() []fs.Item {
+	if len(ui.markedRows) > 0 {
+		rows := make([]int, 0, len(ui.markedRows))
+		for row := range ui.markedRows {
+			rows = append(rows, row)
+		}
+		sort.Ints(rows)
+
+		items := make([]fs.Item, 0, len(rows))
+		for _, row := range rows {
+			items = append(items, ui.table.GetCell(row, 0).GetReference().(fs.Item))
+		}
+		return items
+	}
+
+	row, column := ui.table.GetSelection()
+	selectedItem, ok := ui.table.GetCell(row, column).GetReference().(fs.Item)
+	if !ok || selectedItem == ui.currentDir.GetParent() {
+		return nil
+	}
+	return []fs.Item{selectedItem}
+}
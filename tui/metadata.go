@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/dundee/gdu/v5/pkg/device"
+	"github.com/dundee/gdu/v5/report"
+)
+
+// SetMetadata sets metadata of the analysis shown in the scan info and stored into exports
+func (ui *UI) SetMetadata(meta *report.Metadata) {
+	ui.metadata = meta
+}
+
+// SetDevicesInfoGetter sets getter used for finding the device of the analyzed directory
+func (ui *UI) SetDevicesInfoGetter(getter device.DevicesInfoGetter) {
+	ui.getter = getter
+}
+
+// getDevice returns device containing given path or nil if it can't be found
+func (ui *UI) getDevice(path string) *device.Device {
+	if ui.getter == nil {
+		return nil
+	}
+	devices, err := ui.getter.GetDevicesInfo()
+	if err != nil {
+		return nil
+	}
+	return device.GetDeviceForPath(path, devices)
+}
+
+// showMetadataHeader shows summary of the imported analysis in the header
+func (ui *UI) showMetadataHeader() {
+	if ui.metadata == nil || ui.metadata.Timestamp.IsZero() {
+		return
+	}
+	ui.header.SetText(" gdu ~ " + formatMetadataSummary(ui.metadata) + " ~ press S for scan info, ? for help ")
+}
+
+func formatMetadataSummary(meta *report.Metadata) string {
+	var summary strings.Builder
+	if meta.Hostname != "" {
+		summary.WriteString(meta.Hostname + ":")
+	}
+	summary.WriteString(meta.Root)
+	summary.WriteString(" scanned " + meta.Timestamp.Format("2006-01-02 15:04"))
+	if meta.Duration > 0 {
+		summary.WriteString(" in " + report.FormatDuration(meta.Duration))
+	}
+	if meta.ErrorCount > 0 {
+		summary.WriteString(fmt.Sprintf(" with %d errors", meta.ErrorCount))
+	}
+	return summary.String()
+}
+
+func (ui *UI) showScanInfo() {
+	text := tview.NewTextView().SetDynamicColors(true)
+	text.SetBorder(true).SetBorderPadding(1, 1, 2, 2)
+	text.SetBorderColor(tcell.ColorDefault)
+	text.SetTitle(" Scan info ")
+	text.SetScrollable(true)
+
+	var content strings.Builder
+	var items []report.MetadataItem
+	if ui.metadata != nil {
+		items = ui.metadata.Items()
+	}
+	if len(items) == 0 {
+		content.WriteString("No information about the analysis available")
+	}
+
+	nameWidth := 0
+	for _, item := range items {
+		nameWidth = max(nameWidth, len(item.Name))
+	}
+	for _, item := range items {
+		content.WriteString(fmt.Sprintf("[::b]%*s:[::-] %s\n", nameWidth, item.Name, tview.Escape(item.Value)))
+	}
+	text.SetText(strings.TrimSuffix(content.String(), "\n"))
+
+	height := max(len(items), 1) + 4
+	if _, screenHeight := ui.screen.Size(); height > screenHeight {
+		height = screenHeight
+	}
+
+	ui.pages.AddPage("scaninfo", modal(text, 80, height), true, true)
+	ui.app.SetFocus(text)
+}
@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testapp"
+	"github.com/dundee/gdu/v5/internal/testdev"
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/device"
+	"github.com/dundee/gdu/v5/report"
+)
+
+func TestReadAnalysisShowsMetadata(t *testing.T) {
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	input := `[1,2,{"progname":"gdu","progver":"v5.30.0","timestamp":1704164645,
+		"hostname":"nfs1","scan_root":"/srv","scan_duration":2.5,"errors":3},
+		[{"name":"/srv"},{"name":"file","asize":10,"dsize":4096}]]`
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, true, true, true, false, false)
+	ui.done = make(chan struct{})
+
+	err := ui.ReadAnalysis(strings.NewReader(input))
+	assert.Nil(t, err)
+
+	<-ui.done // wait for reading
+
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+
+	assert.Equal(t, "nfs1", ui.metadata.Hostname)
+	assert.Contains(t, ui.header.GetText(false), "nfs1:/srv scanned ")
+	assert.Contains(t, ui.header.GetText(false), " in 2.5s with 3 errors")
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'S', 0))
+	assert.True(t, ui.pages.HasPage("scaninfo"))
+
+	_, page := ui.pages.GetFrontPage()
+	text := page.(*tview.Flex).GetItem(1).(*tview.Flex).GetItem(1).(*tview.TextView).GetText(true)
+	assert.Contains(t, text, "Host: nfs1")
+	assert.Contains(t, text, "gdu version: v5.30.0")
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'S', 0))
+	assert.False(t, ui.pages.HasPage("scaninfo"))
+}
+
+func TestShowScanInfoWithoutMetadata(t *testing.T) {
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, true, true, true, false, false)
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'S', 0))
+	assert.True(t, ui.pages.HasPage("scaninfo"))
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyEsc, 0, 0))
+	assert.False(t, ui.pages.HasPage("scaninfo"))
+}
+
+func TestAnalyzePathFillsMetadata(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, true, true, true, false, false)
+	ui.done = make(chan struct{})
+	ui.SetAnalyzer(analyze.CreateAnalyzer())
+	ui.SetDevicesInfoGetter(testdev.DevicesInfoGetterMock{
+		Devices: []*device.Device{{Name: "/dev/sda1", MountPoint: "/"}},
+	})
+	ui.SetMetadata(report.NewMetadata("parallel", nil))
+
+	err := ui.AnalyzePath("test_dir", nil)
+	assert.Nil(t, err)
+
+	<-ui.done // wait for analyzer
+
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+
+	assert.Equal(t, "test_dir", ui.metadata.Root)
+	assert.Equal(t, "parallel", ui.metadata.Analyzer)
+	assert.Equal(t, 0, ui.metadata.ErrorCount)
+	assert.WithinDuration(t, time.Now(), ui.metadata.Timestamp, time.Minute)
+	// header of live analysis is not changed
+	assert.Contains(t, ui.header.GetText(false), "press ? for help")
+}
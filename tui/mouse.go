@@ -18,6 +18,12 @@ func (ui *UI) onMouse(event *tcell.EventMouse, action tview.MouseAction) (*tcell
 		ui.pages.HasPage("help") {
 		return nil, action
 	}
+	if ui.pages.HasPage("diff") {
+		return event, action // send event to diff table
+	}
+	if ui.pages.HasPage("treemap") {
+		return ui.onTreemapMouse(event, action)
+	}
 
 	// nolint: exhaustive // Why: we don't need to handle all mouse events
 	switch action {
@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/move"
+)
+
+func (ui *UI) showMoveForm() {
+	if ui.currentDir == nil {
+		return
+	}
+	if ui.noDelete {
+		ui.showErr("Write operations are disabled", nil)
+		return
+	}
+	if ui.isInArchive() {
+		ui.showErr("Moving is not supported in archives", nil)
+		return
+	}
+
+	items := ui.getSelectedOrMarkedItems()
+	if len(items) == 0 {
+		return
+	}
+
+	title := " Move " + tview.Escape(items[0].GetName()) + " "
+	if len(items) > 1 {
+		title = fmt.Sprintf(" Move %d marked items ", len(items))
+	}
+
+	leaveSymlink := false
+	destination := tview.NewInputField().
+		SetLabel("To directory").
+		SetText(ui.currentDirPath + string(filepath.Separator)).
+		SetFieldWidth(60)
+
+	form := tview.NewForm().
+		AddFormItem(destination).
+		AddCheckbox("Leave symlink", false, func(checked bool) {
+			leaveSymlink = checked
+		})
+	form.AddButton("Move", func() {
+		if destination.GetText() == "" {
+			return
+		}
+		ui.closeMoveForm()
+		ui.moveItems(items, ui.resolvePath(destination.GetText()), leaveSymlink)
+	}).
+		AddButton("Cancel", ui.closeMoveForm).
+		SetButtonsAlign(tview.AlignCenter)
+	form.SetBorder(true).
+		SetTitle(title).
+		SetInputCapture(func(key *tcell.EventKey) *tcell.EventKey {
+			if key.Key() == tcell.KeyEsc {
+				ui.closeMoveForm()
+				return nil
+			}
+			return key
+		})
+
+	ui.addInputPage("move", modal(form, 80, 9))
+	ui.app.SetFocus(form)
+}
+
+func (ui *UI) closeMoveForm() {
+	ui.pages.RemovePage("move")
+	ui.app.SetFocus(ui.table)
+}
+
+// moveItems moves items into the destination directory in the background
+func (ui *UI) moveItems(items []fs.Item, destDir string, leaveSymlink bool) {
+	if info, err := os.Stat(destDir); err != nil || !info.IsDir() {
+		ui.showErr("Destination "+tview.Escape(destDir)+" is not a directory", err)
+		return
+	}
+
+	ui.markedRows = make(map[int]struct{})
+
+	go func() {
+		for _, item := range items {
+			dst := filepath.Join(destDir, item.GetName())
+			ui.startJob(dst, "Moving "+item.GetName(), item.GetSize())
+
+			err := move.Item(item.GetPath(), dst, leaveSymlink, func(copied int64) {
+				ui.setJobProgress(dst, copied)
+			})
+			ui.finishJob(dst)
+			if err != nil {
+				ui.showErrFromGo("Can't move "+tview.Escape(item.GetName()), err)
+				break
+			}
+			ui.relocateInTree(item, dst, leaveSymlink)
+		}
+
+		ui.app.QueueUpdateDraw(func() {
+			row, _ := ui.table.GetSelection()
+			ui.showDir()
+			ui.table.Select(min(row, ui.table.GetRowCount()-1), 0)
+		})
+		if ui.done != nil {
+			ui.done <- struct{}{}
+		}
+	}()
+}
+
+// relocateInTree updates the analyzed tree after the item was moved to dst,
+// the item is added to the destination directory if it's part of the tree
+func (ui *UI) relocateInTree(item fs.Item, dst string, leaveSymlink bool) {
+	parent := item.GetParent()
+	path := item.GetPath()
+	parent.RemoveFile(item)
+
+	if leaveSymlink {
+		if dir, ok := parent.(*analyze.Dir); ok {
+			if info, err := os.Lstat(path); err == nil {
+				dir.AddFileWithStats(analyze.NewFile(info, dir))
+			}
+		}
+	}
+
+	if dir, ok := ui.findItemByPath(filepath.Dir(dst)).(*analyze.Dir); ok {
+		item.SetParent(dir)
+		dir.AddFileWithStats(item)
+	}
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func TestMoveWithinTree(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	nested := ui.findItemByPath(filepath.Join(ui.topDirPath, "nested"))
+	file := ui.findItemByPath(filepath.Join(ui.topDirPath, "nested", "file2"))
+	nestedUsage := nested.GetUsage()
+
+	ui.moveItems([]fs.Item{file}, ui.topDirPath, true)
+	<-ui.done
+	drawUpdates(ui)
+
+	assert.FileExists(t, "test_dir/file2")
+	target, err := os.Readlink("test_dir/nested/file2")
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(ui.topDirPath, "file2"), target)
+
+	// moved item is in the destination directory
+	moved := ui.findItemByPath(filepath.Join(ui.topDirPath, "file2"))
+	assert.Equal(t, file, moved)
+	assert.Equal(t, ui.topDir, moved.GetParent())
+
+	// symlink is in place of the moved item
+	link := ui.findItemByPath(filepath.Join(ui.topDirPath, "nested", "file2"))
+	assert.NotNil(t, link)
+	assert.Equal(t, '@', link.GetFlag())
+	assert.Less(t, nested.GetUsage(), nestedUsage)
+	assert.Empty(t, ui.jobs)
+}
+
+func TestMoveOutsideOfTree(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	destDir := t.TempDir()
+	size := ui.topDir.GetSize()
+
+	ui.table.Select(0, 0)
+	ui.moveItems(ui.getSelectedOrMarkedItems(), destDir, false)
+	<-ui.done
+	drawUpdates(ui)
+
+	assert.NoDirExists(t, "test_dir/nested")
+	assert.FileExists(t, filepath.Join(destDir, "nested", "file2"))
+	assert.Empty(t, ui.topDir.GetFiles())
+	assert.Less(t, ui.topDir.GetSize(), size)
+}
+
+func TestMoveErrors(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.table.Select(0, 0)
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'x', 0))
+	assert.True(t, ui.pages.HasPage("move"))
+	ui.closeMoveForm()
+
+	ui.moveItems(ui.getSelectedOrMarkedItems(), filepath.Join(ui.topDirPath, "xxx"), false)
+	assert.True(t, ui.pages.HasPage("error"))
+	ui.pages.RemovePage("error")
+
+	// item can't be moved inside of itself
+	ui.moveItems(ui.getSelectedOrMarkedItems(), filepath.Join(ui.topDirPath, "nested", "subnested"), false)
+	<-ui.done
+	drawUpdates(ui)
+	assert.True(t, ui.pages.HasPage("error"))
+	assert.DirExists(t, "test_dir/nested")
+	assert.Len(t, ui.topDir.GetFiles(), 1)
+}
+
+func TestMoveWithNoDelete(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.SetNoDelete()
+	ui.table.Select(0, 0)
+
+	ui.showMoveForm()
+	assert.False(t, ui.pages.HasPage("move"))
+	assert.True(t, ui.pages.HasPage("error"))
+}
@@ -0,0 +1,261 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/dundee/gdu/v5/build"
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/search"
+)
+
+// maximal number of results shown by the global search
+const searchResultsLimit = 1000
+
+// number of checked items after which progress of the search is shown
+const searchProgressInterval = 100000
+
+// searchView is page with global search in the whole analyzed tree
+type searchView struct {
+	*tview.Flex
+	input   *tview.InputField
+	status  *tview.TextView
+	results *tview.Table
+	cancel  context.CancelFunc
+}
+
+func (ui *UI) showSearch() {
+	if ui.topDir == nil {
+		return
+	}
+
+	view := &searchView{
+		Flex:    tview.NewFlex().SetDirection(tview.FlexRow),
+		input:   tview.NewInputField(),
+		status:  tview.NewTextView().SetDynamicColors(true),
+		results: tview.NewTable().SetSelectable(true, false),
+	}
+	ui.search = view
+
+	view.input.SetText(ui.searchQuery)
+	view.input.SetChangedFunc(func(text string) {
+		ui.searchQuery = text
+		ui.runSearch()
+	})
+	view.input.SetInputCapture(ui.handleSearchInput)
+	if !ui.UseColors {
+		view.input.SetFieldBackgroundColor(tcell.NewRGBColor(100, 100, 100))
+		view.input.SetFieldTextColor(tcell.NewRGBColor(255, 255, 255))
+	}
+	ui.setSearchLabel()
+
+	view.results.SetBackgroundColor(tcell.ColorDefault)
+	view.results.SetSelectedStyle(ui.getSelectedStyle())
+	view.results.SetSelectedFunc(ui.searchResultSelected)
+	view.results.SetInputCapture(ui.handleSearchResults)
+
+	view.AddItem(view.input, 1, 0, true).
+		AddItem(view.status, 1, 0, false).
+		AddItem(view.results, 0, 1, false)
+	view.SetBorder(true).
+		SetTitle(" Search (Ctrl+T changes mode, Enter/↓ goes to results, Esc closes) ")
+
+	ui.addInputPage("search", view)
+	ui.app.SetFocus(view.input)
+	ui.runSearch()
+}
+
+func (ui *UI) closeSearch() {
+	if ui.search.cancel != nil {
+		ui.search.cancel()
+	}
+	ui.pages.RemovePage("search")
+	ui.app.SetFocus(ui.table)
+}
+
+func (ui *UI) setSearchLabel() {
+	ui.search.input.SetLabel(fmt.Sprintf("Find (%s): ", ui.getSearchMode()))
+}
+
+func (ui *UI) getSearchMode() string {
+	if ui.searchMode == "" {
+		return search.ModeSubstring
+	}
+	return ui.searchMode
+}
+
+func (ui *UI) nextSearchMode() {
+	mode := ui.getSearchMode()
+	for i, m := range search.Modes {
+		if m == mode {
+			ui.searchMode = search.Modes[(i+1)%len(search.Modes)]
+			break
+		}
+	}
+	ui.setSearchLabel()
+	ui.runSearch()
+}
+
+func (ui *UI) handleSearchInput(key *tcell.EventKey) *tcell.EventKey {
+	switch key.Key() {
+	case tcell.KeyEsc:
+		ui.closeSearch()
+		return nil
+	case tcell.KeyCtrlT:
+		ui.nextSearchMode()
+		return nil
+	case tcell.KeyEnter, tcell.KeyDown, tcell.KeyTab:
+		if ui.search.results.GetRowCount() > 0 {
+			ui.search.results.Select(0, 0)
+			ui.app.SetFocus(ui.search.results)
+		}
+		return nil
+	}
+	return key
+}
+
+func (ui *UI) handleSearchResults(key *tcell.EventKey) *tcell.EventKey {
+	action := ui.keymap.action(key)
+	switch {
+	case key.Key() == tcell.KeyEsc || action == keyActionQuit:
+		ui.closeSearch()
+		return nil
+	case key.Key() == tcell.KeyTab || action == keyActionFilter:
+		ui.app.SetFocus(ui.search.input)
+		return nil
+	case key.Key() == tcell.KeyUp || key.Rune() == 'k':
+		if row, _ := ui.search.results.GetSelection(); row == 0 {
+			ui.app.SetFocus(ui.search.input)
+			return nil
+		}
+	}
+	return key
+}
+
+// runSearch starts searching for the current query in the background.
+// Running search is cancelled.
+func (ui *UI) runSearch() {
+	view := ui.search
+	if view.cancel != nil {
+		view.cancel()
+		view.cancel = nil
+	}
+	view.results.Clear()
+
+	if ui.searchQuery == "" {
+		view.status.SetText(" Type to search in " + tview.Escape(ui.topDirPath))
+		return
+	}
+
+	match, err := search.NewMatcher(ui.getSearchMode(), ui.searchQuery)
+	if err != nil {
+		view.status.SetText(" [red::]" + tview.Escape(err.Error()))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	view.cancel = cancel
+	view.status.SetText(" Searching...")
+
+	topDir := ui.topDir
+	go func() {
+		progress := func(count int) {
+			if count%searchProgressInterval != 0 {
+				return
+			}
+			ui.app.QueueUpdateDraw(func() {
+				if ctx.Err() == nil {
+					view.status.SetText(fmt.Sprintf(" Searching... %d items checked", count))
+				}
+			})
+		}
+		results := search.Search(ctx, topDir, match, searchResultsLimit, ui.ShowApparentSize, progress)
+
+		ui.app.QueueUpdateDraw(func() {
+			if ctx.Err() != nil {
+				return
+			}
+			ui.showSearchResults(results)
+		})
+		if ui.done != nil {
+			ui.done <- struct{}{}
+		}
+	}()
+}
+
+func (ui *UI) showSearchResults(results []search.Result) {
+	view := ui.search
+	view.results.Clear()
+
+	status := fmt.Sprintf(" Found %d items", len(results))
+	if len(results) == searchResultsLimit {
+		status = fmt.Sprintf(" Showing best %d items", searchResultsLimit)
+	}
+	view.status.SetText(status)
+
+	for row, result := range results {
+		cell := tview.NewTableCell(ui.formatSearchResult(result.Item))
+		cell.SetReference(result.Item)
+		cell.SetStyle(tcell.Style{}.Foreground(tcell.ColorDefault))
+		view.results.SetCell(row, 0, cell)
+	}
+	view.results.Select(0, 0)
+	view.results.ScrollToBeginning()
+}
+
+func (ui *UI) formatSearchResult(item fs.Item) string {
+	var size int64
+	if ui.ShowApparentSize {
+		size = item.GetSize()
+	} else {
+		size = item.GetUsage()
+	}
+
+	row := defaultColorBold
+	if ui.UseColors {
+		row = fmt.Sprintf("[%s::b]", ui.resultRow.NumberColor)
+	}
+	row += fmt.Sprintf("%15s", ui.formatSize(size, false, true)) + " "
+
+	path := strings.TrimPrefix(item.GetPath(), build.RootPathPrefix)
+	if item.IsDir() {
+		if ui.UseColors {
+			row += fmt.Sprintf("[%s::b]", ui.resultRow.DirectoryColor)
+		} else {
+			row += defaultColorBold
+		}
+		return row + tview.Escape(path) + string(filepath.Separator)
+	}
+	return row + defaultColor + tview.Escape(path)
+}
+
+// searchResultSelected opens the directory containing the result and selects it
+func (ui *UI) searchResultSelected(row, column int) {
+	item, ok := ui.search.results.GetCell(row, column).GetReference().(fs.Item)
+	if !ok || item.GetParent() == nil {
+		return
+	}
+	ui.closeSearch()
+	ui.openDir(item.GetParent(), item)
+}
+
+// selectItemRow selects row of the table showing the item
+// or the collapsed path containing it
+func (ui *UI) selectItemRow(item fs.Item) {
+	path := item.GetPath()
+	for row := 0; row < ui.table.GetRowCount(); row++ {
+		ref, ok := ui.table.GetCell(row, 0).GetReference().(fs.Item)
+		if !ok || ref == nil {
+			continue
+		}
+		if ref.GetPath() == path || strings.HasPrefix(ref.GetPath(), path+string(filepath.Separator)) {
+			ui.table.Select(row, 0)
+			return
+		}
+	}
+}
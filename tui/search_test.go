@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testapp"
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func TestSearch(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, false, true, false, false, false)
+	ui.done = make(chan struct{})
+	err := ui.AnalyzePath("test_dir", nil)
+	assert.Nil(t, err)
+	<-ui.done
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'f', 0))
+	assert.True(t, ui.pages.HasPage("search"))
+	assert.Equal(t, 0, ui.search.results.GetRowCount())
+
+	ui.search.input.SetText("file")
+	<-ui.done
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+
+	assert.Equal(t, 2, ui.search.results.GetRowCount())
+	assert.Contains(t, ui.search.status.GetText(false), "Found 2 items")
+	assert.Equal(t, "file", ui.search.results.GetCell(0, 0).GetReference().(fs.Item).GetName())
+	assert.Contains(t, ui.search.results.GetCell(0, 0).Text, "test_dir/nested/subnested/file")
+
+	// keys are handled by the search page
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'q', 0))
+	assert.True(t, ui.pages.HasPage("search"))
+
+	ui.searchResultSelected(1, 0)
+	assert.False(t, ui.pages.HasPage("search"))
+	assert.Equal(t, "nested", ui.currentDir.GetName())
+	row, _ := ui.table.GetSelection()
+	assert.Equal(t, "file2", ui.table.GetCell(row, 0).GetReference().(fs.Item).GetName())
+}
+
+func TestSearchModes(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, false, true, false, false, false)
+	ui.done = make(chan struct{})
+	err := ui.AnalyzePath("test_dir", nil)
+	assert.Nil(t, err)
+	<-ui.done
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+
+	ui.showSearch()
+	assert.Contains(t, ui.search.input.GetLabel(), "substring")
+
+	ui.searchMode = "regex"
+	ui.search.input.SetText("(")
+	assert.Contains(t, ui.search.status.GetText(false), "invalid regular expression")
+
+	// the search is run again with the new mode
+	ui.handleSearchInput(tcell.NewEventKey(tcell.KeyCtrlT, 0, 0))
+	<-ui.done
+	assert.Equal(t, "fuzzy", ui.searchMode)
+	assert.Contains(t, ui.search.input.GetLabel(), "fuzzy")
+	ui.search.input.SetText("sbn")
+	<-ui.done
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+	assert.Equal(t, 1, ui.search.results.GetRowCount())
+	assert.Equal(t, "subnested", ui.search.results.GetCell(0, 0).GetReference().(fs.Item).GetName())
+
+	ui.handleSearchInput(tcell.NewEventKey(tcell.KeyCtrlT, 0, 0))
+	<-ui.done
+	assert.Equal(t, "substring", ui.searchMode)
+
+	ui.handleSearchInput(tcell.NewEventKey(tcell.KeyEsc, 0, 0))
+	assert.False(t, ui.pages.HasPage("search"))
+}
@@ -13,37 +13,6 @@ import (
 	"github.com/dundee/gdu/v5/pkg/fs"
 )
 
-const helpText = `     [::b]up/down, k/j    [white:black:-]Move cursor up/down
-  [::b]pgup/pgdn, g/G     [white:black:-]Move cursor top/bottom
- [::b]enter, right, l     [white:black:-]Go to directory/device
-         [::b]left, h     [white:black:-]Go to parent directory
-
-               [::b]r     [white:black:-]Rescan current directory
-               [::b]E     [white:black:-]Export analysis data to file as JSON
-               [::b]/     [white:black:-]Search items by name
-               [::b]a     [white:black:-]Toggle between showing disk usage and apparent size
-               [::b]B     [white:black:-]Toggle bar alignment to biggest file or directory
-               [::b]c     [white:black:-]Show/hide file count
-               [::b]m     [white:black:-]Show/hide latest mtime
-               [::b]b     [white:black:-]Spawn shell in current directory
-               [::b]q     [white:black:-]Quit gdu
-               [::b]Q     [white:black:-]Quit gdu and print current directory path
-
-Item under cursor:
-               [::b]d     [white:black:-]Delete file or directory
-               [::b]e     [white:black:-]Empty file or directory
-			   [::b]space [white:black:-]Mark file or directory for deletion
-			   [::b]I     [white:black:-]Ignore file or directory
-               [::b]v     [white:black:-]Show content of file
-               [::b]o     [white:black:-]Open file or directory in external program
-               [::b]i     [white:black:-]Show info about item
-
-Sort by (twice toggles asc/desc):
-               [::b]n     [white:black:-]Sort by name (asc/desc)
-               [::b]s     [white:black:-]Sort by size (asc/desc)
-               [::b]C     [white:black:-]Sort by file count (asc/desc)
-               [::b]M     [white:black:-]Sort by mtime (asc/desc)`
-
 // nolint: funlen // Why: complex function
 func (ui *UI) showDir() {
 	var (
@@ -54,6 +23,9 @@ func (ui *UI) showDir() {
 		itemCount  int
 	)
 
+	if ui.currentDirPath != "" && ui.currentDirPath != ui.currentDir.GetPath() {
+		ui.previousDirPath = ui.currentDirPath
+	}
 	ui.currentDirPath = ui.currentDir.GetPath()
 
 	if ui.changeCwdFn != nil {
@@ -71,6 +43,7 @@ func (ui *UI) showDir() {
 		" ---").SetDynamicColors(true)
 
 	ui.table.Clear()
+	ui.treeDepths = make(map[int]int)
 
 	rowIndex := 0
 	if ui.currentDirPath != ui.topDirPath {
@@ -83,7 +56,7 @@ func (ui *UI) showDir() {
 
 		// Use the collapsed parent logic to handle navigation back through collapsed paths
 		var collapsedParent fs.Item
-		if ui.collapsePath {
+		if ui.collapsePath || ui.treeView {
 			collapsedParent = findCollapsedParent(ui.currentDir)
 		} else {
 			collapsedParent = ui.currentDir.GetParent()
@@ -105,6 +78,9 @@ func (ui *UI) showDir() {
 	for _, item := range ui.currentDir.GetFiles() {
 		if _, ignored := ui.ignoredRows[i]; ignored {
 			i++
+			if ui.treeView {
+				i += ui.countTreeRows(getTreeReference(item))
+			}
 			continue
 		}
 
@@ -120,6 +96,9 @@ func (ui *UI) showDir() {
 			maxUsage += item.GetUsage()
 		}
 		i++
+		if ui.treeView {
+			i += ui.countTreeRows(getTreeReference(item))
+		}
 	}
 
 	for _, item := range ui.currentDir.GetFiles() {
@@ -140,65 +119,17 @@ func (ui *UI) showDir() {
 
 		_, marked := ui.markedRows[rowIndex]
 
-		var cell *tview.TableCell
-		var reference fs.Item
-
-		// Check if this directory can be collapsed
-		if item.IsDir() {
-			var collapsedPath *CollapsedPath
-			if ui.collapsePath {
-				collapsedPath = findCollapsiblePath(item)
-			}
-
-			if collapsedPath != nil {
-				// Format as collapsed path
-				cell = tview.NewTableCell(ui.formatCollapsedRow(collapsedPath, maxUsage, maxSize, marked, ignored))
-				// Reference should point to the deepest directory for navigation
-				reference = collapsedPath.DeepestDir
-			} else {
-				// Regular directory formatting
-				cell = tview.NewTableCell(ui.formatFileRow(item, maxUsage, maxSize, marked, ignored))
-				reference = item
-			}
-		} else {
-			// Regular file formatting
-			cell = tview.NewTableCell(ui.formatFileRow(item, maxUsage, maxSize, marked, ignored))
-			reference = item
-		}
-
-		cell.SetReference(reference)
-
-		switch {
-		case ignored:
-			cell.SetStyle(tcell.Style{}.Foreground(tview.Styles.SecondaryTextColor))
-		case marked:
-			cell.SetStyle(tcell.Style{}.Foreground(tview.Styles.PrimaryTextColor))
-			cell.SetBackgroundColor(tview.Styles.ContrastBackgroundColor)
-		default:
-			cell.SetStyle(tcell.Style{}.Foreground(tcell.ColorDefault))
-		}
-
+		cell := ui.createFileCell(item, maxUsage, maxSize, marked, ignored, "")
 		ui.table.SetCell(rowIndex, 0, cell)
 		rowIndex++
-	}
 
-	var footerNumberColor, footerTextColor string
-	if ui.UseColors {
-		footerNumberColor = fmt.Sprintf(
-			"[%s:%s:b]",
-			ui.footerNumberColor,
-			ui.footerBackgroundColor,
-		)
-		footerTextColor = fmt.Sprintf(
-			"[%s:%s:-]",
-			ui.footerTextColor,
-			ui.footerBackgroundColor,
-		)
-	} else {
-		footerNumberColor = "[black:white:b]"
-		footerTextColor = blackOnWhite
+		if ui.treeView {
+			rowIndex = ui.addTreeRows(cell.GetReference().(fs.Item), rowIndex, treeIndent, 1)
+		}
 	}
 
+	footerNumberColor, footerTextColor := ui.getFooterColors()
+
 	selected := ""
 	if len(ui.markedRows) > 0 {
 		selected = " Selected items: " + footerNumberColor +
@@ -229,6 +160,57 @@ func (ui *UI) showDir() {
 	}
 }
 
+// createFileCell creates table cell showing the item.
+// Chain of directories with single subdirectory is folded into one row
+// when collapsing of paths or the tree view is enabled.
+func (ui *UI) createFileCell(item fs.Item, maxUsage, maxSize int64, marked, ignored bool, branch string) *tview.TableCell {
+	var collapsedPath *CollapsedPath
+	if item.IsDir() && (ui.collapsePath || ui.treeView) {
+		collapsedPath = findCollapsiblePath(item)
+	}
+
+	var (
+		cell      *tview.TableCell
+		reference fs.Item
+	)
+	if collapsedPath != nil {
+		// Reference should point to the deepest directory for navigation
+		reference = collapsedPath.DeepestDir
+		guide := ui.getTreeGuide(reference, branch)
+		cell = tview.NewTableCell(ui.formatTreeCollapsedRow(collapsedPath, maxUsage, maxSize, marked, ignored, guide))
+	} else {
+		reference = item
+		guide := ui.getTreeGuide(reference, branch)
+		cell = tview.NewTableCell(ui.formatTreeFileRow(item, maxUsage, maxSize, marked, ignored, guide))
+	}
+
+	cell.SetReference(reference)
+
+	cell.SetStyle(ui.getRowStyle(marked, ignored))
+	if marked || ignored {
+		// background of marked and ignored rows comes from the style
+		cell.SetTransparency(false)
+	}
+	return cell
+}
+
+func (ui *UI) getFooterColors() (numberColor, textColor string) {
+	if ui.UseColors {
+		numberColor = fmt.Sprintf(
+			"[%s:%s:b]",
+			ui.footerNumberColor,
+			ui.footerBackgroundColor,
+		)
+		textColor = fmt.Sprintf(
+			"[%s:%s:-]",
+			ui.footerTextColor,
+			ui.footerBackgroundColor,
+		)
+		return numberColor, textColor
+	}
+	return "[black:white:b]", blackOnWhite
+}
+
 func (ui *UI) showDevices() {
 	var totalUsage int64
 
@@ -243,6 +225,9 @@ func (ui *UI) showDevices() {
 	var textColor, sizeColor string
 	if ui.UseColors {
 		textColor = "[#3498db:-:b]"
+		if ui.theme != nil {
+			textColor = fmt.Sprintf("[%s:-:b]", ui.resultRow.DirectoryColor)
+		}
 		sizeColor = "[#edb20a:-:b]"
 	} else {
 		textColor = "[white:-:b]"
@@ -311,9 +296,7 @@ func (ui *UI) showErr(msg string, err error) {
 			ui.pages.RemovePage("error")
 		})
 
-	if !ui.UseColors {
-		modal.SetBackgroundColor(tcell.ColorGray)
-	}
+	ui.styleModal(modal, tview.Styles.ContrastBackgroundColor)
 
 	ui.pages.AddPage("error", modal, true, true)
 	ui.app.SetFocus(modal)
@@ -355,8 +338,9 @@ func (ui *UI) showHelp() {
 }
 
 func (ui *UI) formatHelpTextFor() string {
-	lines := strings.Split(helpText, "\n")
+	lines := strings.Split(ui.keymap.formatHelp(), "\n")
 
+	inCustomActions := false
 	for i, line := range lines {
 		if ui.UseColors {
 			lines[i] = strings.ReplaceAll(
@@ -379,6 +363,12 @@ func (ui *UI) formatHelpTextFor() string {
 			strings.Contains(line, "Open file or directory in external program")) {
 			lines[i] += " (disabled)"
 		}
+
+		if line == customActionsTitle {
+			inCustomActions = true
+		} else if ui.noSpawnShell && inCustomActions {
+			lines[i] += " (disabled)"
+		}
 	}
 
 	return strings.Join(lines, "\n")
@@ -75,7 +75,7 @@ func (ui *UI) showFile() *tview.TextView {
 	totalLines += readNextPart(defaultLinesCount)
 
 	file.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Rune() == 'q' || event.Key() == tcell.KeyESC {
+		if ui.keymap.action(event) == keyActionQuit || event.Key() == tcell.KeyESC {
 			err = f.Close()
 			if err != nil {
 				ui.showErr("Error closing file", err)
@@ -107,7 +107,7 @@ func (ui *UI) showFile() *tview.TextView {
 		AddItem(ui.footerLabel, 3, 0, 1, 1, 0, 0, false)
 
 	ui.pages.HidePage("background")
-	ui.pages.AddPage("file", grid, true, true)
+	ui.addInputPage("file", grid)
 
 	return file
 }
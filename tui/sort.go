@@ -49,40 +49,45 @@ func (ui *UI) setSorting(newOrder string) {
 }
 
 func (ui *UI) sortItems() {
+	ui.sortFiles(ui.currentDir.GetFiles())
+}
+
+// sortFiles sorts given items according to the current sorting
+func (ui *UI) sortFiles(files fs.Files) {
 	if ui.sortBy == sizeSortKey {
 		if ui.ShowApparentSize {
 			if ui.sortOrder == descOrder {
-				sort.Sort(sort.Reverse(fs.ByApparentSize(ui.currentDir.GetFiles())))
+				sort.Sort(sort.Reverse(fs.ByApparentSize(files)))
 			} else {
-				sort.Sort(fs.ByApparentSize(ui.currentDir.GetFiles()))
+				sort.Sort(fs.ByApparentSize(files))
 			}
 		} else {
 			if ui.sortOrder == descOrder {
-				sort.Sort(sort.Reverse(ui.currentDir.GetFiles()))
+				sort.Sort(sort.Reverse(files))
 			} else {
-				sort.Sort(ui.currentDir.GetFiles())
+				sort.Sort(files)
 			}
 		}
 	}
 	if ui.sortBy == itemCountSortKey {
 		if ui.sortOrder == descOrder {
-			sort.Sort(sort.Reverse(fs.ByItemCount(ui.currentDir.GetFiles())))
+			sort.Sort(sort.Reverse(fs.ByItemCount(files)))
 		} else {
-			sort.Sort(fs.ByItemCount(ui.currentDir.GetFiles()))
+			sort.Sort(fs.ByItemCount(files))
 		}
 	}
 	if ui.sortBy == nameSortKey {
 		if ui.sortOrder == descOrder {
-			sort.Sort(sort.Reverse(fs.ByName(ui.currentDir.GetFiles())))
+			sort.Sort(sort.Reverse(fs.ByName(files)))
 		} else {
-			sort.Sort(fs.ByName(ui.currentDir.GetFiles()))
+			sort.Sort(fs.ByName(files))
 		}
 	}
 	if ui.sortBy == mtimeSortKey {
 		if ui.sortOrder == descOrder {
-			sort.Sort(sort.Reverse(fs.ByMtime(ui.currentDir.GetFiles())))
+			sort.Sort(sort.Reverse(fs.ByMtime(files)))
 		} else {
-			sort.Sort(fs.ByMtime(ui.currentDir.GetFiles()))
+			sort.Sort(fs.ByMtime(files))
 		}
 	}
 }
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
@@ -10,10 +11,15 @@ import (
 
 func (ui *UI) toggleStatusBar(show bool) {
 	var textColor, textBgColor tcell.Color
-	if ui.UseColors {
+	switch {
+	case ui.UseColors && ui.theme != nil:
+		// status bar has the same colours as the header
+		textColor = tcell.GetColor(ui.headerTextColor)
+		textBgColor = tcell.GetColor(ui.headerBackgroundColor)
+	case ui.UseColors:
 		textColor = tcell.NewRGBColor(0, 0, 0)
 		textBgColor = tcell.NewRGBColor(36, 121, 208)
-	} else {
+	default:
 		textColor = tcell.NewRGBColor(0, 0, 0)
 		textBgColor = tcell.NewRGBColor(255, 255, 255)
 	}
@@ -44,6 +50,13 @@ func (ui *UI) toggleStatusBar(show bool) {
 		AddItem(ui.footer, 3, 0, 1, 1, 0, 0, false)
 }
 
+// runStatusWorker starts the worker updating the status bar if it's not running yet
+func (ui *UI) runStatusWorker() {
+	ui.statusWorkerOnce.Do(func() {
+		go ui.updateStatusWorker()
+	})
+}
+
 func (ui *UI) updateStatusWorker() {
 	for {
 		ui.updateStatus()
@@ -54,21 +67,27 @@ func (ui *UI) updateStatusWorker() {
 func (ui *UI) updateStatus() {
 	ui.workersMut.Lock()
 	cnt := ui.activeWorkers
+	running := len(ui.jobs) > 0
+	var jobsStatus string
+	if running {
+		jobsStatus = ui.getJobsStatus()
+	}
 	ui.workersMut.Unlock()
 
 	ui.statusMut.RLock()
 	status := ui.status
 	ui.statusMut.RUnlock()
 
-	if cnt == 0 && status == nil {
+	active := cnt > 0 || running
+	if !active && status == nil {
 		return
 	}
 
-	if cnt > 0 && status == nil {
+	if active && status == nil {
 		ui.app.QueueUpdateDraw(func() {
 			ui.toggleStatusBar(true)
 		})
-	} else if cnt == 0 {
+	} else if !active {
 		ui.app.QueueUpdateDraw(func() {
 			ui.toggleStatusBar(false)
 		})
@@ -76,7 +95,14 @@ func (ui *UI) updateStatus() {
 	}
 
 	ui.app.QueueUpdateDraw(func() {
-		msg := fmt.Sprintf(" Active background deletions: %d", cnt)
+		var parts []string
+		if cnt > 0 {
+			parts = append(parts, fmt.Sprintf("Active background deletions: %d", cnt))
+		}
+		if running {
+			parts = append(parts, jobsStatus)
+		}
+		msg := " " + strings.Join(parts, " | ")
 		ui.statusMut.RLock()
 		ui.status.SetText(msg)
 		ui.statusMut.RUnlock()
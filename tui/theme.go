@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/theme"
+)
+
+// SetTheme sets colours of the whole UI from the theme.
+// Colours of modals are set globally for all tview primitives.
+func (ui *UI) SetTheme(t *theme.Theme) {
+	ui.theme = t
+	ui.headerTextColor = t.Header.TextColor
+	ui.headerBackgroundColor = t.Header.BackgroundColor
+	ui.footerTextColor = t.Footer.TextColor
+	ui.footerBackgroundColor = t.Footer.BackgroundColor
+	ui.footerNumberColor = t.Footer.NumberColor
+	ui.resultRow.NumberColor = t.ResultRow.NumberColor
+	ui.resultRow.DirectoryColor = t.ResultRow.DirectoryColor
+	if t.SelectedRow.TextColor != "" {
+		ui.selectedTextColor = tcell.GetColor(t.SelectedRow.TextColor)
+	}
+	if t.SelectedRow.BackgroundColor != "" {
+		ui.selectedBackgroundColor = tcell.GetColor(t.SelectedRow.BackgroundColor)
+	}
+
+	if !ui.UseColors {
+		return
+	}
+	setColor(t.Modal.TextColor, &tview.Styles.PrimaryTextColor)
+	setColor(t.Modal.BackgroundColor, &tview.Styles.PrimitiveBackgroundColor)
+	setColor(t.Modal.BorderColor, &tview.Styles.BorderColor)
+	setColor(t.Modal.TitleColor, &tview.Styles.TitleColor)
+}
+
+// setColor sets the color if it's defined in the theme
+func setColor(name string, color *tcell.Color) {
+	if name != "" {
+		*color = tcell.GetColor(name)
+	}
+}
+
+// styleModal sets colours of the modal dialog,
+// background is used when the theme doesn't define background of modals
+func (ui *UI) styleModal(modal *tview.Modal, background tcell.Color) {
+	if !ui.UseColors {
+		modal.SetBackgroundColor(tcell.ColorGray)
+		return
+	}
+	modal.SetBackgroundColor(background)
+	if ui.theme == nil {
+		return
+	}
+	if ui.theme.Modal.BackgroundColor != "" {
+		modal.SetBackgroundColor(tcell.GetColor(ui.theme.Modal.BackgroundColor))
+	}
+	if ui.theme.Modal.TextColor != "" {
+		modal.SetTextColor(tcell.GetColor(ui.theme.Modal.TextColor))
+	}
+}
+
+// getRowStyle returns style of marked and ignored rows
+func (ui *UI) getRowStyle(marked, ignored bool) tcell.Style {
+	var style tcell.Style
+	switch {
+	case ignored:
+		style = tcell.Style{}.Foreground(tview.Styles.SecondaryTextColor)
+		if ui.UseColors && ui.theme != nil {
+			style = themeStyle(style, ui.theme.IgnoredRow)
+		}
+	case marked:
+		style = tcell.Style{}.
+			Foreground(tview.Styles.PrimaryTextColor).
+			Background(tview.Styles.ContrastBackgroundColor)
+		if ui.UseColors && ui.theme != nil {
+			style = themeStyle(style, ui.theme.MarkedRow)
+		}
+	default:
+		style = tcell.Style{}.Foreground(tcell.ColorDefault)
+	}
+	return style
+}
+
+func themeStyle(style tcell.Style, colors theme.ColorStyle) tcell.Style {
+	if colors.TextColor != "" {
+		style = style.Foreground(tcell.GetColor(colors.TextColor))
+	}
+	if colors.BackgroundColor != "" {
+		style = style.Background(tcell.GetColor(colors.BackgroundColor))
+	}
+	return style
+}
+
+// themeColor returns color tag of the theme colour for rows which are neither marked nor ignored
+func (ui *UI) themeColor(color string, marked, ignored bool) string {
+	if !ui.UseColors || marked || ignored || !theme.IsSet(color) {
+		return ""
+	}
+	return "[" + color + "::]"
+}
+
+// formatFlag formats flag of the item with the colour of the theme
+func (ui *UI) formatFlag(item fs.Item, marked, ignored bool) string {
+	if ui.theme == nil {
+		return string(item.GetFlag())
+	}
+	color := ui.themeColor(ui.theme.ResultRow.FlagColor, marked, ignored)
+	if color == "" || item.GetFlag() == ' ' {
+		return string(item.GetFlag())
+	}
+	return color + string(item.GetFlag()) + defaultColor
+}
+
+// formatBar formats the usage graph with the colour of the theme
+func (ui *UI) formatBar(graph string, marked, ignored bool) string {
+	if ui.theme == nil {
+		return graph
+	}
+	color := ui.themeColor(ui.theme.ResultRow.BarColor, marked, ignored)
+	if color == "" {
+		return graph
+	}
+	return color + graph + defaultColor
+}
+
+// getRuleColor returns colour of the item given by size and age rules of the theme,
+// empty string is returned when no rule matches
+func (ui *UI) getRuleColor(item fs.Item, marked, ignored bool) string {
+	if !ui.UseColors || ui.theme == nil || marked || ignored || !ui.theme.HasRules() {
+		return ""
+	}
+	size := item.GetUsage()
+	if ui.ShowApparentSize {
+		size = item.GetSize()
+	}
+	return ui.theme.RuleColor(size, item.GetMtime(), time.Now())
+}
+
+// getNumberColor returns color tag of numbers in the row
+func (ui *UI) getNumberColor(ruleColor string) string {
+	if ruleColor != "" {
+		return fmt.Sprintf("[%s::b]", ruleColor)
+	}
+	return fmt.Sprintf("[%s::b]", ui.resultRow.NumberColor)
+}
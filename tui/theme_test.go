@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testapp"
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/theme"
+)
+
+func createThemedUI(t *testing.T, name string, useColors bool) *UI {
+	t.Helper()
+
+	styles := tview.Styles
+	t.Cleanup(func() { tview.Styles = styles })
+
+	th, err := theme.Get(name, nil)
+	assert.Nil(t, err)
+
+	app := testapp.CreateMockedApp(false)
+	ui := CreateUI(app, nil, &bytes.Buffer{}, useColors, false, false, false, false, func(ui *UI) {
+		ui.SetTheme(th)
+	})
+	ui.useOldSizeBar = true
+	return ui
+}
+
+func TestSetTheme(t *testing.T) {
+	ui := createThemedUI(t, "nord", true)
+
+	assert.Equal(t, "#2e3440", ui.headerTextColor)
+	assert.Equal(t, "#88c0d0", ui.footerBackgroundColor)
+	assert.Equal(t, tcell.GetColor("#5e81ac"), ui.selectedBackgroundColor)
+	assert.Equal(t, tcell.GetColor("#3b4252"), tview.Styles.PrimitiveBackgroundColor)
+	assert.Equal(t, tcell.GetColor("#88c0d0"), tview.Styles.BorderColor)
+}
+
+func TestThemedRows(t *testing.T) {
+	ui := createThemedUI(t, "gruvbox", true)
+	now := time.Now()
+
+	dir := &analyze.Dir{File: &analyze.File{Usage: 20 << 30}}
+	big := &analyze.File{Name: "big", Parent: dir, Usage: 20 << 30, Mtime: now, Flag: 'H'}
+	old := &analyze.File{Name: "old", Parent: dir, Usage: 1 << 20, Mtime: now.AddDate(-2, 0, 0), Flag: ' '}
+	fresh := &analyze.File{Name: "fresh", Parent: dir, Usage: 1 << 20, Mtime: now, Flag: ' '}
+
+	row := ui.formatFileRow(big, dir.GetUsage(), dir.GetSize(), false, false)
+	assert.Contains(t, row, "[#d3869b::]H[-::][#fb4934::b]")
+	assert.Contains(t, row, "[#8ec07c::][##########][-::]")
+	assert.Contains(t, row, "[#fb4934::]big")
+
+	assert.Contains(t, ui.formatFileRow(old, dir.GetUsage(), dir.GetSize(), false, false), "[#928374::]old")
+	assert.Contains(t, ui.formatFileRow(fresh, dir.GetUsage(), dir.GetSize(), false, false), "[#fe8019::b]")
+
+	// rules don't apply to marked rows
+	row = ui.formatFileRow(big, dir.GetUsage(), dir.GetSize(), true, false)
+	assert.NotContains(t, row, "#fb4934")
+	assert.NotContains(t, row, "#8ec07c")
+}
+
+func TestThemedRowsWithoutColors(t *testing.T) {
+	ui := createThemedUI(t, "gruvbox", false)
+
+	dir := &analyze.Dir{File: &analyze.File{Usage: 20 << 30}}
+	big := &analyze.File{Name: "big", Parent: dir, Usage: 20 << 30, Mtime: time.Now(), Flag: 'H'}
+
+	row := ui.formatFileRow(big, dir.GetUsage(), dir.GetSize(), false, false)
+	assert.Equal(t, "H[::b]  20.0[-::] GiB [##########] big", row)
+	_, bg, _ := ui.getRowStyle(true, false).Decompose()
+	assert.Equal(t, tview.Styles.ContrastBackgroundColor, bg)
+}
+
+func TestThemedMarkedAndIgnoredRows(t *testing.T) {
+	ui := createThemedUI(t, "light", true)
+
+	fg, bg, _ := ui.getRowStyle(true, false).Decompose()
+	assert.Equal(t, tcell.GetColor("#000000"), fg)
+	assert.Equal(t, tcell.GetColor("#ffd75f"), bg)
+	fg, _, _ = ui.getRowStyle(false, true).Decompose()
+	assert.Equal(t, tcell.GetColor("#8a8a8a"), fg)
+}
+
+func TestThemedModal(t *testing.T) {
+	ui := createThemedUI(t, "light", true)
+	ui.showErr("Something went wrong", nil)
+	assert.True(t, ui.pages.HasPage("error"))
+
+	screen := testapp.CreateSimScreen()
+	assert.Nil(t, screen.Init())
+	defer screen.Fini()
+	screen.SetSize(60, 20)
+
+	modal := tview.NewModal().SetText("Text")
+	ui.styleModal(modal, tcell.ColorBlack)
+	modal.SetRect(0, 0, 60, 20)
+	modal.Draw(screen)
+
+	_, _, style, _ := screen.GetContent(30, 9)
+	_, bg, _ := style.Decompose()
+	assert.Equal(t, tcell.GetColor("#e4e4e4"), bg)
+}
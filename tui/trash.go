@@ -0,0 +1,222 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+	"github.com/dundee/gdu/v5/pkg/trash"
+)
+
+func (ui *UI) getDeleteAction() string {
+	if ui.deleteToTrash {
+		return actionTrash
+	}
+	return actionDelete
+}
+
+// getTrashes returns the home trash, the trash of the analyzed directory
+// and trashes of all mount points under the analyzed directory
+func (ui *UI) getTrashes() ([]*trash.Trash, error) {
+	home, err := trash.HomeTrash()
+	if err != nil {
+		return nil, err
+	}
+	trashes := []*trash.Trash{home}
+	add := func(t *trash.Trash, err error) {
+		if err != nil {
+			return
+		}
+		for _, known := range trashes {
+			if known.Dir == t.Dir {
+				return
+			}
+		}
+		trashes = append(trashes, t)
+	}
+
+	if ui.topDirPath == "" {
+		return trashes, nil
+	}
+	add(trash.ForPath(ui.topDirPath))
+
+	if ui.getter == nil {
+		return trashes, nil
+	}
+	mounts, err := ui.getter.GetMounts()
+	if err != nil {
+		return trashes, nil
+	}
+	prefix := strings.TrimSuffix(ui.topDirPath, string(filepath.Separator)) + string(filepath.Separator)
+	for _, mount := range mounts {
+		if mount.MountPoint == ui.topDirPath || strings.HasPrefix(mount.MountPoint, prefix) {
+			add(trash.ForMount(mount.MountPoint))
+		}
+	}
+	return trashes, nil
+}
+
+// getTrashedItems returns items moved to the trash by gdu
+func (ui *UI) getTrashedItems() ([]*trash.Item, error) {
+	trashes, err := ui.getTrashes()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*trash.Item
+	for _, t := range trashes {
+		found, err := t.List()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, found...)
+	}
+	return items, nil
+}
+
+func (ui *UI) showTrash() {
+	if ui.noDelete {
+		ui.showErr("Write operations are disabled", nil)
+		return
+	}
+
+	items, err := ui.getTrashedItems()
+	if err != nil {
+		ui.showErr("Error reading trash", err)
+		return
+	}
+
+	table := tview.NewTable().SetSelectable(true, false)
+	table.SetBackgroundColor(tcell.ColorDefault)
+	table.SetSelectedStyle(ui.getSelectedStyle())
+	table.SetBorder(true).
+		SetTitle(" Trash (" + formatKeyHints(
+			keyHint{"Enter", "restores"},
+			keyHint{ui.keymap.keyName(keyActionDelete), "deletes permanently"},
+			keyHint{"E", "empties"},
+			keyHint{"Esc", "closes"},
+		) + ") ")
+
+	if len(items) == 0 {
+		table.SetCell(0, 0, tview.NewTableCell(" No items deleted by gdu in the trash").SetSelectable(false))
+	}
+	for row, item := range items {
+		table.SetCell(row, 0, tview.NewTableCell(" "+ui.formatSize(item.Size, false, true)).SetReference(item))
+		table.SetCell(row, 1, tview.NewTableCell(" "+item.DeletionDate.Format("2006-01-02 15:04:05")+" "))
+		table.SetCell(row, 2, tview.NewTableCell(tview.Escape(item.Path)))
+	}
+
+	getSelected := func() *trash.Item {
+		row, _ := table.GetSelection()
+		item, _ := table.GetCell(row, 0).GetReference().(*trash.Item)
+		return item
+	}
+
+	table.SetSelectedFunc(func(row, column int) {
+		if item := getSelected(); item != nil {
+			ui.restoreTrashItem(item)
+		}
+	})
+	table.SetInputCapture(func(key *tcell.EventKey) *tcell.EventKey {
+		action := ui.keymap.action(key)
+		switch {
+		case key.Key() == tcell.KeyEsc || action == keyActionQuit:
+			ui.closeTrash()
+			return nil
+		case key.Rune() == 'r':
+			if item := getSelected(); item != nil {
+				ui.restoreTrashItem(item)
+			}
+			return nil
+		case action == keyActionDelete:
+			if item := getSelected(); item != nil {
+				ui.confirmTrashRemoval(
+					"Are you sure you want to permanently delete \""+tview.Escape(item.Path)+"\"?",
+					[]*trash.Item{item},
+				)
+			}
+			return nil
+		case key.Rune() == 'E':
+			if len(items) > 0 {
+				ui.confirmTrashRemoval(
+					"Are you sure you want to permanently delete all items deleted by gdu?",
+					items,
+				)
+			}
+			return nil
+		}
+		return key
+	})
+
+	ui.addInputPage("trash", modal(table, 100, 20))
+	ui.app.SetFocus(table)
+}
+
+func (ui *UI) closeTrash() {
+	ui.pages.RemovePage("trash")
+	ui.app.SetFocus(ui.table)
+}
+
+func (ui *UI) confirmTrashRemoval(text string, items []*trash.Item) {
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"no", "yes"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			ui.pages.RemovePage("trashconfirm")
+			ui.closeTrash()
+			if buttonIndex == 1 {
+				if err := ui.removeTrashItems(items); err != nil {
+					ui.showErr("Can't delete item from trash", err)
+					return
+				}
+			}
+			ui.showTrash()
+		})
+
+	modal.SetBorderColor(tcell.ColorDefault)
+	ui.styleModal(modal, tcell.ColorBlack)
+
+	ui.addInputPage("trashconfirm", modal)
+	ui.app.SetFocus(modal)
+}
+
+func (ui *UI) removeTrashItems(items []*trash.Item) error {
+	for _, item := range items {
+		if err := item.Remove(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ui *UI) restoreTrashItem(item *trash.Item) {
+	if err := item.Restore(); err != nil {
+		ui.showErr("Can't restore "+tview.Escape(item.Path), err)
+		return
+	}
+	ui.closeTrash()
+	ui.rescanRestoredItem(item.Path)
+}
+
+// rescanRestoredItem rescans the directory of the analyzed tree the item was restored to
+func (ui *UI) rescanRestoredItem(path string) {
+	var dir fs.Item
+	for dirPath := filepath.Dir(path); dir == nil; dirPath = filepath.Dir(dirPath) {
+		dir = ui.findItemByPath(dirPath)
+		if filepath.Dir(dirPath) == dirPath {
+			break
+		}
+	}
+	if dir == nil || !dir.IsDir() {
+		return // restored outside of the analyzed tree
+	}
+
+	ui.Analyzer.ResetProgress()
+	ui.linkedItems = make(fs.HardLinkedItems)
+	if err := ui.AnalyzePath(dir.GetPath(), dir.GetParent()); err != nil {
+		ui.showErr("Error rescanning path", err)
+	}
+}
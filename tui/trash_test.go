@@ -0,0 +1,139 @@
+//go:build linux
+
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testdev"
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/device"
+	"github.com/dundee/gdu/v5/pkg/trash"
+)
+
+func setTrashHome(t *testing.T) {
+	t.Helper()
+	// keep the trash on the same device as the test dir
+	dataHome, err := filepath.Abs("test_trash")
+	assert.Nil(t, err)
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Cleanup(func() {
+		os.RemoveAll(dataHome)
+	})
+}
+
+func TestDeleteToTrashAndRestore(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	setTrashHome(t)
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.SetDeleteToTrash()
+	assert.Equal(t, actionTrash, ui.getDeleteAction())
+
+	size := ui.topDir.GetSize()
+	ui.table.Select(0, 0)
+	ui.deleteSelected(false)
+	<-ui.done
+	drawUpdates(ui)
+
+	assert.NoDirExists(t, "test_dir/nested")
+	assert.Empty(t, ui.topDir.GetFiles())
+	assert.Less(t, ui.topDir.GetSize(), size)
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'D', 0))
+	assert.True(t, ui.pages.HasPage("trash"))
+
+	// keys are handled by the trash page
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'q', 0))
+	assert.True(t, ui.pages.HasPage("trash"))
+
+	items, err := ui.getTrashedItems()
+	assert.Nil(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, filepath.Join(ui.topDirPath, "nested"), items[0].Path)
+
+	ui.restoreTrashItem(items[0])
+	<-ui.done
+	drawUpdates(ui)
+
+	assert.False(t, ui.pages.HasPage("trash"))
+	assert.DirExists(t, "test_dir/nested")
+	assert.Len(t, ui.topDir.GetFiles(), 1)
+	assert.Equal(t, size, ui.topDir.GetSize())
+}
+
+func TestTrashWithNoDelete(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	setTrashHome(t)
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.SetNoDelete()
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'D', 0))
+	assert.False(t, ui.pages.HasPage("trash"))
+	assert.True(t, ui.pages.HasPage("error"))
+}
+
+func TestRemoveFromTrash(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	setTrashHome(t)
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	assert.Equal(t, actionDelete, ui.getDeleteAction())
+
+	home, err := trash.HomeTrash()
+	assert.Nil(t, err)
+	assert.Nil(t, home.Put(filepath.Join(ui.topDirPath, "nested", "file2")))
+
+	items, err := ui.getTrashedItems()
+	assert.Nil(t, err)
+	assert.Len(t, items, 1)
+
+	ui.showTrash()
+	ui.confirmTrashRemoval("Delete?", items)
+	assert.True(t, ui.pages.HasPage("trashconfirm"))
+
+	assert.Nil(t, ui.removeTrashItems(items))
+	items, err = ui.getTrashedItems()
+	assert.Nil(t, err)
+	assert.Empty(t, items)
+}
+
+func TestTrashesOfMountsUnderAnalyzedDir(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+	setTrashHome(t)
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	nested := filepath.Join(ui.topDirPath, "nested")
+	ui.SetDevicesInfoGetter(testdev.DevicesInfoGetterMock{
+		Devices: device.Devices{
+			{Name: "/dev/sda1", MountPoint: "/"},
+			{Name: "/dev/sdb1", MountPoint: nested},
+			{Name: "/dev/sdc1", MountPoint: ui.topDirPath + "-other"},
+		},
+	})
+
+	// items on other devices are put into the trash of their mount point
+	mountTrash, err := trash.ForMount(nested)
+	assert.Nil(t, err)
+	assert.Nil(t, mountTrash.Put(filepath.Join(nested, "file2")))
+
+	trashes, err := ui.getTrashes()
+	assert.Nil(t, err)
+	assert.Len(t, trashes, 2)
+	assert.Equal(t, mountTrash.Dir, trashes[1].Dir)
+
+	items, err := ui.getTrashedItems()
+	assert.Nil(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, filepath.Join(nested, "file2"), items[0].Path)
+}
@@ -0,0 +1,225 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// indentation of the items nested in the top level directories
+const treeIndent = "  "
+
+// SetTreeView shows the directories as expandable tree
+func (ui *UI) SetTreeView(value bool) {
+	ui.treeView = value
+}
+
+func (ui *UI) toggleTreeView() {
+	if ui.currentDir == nil {
+		return
+	}
+
+	ui.treeView = !ui.treeView
+	ui.markedRows = make(map[int]struct{})
+	ui.ignoredRows = make(map[int]struct{})
+	ui.showDir()
+}
+
+// getTreeGuide returns indentation guide and expansion marker shown in front of the item
+func (ui *UI) getTreeGuide(item fs.Item, branch string) string {
+	if !ui.treeView {
+		return ""
+	}
+	if item.IsDir() {
+		if ui.isExpanded(item) {
+			return branch + "▾ "
+		}
+		return branch + "▸ "
+	}
+	if branch == "" {
+		return "  "
+	}
+	return branch + "─ "
+}
+
+func (ui *UI) isExpanded(item fs.Item) bool {
+	_, ok := ui.expandedDirs[item.GetPath()]
+	return ok
+}
+
+// addTreeRows adds rows of the content of the expanded directory after the given row.
+// It returns index of the row following the added ones.
+func (ui *UI) addTreeRows(dir fs.Item, rowIndex int, indent string, depth int) int {
+	if !dir.IsDir() || !ui.isExpanded(dir) {
+		return rowIndex
+	}
+
+	ui.sortFiles(dir.GetFiles())
+
+	unlock := dir.RLock()
+	defer unlock()
+
+	var maxUsage, maxSize int64
+	files := dir.GetFiles()
+	for _, item := range files {
+		if ui.ShowRelativeSize {
+			maxUsage = max(maxUsage, item.GetUsage())
+			maxSize = max(maxSize, item.GetSize())
+		} else {
+			maxUsage += item.GetUsage()
+			maxSize += item.GetSize()
+		}
+	}
+
+	for i, item := range files {
+		branch, nextIndent := "├─", indent+"│ "
+		if i == len(files)-1 {
+			branch, nextIndent = "└─", indent+"  "
+		}
+
+		_, marked := ui.markedRows[rowIndex]
+		_, ignored := ui.ignoredRows[rowIndex]
+
+		cell := ui.createFileCell(item, maxUsage, maxSize, marked, ignored, indent+branch)
+		ui.table.SetCell(rowIndex, 0, cell)
+		ui.treeDepths[rowIndex] = depth
+		rowIndex++
+
+		rowIndex = ui.addTreeRows(cell.GetReference().(fs.Item), rowIndex, nextIndent, depth+1)
+	}
+	return rowIndex
+}
+
+// countTreeRows returns number of rows shown for the content of the expanded directory
+func (ui *UI) countTreeRows(dir fs.Item) int {
+	if !dir.IsDir() || !ui.isExpanded(dir) {
+		return 0
+	}
+
+	count := 0
+	for _, item := range dir.GetFilesLocked() {
+		count += 1 + ui.countTreeRows(getTreeReference(item))
+	}
+	return count
+}
+
+// getTreeReference returns the item shown in the row of the tree,
+// which is the deepest directory for chains of single subdirectories
+func getTreeReference(item fs.Item) fs.Item {
+	if collapsedPath := findCollapsiblePath(item); collapsedPath != nil {
+		return collapsedPath.DeepestDir
+	}
+	return item
+}
+
+// getTreeDepth returns depth of the row in the tree, top level rows have depth 0
+func (ui *UI) getTreeDepth(row int) int {
+	return ui.treeDepths[row]
+}
+
+// expandTreeRow expands or collapses the directory in the given row
+func (ui *UI) expandTreeRow(row int, expand bool) {
+	item, ok := ui.table.GetCell(row, 0).GetReference().(fs.Item)
+	if !ok || !item.IsDir() || ui.isExpanded(item) == expand {
+		return
+	}
+
+	// rows of the content are inserted or removed so marks have to be moved
+	var count int
+	if expand {
+		ui.expandedDirs[item.GetPath()] = struct{}{}
+		count = ui.countTreeRows(item)
+	} else {
+		count = -ui.countTreeRows(item)
+		ui.collapseTreeDir(item)
+	}
+	ui.markedRows = shiftRows(ui.markedRows, row, count)
+	ui.ignoredRows = shiftRows(ui.ignoredRows, row, count)
+
+	rowOffset, columnOffset := ui.table.GetOffset()
+	ui.showDir()
+	ui.table.Select(row, 0)
+	ui.table.SetOffset(rowOffset, columnOffset)
+}
+
+// collapseTreeDir collapses the directory together with all expanded subdirectories
+func (ui *UI) collapseTreeDir(dir fs.Item) {
+	prefix := dir.GetPath() + string(filepath.Separator)
+	for path := range ui.expandedDirs {
+		if path == dir.GetPath() || strings.HasPrefix(path, prefix) {
+			delete(ui.expandedDirs, path)
+		}
+	}
+}
+
+// shiftRows moves rows following the given row by count,
+// rows which are hidden by collapsing are dropped
+func shiftRows(rows map[int]struct{}, row, count int) map[int]struct{} {
+	result := make(map[int]struct{}, len(rows))
+	for r := range rows {
+		switch {
+		case r <= row:
+			result[r] = struct{}{}
+		case count < 0 && r <= row-count:
+			continue
+		default:
+			result[r+count] = struct{}{}
+		}
+	}
+	return result
+}
+
+// handleTreeLeft collapses the selected directory or selects the parent row
+func (ui *UI) handleTreeLeft() {
+	row, _ := ui.table.GetSelection()
+	if item, ok := ui.table.GetCell(row, 0).GetReference().(fs.Item); ok &&
+		item.IsDir() && ui.isExpanded(item) && !ui.isParentRow(row) {
+		ui.expandTreeRow(row, false)
+		return
+	}
+
+	depth := ui.getTreeDepth(row)
+	if depth == 0 {
+		ui.handleLeft()
+		return
+	}
+	for r := row - 1; r >= 0; r-- {
+		if ui.getTreeDepth(r) == depth-1 {
+			ui.table.Select(r, 0)
+			return
+		}
+	}
+}
+
+// handleTreeRight expands the selected directory or selects its first item
+func (ui *UI) handleTreeRight() {
+	row, _ := ui.table.GetSelection()
+	if ui.isParentRow(row) {
+		return
+	}
+	item, ok := ui.table.GetCell(row, 0).GetReference().(fs.Item)
+	if !ok || !item.IsDir() {
+		return
+	}
+	if !ui.isExpanded(item) {
+		ui.expandTreeRow(row, true)
+		return
+	}
+	if row+1 < ui.table.GetRowCount() && ui.getTreeDepth(row+1) > ui.getTreeDepth(row) {
+		ui.table.Select(row+1, 0)
+	}
+}
+
+// isParentRow returns true if the row is the "/.." row leading to the parent directory
+func (ui *UI) isParentRow(row int) bool {
+	return row == 0 && ui.currentDirPath != ui.topDirPath
+}
+
+// getParentDir returns directory containing the item shown in the table
+func (ui *UI) getParentDir(item fs.Item) fs.Item {
+	if ui.treeView && item.GetParent() != nil {
+		return item.GetParent()
+	}
+	return ui.currentDir
+}
@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testapp"
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func getRowName(ui *UI, row int) string {
+	return ui.table.GetCell(row, 0).GetReference().(fs.Item).GetName()
+}
+
+func TestTreeViewExpandCollapse(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 't', 0))
+	assert.Equal(t, 1, ui.table.GetRowCount())
+	assert.Contains(t, ui.table.GetCell(0, 0).Text, "▸ [::b]/nested")
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRight, 0, 0))
+	assert.Equal(t, 3, ui.table.GetRowCount())
+	assert.Contains(t, ui.table.GetCell(0, 0).Text, "▾ [::b]/nested")
+	assert.Equal(t, "subnested", getRowName(ui, 1))
+	assert.Contains(t, ui.table.GetCell(1, 0).Text, "  ├─▸ [::b]/subnested")
+	assert.Contains(t, ui.table.GetCell(2, 0).Text, "  └── file2")
+
+	// moves to the first item of the expanded directory and expands it
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'l', 0))
+	row, _ := ui.table.GetSelection()
+	assert.Equal(t, 1, row)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'l', 0))
+	assert.Equal(t, 4, ui.table.GetRowCount())
+	assert.Equal(t, "file", getRowName(ui, 2))
+	assert.Contains(t, ui.table.GetCell(2, 0).Text, "  │ └── file")
+	assert.Equal(t, 2, ui.getTreeDepth(2))
+
+	// goes to the parent row and collapses it
+	ui.table.Select(2, 0)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyLeft, 0, 0))
+	row, _ = ui.table.GetSelection()
+	assert.Equal(t, 1, row)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'h', 0))
+	assert.Equal(t, 3, ui.table.GetRowCount())
+
+	// collapsing of the top directory collapses also its subdirectories
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'l', 0))
+	assert.Equal(t, 4, ui.table.GetRowCount())
+	ui.table.Select(0, 0)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'h', 0))
+	assert.Equal(t, 1, ui.table.GetRowCount())
+	assert.Empty(t, ui.expandedDirs)
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 't', 0))
+	assert.False(t, ui.treeView)
+	assert.NotContains(t, ui.table.GetCell(0, 0).Text, "▸")
+}
+
+func TestTreeViewMarks(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 't', 0))
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRight, 0, 0))
+	ui.table.Select(2, 0)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, ' ', 0))
+	assert.Contains(t, ui.markedRows, 2)
+
+	// mark of file2 is moved when subnested is expanded
+	ui.table.Select(1, 0)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRight, 0, 0))
+	assert.Equal(t, map[int]struct{}{3: {}}, ui.markedRows)
+	assert.Equal(t, "file2", getRowName(ui, 3))
+	assert.Contains(t, ui.table.GetCell(3, 0).Text, "✓")
+
+	// mark of the hidden item is dropped
+	ui.table.Select(0, 0)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyLeft, 0, 0))
+	assert.Empty(t, ui.markedRows)
+}
+
+func TestTreeViewDeleteNested(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	ui := getAnalyzedPathMockedApp(t, false, true, false)
+	ui.askBeforeDelete = false
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 't', 0))
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRight, 0, 0))
+	ui.table.Select(2, 0)
+	assert.Equal(t, "file2", getRowName(ui, 2))
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'd', 0))
+	<-ui.done
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+
+	assert.NoFileExists(t, "test_dir/nested/file2")
+	assert.DirExists(t, "test_dir/nested/subnested")
+	assert.Len(t, ui.currentDir.GetFiles()[0].GetFiles(), 1)
+
+	// nested now contains only subnested so they are folded into one row
+	assert.Equal(t, 1, ui.table.GetRowCount())
+	assert.Equal(t, "subnested", getRowName(ui, 0))
+}
+
+func TestShiftRows(t *testing.T) {
+	rows := map[int]struct{}{1: {}, 3: {}, 5: {}}
+
+	assert.Equal(t, map[int]struct{}{1: {}, 5: {}, 7: {}}, shiftRows(rows, 2, 2))
+	assert.Equal(t, map[int]struct{}{1: {}, 3: {}}, shiftRows(rows, 2, -2))
+}
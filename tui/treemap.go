@@ -0,0 +1,495 @@
+package tui
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// number of levels of the directory tree drawn in the treemap
+const treemapDepth = 2
+
+var treemapColors = []tcell.Color{
+	tcell.ColorSteelBlue,
+	tcell.ColorDarkCyan,
+	tcell.ColorOliveDrab,
+	tcell.ColorDarkGoldenrod,
+	tcell.ColorIndianRed,
+	tcell.ColorMediumPurple,
+	tcell.ColorCadetBlue,
+	tcell.ColorSienna,
+}
+
+// treemapArea is area of the treemap in fractions of screen cells
+type treemapArea struct {
+	x, y, width, height float64
+}
+
+// treemapRect is area of the screen occupied by one item of the treemap
+type treemapRect struct {
+	item                fs.Item
+	row                 int // row of the item in the table
+	x, y, width, height int
+}
+
+// squarify lays out areas for given sizes sorted in descending order,
+// so the areas are as close to squares as possible
+func squarify(sizes []float64, area treemapArea) []treemapArea {
+	result := make([]treemapArea, 0, len(sizes))
+
+	var total float64
+	for _, size := range sizes {
+		total += size
+	}
+	if total <= 0 || area.width <= 0 || area.height <= 0 {
+		return append(result, make([]treemapArea, len(sizes))...)
+	}
+
+	scale := area.width * area.height / total
+	areas := make([]float64, len(sizes))
+	for i, size := range sizes {
+		areas[i] = size * scale
+	}
+
+	for start := 0; start < len(areas); {
+		side := min(area.width, area.height)
+		end := start + 1
+		for end < len(areas) && worstRatio(areas[start:end+1], side) <= worstRatio(areas[start:end], side) {
+			end++
+		}
+
+		var rowArea float64
+		for _, a := range areas[start:end] {
+			rowArea += a
+		}
+
+		if area.width >= area.height {
+			// the row is placed as a column on the left side
+			columnWidth := rowArea / area.height
+			y := area.y
+			for _, a := range areas[start:end] {
+				height := a / columnWidth
+				result = append(result, treemapArea{area.x, y, columnWidth, height})
+				y += height
+			}
+			area.x += columnWidth
+			area.width -= columnWidth
+		} else {
+			rowHeight := rowArea / area.width
+			x := area.x
+			for _, a := range areas[start:end] {
+				width := a / rowHeight
+				result = append(result, treemapArea{x, area.y, width, rowHeight})
+				x += width
+			}
+			area.y += rowHeight
+			area.height -= rowHeight
+		}
+		start = end
+	}
+	return result
+}
+
+// worstRatio returns the worst aspect ratio of areas placed in a row along given side
+func worstRatio(areas []float64, side float64) float64 {
+	var sum, largest float64
+	smallest := math.Inf(1)
+	for _, a := range areas {
+		sum += a
+		largest = max(largest, a)
+		smallest = min(smallest, a)
+	}
+	return max(side*side*largest/(sum*sum), sum*sum/(side*side*smallest))
+}
+
+// layoutTreemap places items into the rectangle of the screen.
+// Items too small to occupy a single cell are left out.
+func layoutTreemap(items []treemapRect, sizes []int64, x, y, width, height int) []treemapRect {
+	order := make([]int, 0, len(items))
+	for i := range items {
+		if sizes[i] > 0 {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return sizes[order[i]] > sizes[order[j]]
+	})
+
+	sortedSizes := make([]float64, len(order))
+	for i, index := range order {
+		sortedSizes[i] = float64(sizes[index])
+	}
+
+	// terminal cells are about twice as high as wide
+	areas := squarify(sortedSizes, treemapArea{
+		x: float64(x), y: float64(2 * y), width: float64(width), height: float64(2 * height),
+	})
+
+	rects := make([]treemapRect, 0, len(order))
+	for i, index := range order {
+		area := areas[i]
+		x0, x1 := int(math.Round(area.x)), int(math.Round(area.x+area.width))
+		y0, y1 := int(math.Round(area.y/2)), int(math.Round((area.y+area.height)/2))
+		if x1 <= x0 || y1 <= y0 {
+			continue
+		}
+		rect := items[index]
+		rect.x, rect.y, rect.width, rect.height = x0, y0, x1-x0, y1-y0
+		rects = append(rects, rect)
+	}
+	return rects
+}
+
+// treemap draws items of the current directory as nested rectangles
+// sized by their disk usage (or apparent size)
+type treemap struct {
+	*tview.Box
+	ui    *UI
+	rects []treemapRect
+}
+
+func newTreemap(ui *UI) *treemap {
+	t := &treemap{
+		Box: tview.NewBox(),
+		ui:  ui,
+	}
+	t.SetBackgroundColor(tcell.ColorDefault)
+	return t
+}
+
+// getItems returns items shown in the table of the current directory
+func (t *treemap) getItems() []treemapRect {
+	items := make([]treemapRect, 0, t.ui.table.GetRowCount())
+	for row := 0; row < t.ui.table.GetRowCount(); row++ {
+		item, ok := t.ui.table.GetCell(row, 0).GetReference().(fs.Item)
+		if !ok || item == nil || t.ui.isParentItem(item) {
+			continue
+		}
+		if _, ignored := t.ui.ignoredRows[row]; ignored {
+			continue
+		}
+		// items nested in the expanded directories are part of their parent's rectangle
+		if t.ui.getTreeDepth(row) > 0 {
+			continue
+		}
+		items = append(items, treemapRect{item: item, row: row})
+	}
+	return items
+}
+
+func (t *treemap) getSize(item fs.Item) int64 {
+	if t.ui.ShowApparentSize {
+		return item.GetSize()
+	}
+	return item.GetUsage()
+}
+
+// Draw draws the treemap of the current directory
+func (t *treemap) Draw(screen tcell.Screen) {
+	t.DrawForSubclass(screen, t)
+	x, y, width, height := t.GetInnerRect()
+
+	t.rects = nil
+	if t.ui.currentDir == nil {
+		return
+	}
+
+	items := t.getItems()
+	sizes := make([]int64, len(items))
+	for i, item := range items {
+		sizes[i] = t.getSize(item.item)
+	}
+	t.rects = layoutTreemap(items, sizes, x, y, width, height)
+
+	if len(t.rects) == 0 {
+		tview.Print(screen, "No items to display", x, y+height/2, width, tview.AlignCenter, tcell.ColorDefault)
+		return
+	}
+
+	selectedRow, _ := t.ui.table.GetSelection()
+	for i, rect := range t.rects {
+		style := tcell.StyleDefault
+		if t.ui.UseColors {
+			style = style.Background(treemapColors[i%len(treemapColors)]).Foreground(tcell.ColorWhite)
+		}
+		if rect.row == selectedRow {
+			style = t.ui.getSelectedStyle()
+		}
+		t.drawRect(screen, rect, style, 1)
+	}
+}
+
+// drawRect draws framed rectangle of the item with its name and size
+// and items of the directory inside of it
+func (t *treemap) drawRect(screen tcell.Screen, rect treemapRect, style tcell.Style, depth int) {
+	for cy := rect.y; cy < rect.y+rect.height; cy++ {
+		for cx := rect.x; cx < rect.x+rect.width; cx++ {
+			screen.SetContent(cx, cy, ' ', nil, style)
+		}
+	}
+
+	label := rect.item.GetName()
+	if rect.item.IsDir() {
+		label = "/" + label
+	}
+	label += " " + t.formatSize(t.getSize(rect.item))
+
+	if rect.width < 3 || rect.height < 2 {
+		printTreemapLabel(screen, label, rect.x, rect.y, rect.width, style)
+		return
+	}
+
+	t.drawFrame(screen, rect, style)
+	printTreemapLabel(screen, label, rect.x+1, rect.y, rect.width-2, style)
+
+	if depth >= treemapDepth || !rect.item.IsDir() || rect.width < 4 || rect.height < 3 {
+		return
+	}
+
+	unlock := rect.item.RLock()
+	defer unlock()
+
+	files := rect.item.GetFiles()
+	items := make([]treemapRect, len(files))
+	sizes := make([]int64, len(files))
+	for i, file := range files {
+		items[i] = treemapRect{item: file, row: -1}
+		sizes[i] = t.getSize(file)
+	}
+	for _, nested := range layoutTreemap(items, sizes, rect.x+1, rect.y+1, rect.width-2, rect.height-2) {
+		// nested items too small to be framed would be only noise
+		if nested.width >= 3 && nested.height >= 2 {
+			t.drawRect(screen, nested, style, depth+1)
+		}
+	}
+}
+
+func (t *treemap) drawFrame(screen tcell.Screen, rect treemapRect, style tcell.Style) {
+	right, bottom := rect.x+rect.width-1, rect.y+rect.height-1
+	for cx := rect.x + 1; cx < right; cx++ {
+		screen.SetContent(cx, rect.y, tview.Borders.Horizontal, nil, style)
+		screen.SetContent(cx, bottom, tview.Borders.Horizontal, nil, style)
+	}
+	for cy := rect.y + 1; cy < bottom; cy++ {
+		screen.SetContent(rect.x, cy, tview.Borders.Vertical, nil, style)
+		screen.SetContent(right, cy, tview.Borders.Vertical, nil, style)
+	}
+	screen.SetContent(rect.x, rect.y, tview.Borders.TopLeft, nil, style)
+	screen.SetContent(right, rect.y, tview.Borders.TopRight, nil, style)
+	screen.SetContent(rect.x, bottom, tview.Borders.BottomLeft, nil, style)
+	screen.SetContent(right, bottom, tview.Borders.BottomRight, nil, style)
+}
+
+func (t *treemap) formatSize(size int64) string {
+	if t.ui.UseSIPrefix {
+		return formatWithDecPrefix(size, "")
+	}
+	return formatWithBinPrefix(float64(size), "")
+}
+
+func printTreemapLabel(screen tcell.Screen, label string, x, y, width int, style tcell.Style) {
+	for _, r := range label {
+		if width <= 0 {
+			return
+		}
+		screen.SetContent(x, y, r, nil, style)
+		x++
+		width--
+	}
+}
+
+// rectAt returns rectangle of the treemap at given position of the screen
+func (t *treemap) rectAt(x, y int) (treemapRect, bool) {
+	for _, rect := range t.rects {
+		if x >= rect.x && x < rect.x+rect.width && y >= rect.y && y < rect.y+rect.height {
+			return rect, true
+		}
+	}
+	return treemapRect{}, false
+}
+
+// neighbour returns the nearest rectangle in given direction from the rectangle of the selected row
+func (t *treemap) neighbour(row, dx, dy int) (treemapRect, bool) {
+	var current treemapRect
+	found := false
+	for _, rect := range t.rects {
+		if rect.row == row {
+			current, found = rect, true
+		}
+	}
+	if !found {
+		return t.first()
+	}
+
+	// centers are doubled to stay in integers
+	cx, cy := 2*current.x+current.width, 2*current.y+current.height
+	best, bestDistance := treemapRect{}, math.MaxInt
+	for _, rect := range t.rects {
+		var gap, offset int
+		switch {
+		case dx > 0 && rect.x >= current.x+current.width:
+			gap, offset = rect.x-current.x-current.width, 2*rect.y+rect.height-cy
+		case dx < 0 && rect.x+rect.width <= current.x:
+			gap, offset = current.x-rect.x-rect.width, 2*rect.y+rect.height-cy
+		case dy > 0 && rect.y >= current.y+current.height:
+			gap, offset = rect.y-current.y-current.height, 2*rect.x+rect.width-cx
+		case dy < 0 && rect.y+rect.height <= current.y:
+			gap, offset = current.y-rect.y-rect.height, 2*rect.x+rect.width-cx
+		default:
+			continue
+		}
+		distance := 4*gap + abs(offset)
+		if distance < bestDistance {
+			best, bestDistance = rect, distance
+		}
+	}
+	return best, bestDistance != math.MaxInt
+}
+
+// first returns rectangle of the biggest item
+func (t *treemap) first() (treemapRect, bool) {
+	if len(t.rects) == 0 {
+		return treemapRect{}, false
+	}
+	return t.rects[0], true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func (ui *UI) toggleTreemap() {
+	if ui.pages.HasPage("treemap") {
+		ui.pages.RemovePage("treemap")
+		ui.app.SetFocus(ui.table)
+		return
+	}
+	if ui.currentDir == nil {
+		return
+	}
+
+	ui.treemap = newTreemap(ui)
+
+	var grid *tview.Grid
+	if ui.headerHidden {
+		grid = tview.NewGrid().SetRows(1, 0, 1).SetColumns(0)
+		grid.AddItem(ui.currentDirLabel, 0, 0, 1, 1, 0, 0, false).
+			AddItem(ui.treemap, 1, 0, 1, 1, 0, 0, false).
+			AddItem(ui.footer, 2, 0, 1, 1, 0, 0, false)
+	} else {
+		grid = tview.NewGrid().SetRows(1, 1, 0, 1).SetColumns(0)
+		grid.AddItem(ui.header, 0, 0, 1, 1, 0, 0, false).
+			AddItem(ui.currentDirLabel, 1, 0, 1, 1, 0, 0, false).
+			AddItem(ui.treemap, 2, 0, 1, 1, 0, 0, false).
+			AddItem(ui.footer, 3, 0, 1, 1, 0, 0, false)
+	}
+	ui.pages.AddPage("treemap", grid, true, true)
+	ui.app.SetFocus(ui.table)
+
+	row, _ := ui.table.GetSelection()
+	if item, ok := ui.table.GetCell(row, 0).GetReference().(fs.Item); !ok || ui.isParentItem(item) {
+		ui.selectBiggestTreemapItem()
+	}
+}
+
+// handleTreemap moves the selection across rectangles of the treemap and opens directories
+func (ui *UI) handleTreemap(key *tcell.EventKey) *tcell.EventKey {
+	if ui.keymap.action(key) == keyActionTreemap {
+		ui.toggleTreemap()
+		return nil
+	}
+	if !ui.pages.HasPage("treemap") || ui.currentDir == nil {
+		return key
+	}
+
+	row, _ := ui.table.GetSelection()
+	action := ui.keymap.action(key)
+	dx, dy := 0, 0
+	switch {
+	case key.Key() == tcell.KeyLeft || action == keyActionGoToParent:
+		dx = -1
+	case key.Key() == tcell.KeyRight || action == keyActionGoInto:
+		dx = 1
+	case key.Key() == tcell.KeyUp || key.Rune() == 'k':
+		dy = -1
+	case key.Key() == tcell.KeyDown || key.Rune() == 'j':
+		dy = 1
+	case key.Key() == tcell.KeyEnter:
+		ui.treemapItemSelected(row)
+		return nil
+	case key.Key() == tcell.KeyBackspace || key.Key() == tcell.KeyBackspace2:
+		if ui.currentDirPath != ui.topDirPath {
+			ui.fileItemSelected(0, 0)
+		}
+		return nil
+	default:
+		return key
+	}
+
+	if rect, ok := ui.treemap.neighbour(row, dx, dy); ok {
+		ui.table.Select(rect.row, 0)
+	}
+	return nil
+}
+
+func (ui *UI) onTreemapMouse(event *tcell.EventMouse, action tview.MouseAction) (*tcell.EventMouse, tview.MouseAction) {
+	// nolint: exhaustive // Why: we don't need to handle all mouse events
+	switch action {
+	case tview.MouseLeftClick, tview.MouseLeftDoubleClick:
+		rect, ok := ui.treemap.rectAt(event.Position())
+		if !ok {
+			return nil, action
+		}
+		ui.table.Select(rect.row, 0)
+		if action == tview.MouseLeftDoubleClick {
+			ui.treemapItemSelected(rect.row)
+		}
+		return nil, action
+	}
+	return event, action
+}
+
+// isParentItem returns true if the item is the parent directory referenced by the ".." row.
+// Paths are compared as stored directories load new instance of the parent on every call
+// and the row references the nearest shown ancestor when paths are collapsed.
+func (ui *UI) isParentItem(item fs.Item) bool {
+	path, currentPath := item.GetPath(), ui.currentDir.GetPath()
+	return len(path) < len(currentPath) && strings.HasPrefix(currentPath, path)
+}
+
+// treemapItemSelected opens directory in given row of the table
+// and selects the biggest item in it
+func (ui *UI) treemapItemSelected(row int) {
+	item, ok := ui.table.GetCell(row, 0).GetReference().(fs.Item)
+	if !ok || item == nil || !item.IsDir() || ui.isParentItem(item) {
+		return
+	}
+	ui.fileItemSelected(row, 0)
+	ui.selectBiggestTreemapItem()
+}
+
+// selectBiggestTreemapItem selects the biggest item of the current directory
+// so the selection is visible in the treemap
+func (ui *UI) selectBiggestTreemapItem() {
+	items := ui.treemap.getItems()
+	var (
+		biggest fs.Item
+		row     int
+	)
+	for _, item := range items {
+		if biggest == nil || ui.treemap.getSize(item.item) > ui.treemap.getSize(biggest) {
+			biggest, row = item.item, item.row
+		}
+	}
+	if biggest != nil {
+		ui.table.Select(row, 0)
+	}
+}
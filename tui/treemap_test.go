@@ -0,0 +1,237 @@
+package tui
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testapp"
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func TestSquarify(t *testing.T) {
+	sizes := []float64{6, 6, 4, 3, 2, 2, 1}
+	areas := squarify(sizes, treemapArea{width: 6, height: 4})
+
+	assert.Len(t, areas, len(sizes))
+	for i, area := range areas {
+		assert.InDelta(t, sizes[i], area.width*area.height, 0.0001)
+		assert.GreaterOrEqual(t, area.x, 0.0)
+		assert.GreaterOrEqual(t, area.y, 0.0)
+		assert.LessOrEqual(t, area.x+area.width, 6.0001)
+		assert.LessOrEqual(t, area.y+area.height, 4.0001)
+	}
+	// the first row contains two squares of the biggest items
+	assert.Equal(t, treemapArea{0, 0, 3, 2}, areas[0])
+	assert.Equal(t, treemapArea{0, 2, 3, 2}, areas[1])
+
+	assert.Equal(t, make([]treemapArea, 2), squarify([]float64{0, 0}, treemapArea{width: 6, height: 4}))
+}
+
+func TestLayoutTreemap(t *testing.T) {
+	items := []treemapRect{{row: 0}, {row: 1}, {row: 2}, {row: 3}}
+	rects := layoutTreemap(items, []int64{10, 30, 0, 60}, 0, 0, 20, 10)
+
+	// empty items are left out, the rest is sorted by size
+	assert.Len(t, rects, 3)
+	assert.Equal(t, 3, rects[0].row)
+	assert.Equal(t, 1, rects[1].row)
+	assert.Equal(t, 0, rects[2].row)
+
+	area := 0
+	covered := make(map[[2]int]bool)
+	for _, rect := range rects {
+		area += rect.width * rect.height
+		for x := rect.x; x < rect.x+rect.width; x++ {
+			for y := rect.y; y < rect.y+rect.height; y++ {
+				assert.False(t, covered[[2]int{x, y}])
+				covered[[2]int{x, y}] = true
+			}
+		}
+	}
+	assert.Equal(t, 20*10, area)
+}
+
+func TestTreemap(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, false, false, false, false, false)
+	ui.done = make(chan struct{})
+	err := ui.AnalyzePath("test_dir", nil)
+	assert.Nil(t, err)
+	<-ui.done
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'w', 0))
+	assert.True(t, ui.pages.HasPage("treemap"))
+
+	ui.treemap.SetRect(0, 0, 40, 10)
+	ui.treemap.Draw(simScreen)
+	assert.Len(t, ui.treemap.rects, 1)
+	assert.Equal(t, "nested", ui.treemap.rects[0].item.GetName())
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyEnter, 0, 0))
+	assert.Equal(t, "nested", ui.currentDir.GetName())
+
+	ui.treemap.Draw(simScreen)
+	assert.Len(t, ui.treemap.rects, 2)
+	row, _ := ui.table.GetSelection()
+	assert.Equal(t, ui.treemap.rects[0].row, row)
+
+	// selection moves to the other rectangle and back
+	first := ui.table.GetCell(row, 0).GetReference().(fs.Item)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRight, 0, 0))
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'j', 0))
+	row, _ = ui.table.GetSelection()
+	assert.NotEqual(t, first, ui.table.GetCell(row, 0).GetReference())
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyLeft, 0, 0))
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'k', 0))
+	row, _ = ui.table.GetSelection()
+	assert.Equal(t, first, ui.table.GetCell(row, 0).GetReference())
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyBackspace2, 0, 0))
+	assert.Equal(t, "test_dir", ui.currentDir.GetName())
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'w', 0))
+	assert.False(t, ui.pages.HasPage("treemap"))
+}
+
+func TestTreemapMouse(t *testing.T) {
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, true, false, false, false, false)
+
+	dir := &analyze.Dir{
+		File: &analyze.File{
+			Name: "test_dir",
+		},
+		BasePath: ".",
+	}
+	nested := &analyze.Dir{
+		File: &analyze.File{
+			Name:   "nested",
+			Usage:  300,
+			Parent: dir,
+		},
+	}
+	file := &analyze.File{
+		Name:   "file",
+		Usage:  100,
+		Parent: dir,
+	}
+	dir.AddFile(nested)
+	dir.AddFile(file)
+
+	ui.topDir = dir
+	ui.topDirPath = dir.GetPath()
+	ui.currentDir = dir
+	ui.showDir()
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'w', 0))
+	ui.treemap.SetRect(0, 0, 40, 10)
+	ui.treemap.Draw(simScreen)
+	assert.Len(t, ui.treemap.rects, 2)
+
+	fileRect := ui.treemap.rects[1]
+	assert.Equal(t, file, fileRect.item)
+
+	ui.onMouse(tcell.NewEventMouse(fileRect.x, fileRect.y, tcell.Button1, 0), tview.MouseLeftClick)
+	row, _ := ui.table.GetSelection()
+	assert.Equal(t, fileRect.row, row)
+
+	nestedRect := ui.treemap.rects[0]
+	ui.onMouse(tcell.NewEventMouse(nestedRect.x, nestedRect.y, tcell.Button1, 0), tview.MouseLeftDoubleClick)
+	assert.Equal(t, nested, ui.currentDir)
+}
+
+func TestTreemapOfStoredDir(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, false, false, false, false, false)
+	ui.SetAnalyzer(analyze.CreateStoredAnalyzer(t.TempDir()))
+	ui.done = make(chan struct{})
+	err := ui.AnalyzePath("test_dir", nil)
+	assert.Nil(t, err)
+	<-ui.done
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'w', 0))
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyEnter, 0, 0))
+	assert.Equal(t, "nested", ui.currentDir.GetName())
+
+	// parent directory loaded from the storage is not part of the treemap
+	ui.treemap.SetRect(0, 0, 40, 10)
+	ui.treemap.Draw(simScreen)
+	assert.Len(t, ui.treemap.rects, 2)
+	for _, rect := range ui.treemap.rects {
+		assert.NotEqual(t, "test_dir", rect.item.GetPath())
+	}
+
+	// the ".." row doesn't open the parent directory
+	ui.treemapItemSelected(0)
+	assert.Equal(t, "nested", ui.currentDir.GetName())
+}
+
+func TestTreemapWithReboundKeys(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	keymap, err := ParseKeymap(map[string][]string{
+		"go-into":      {"L"},
+		"go-to-parent": {"H"},
+	})
+	assert.Nil(t, err)
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, false, false, false, false, false)
+	ui.SetKeymap(keymap)
+	ui.done = make(chan struct{})
+	err = ui.AnalyzePath("test_dir", nil)
+	assert.Nil(t, err)
+	<-ui.done
+	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
+		f()
+	}
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'w', 0))
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyEnter, 0, 0))
+	ui.treemap.SetRect(0, 0, 40, 10)
+	ui.treemap.Draw(simScreen)
+	assert.Len(t, ui.treemap.rects, 2)
+	first, _ := ui.table.GetSelection()
+
+	// rectangles are laid out side by side
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'l', 0))
+	row, _ := ui.table.GetSelection()
+	assert.Equal(t, first, row)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'L', 0))
+	row, _ = ui.table.GetSelection()
+	assert.NotEqual(t, first, row)
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'H', 0))
+	row, _ = ui.table.GetSelection()
+	assert.Equal(t, first, row)
+}
@@ -0,0 +1,144 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+// number of the newest snapshots shown in the usage trend
+const trendSnapshotCount = 20
+
+var (
+	sparklineChars      = []rune("▁▂▃▄▅▆▇█")
+	sparklineASCIIChars = []rune("_.-:=+*#")
+)
+
+// sparkline returns one character per value scaled between the lowest and the highest value
+func sparkline(values []int64, chars []rune) string {
+	if len(values) == 0 {
+		return ""
+	}
+	low, high := values[0], values[0]
+	for _, value := range values {
+		low = min(low, value)
+		high = max(high, value)
+	}
+
+	var line strings.Builder
+	for _, value := range values {
+		index := 0
+		if high > low {
+			index = int((value - low) * int64(len(chars)-1) / (high - low))
+		}
+		line.WriteRune(chars[index])
+	}
+	return line.String()
+}
+
+func (ui *UI) showTrend() {
+	if ui.currentDir == nil {
+		return
+	}
+	row, column := ui.table.GetSelection()
+	selectedItem, ok := ui.table.GetCell(row, column).GetReference().(fs.Item)
+	if !ok || selectedItem == nil {
+		selectedItem = ui.currentDir
+	}
+	if !selectedItem.IsDir() {
+		ui.showErr("Usage trend is recorded only for directories", nil)
+		return
+	}
+	storedDir, ok := selectedItem.(*analyze.StoredDir)
+	if !ok {
+		ui.showErr("Usage trend is available only with persistent storage (--use-storage)", nil)
+		return
+	}
+
+	storage := storedDir.GetStorage()
+	if !storage.IsOpen() {
+		closeFn := storage.Open()
+		defer closeFn()
+	}
+	history, err := storage.GetUsageHistory(selectedItem.GetPath(), trendSnapshotCount)
+	if err != nil {
+		ui.showErr("Error reading snapshots", err)
+		return
+	}
+
+	text := tview.NewTextView().SetDynamicColors(true)
+	text.SetBorder(true).SetBorderPadding(1, 1, 2, 2)
+	text.SetBorderColor(tcell.ColorDefault)
+	text.SetTitle(" Usage trend ")
+	text.SetScrollable(true)
+	text.SetText(ui.formatTrend(selectedItem.GetPath(), history))
+
+	height := len(history) + 8
+	if len(history) == 0 {
+		height = 7
+	}
+	if _, screenHeight := ui.screen.Size(); height > screenHeight {
+		height = screenHeight
+	}
+
+	ui.pages.AddPage("trend", modal(text, 80, height), true, true)
+	ui.app.SetFocus(text)
+}
+
+func (ui *UI) formatTrend(path string, history []analyze.SnapshotUsage) string {
+	var content strings.Builder
+	content.WriteString("[::b]" + tview.Escape(path) + "[::-]\n\n")
+
+	if len(history) == 0 {
+		content.WriteString("No snapshots of this directory in the storage")
+		return content.String()
+	}
+
+	values := make([]int64, 0, len(history))
+	for _, usage := range history {
+		if ui.ShowApparentSize {
+			values = append(values, usage.Size)
+		} else {
+			values = append(values, usage.Usage)
+		}
+	}
+
+	chars := sparklineChars
+	if ui.useOldSizeBar {
+		chars = sparklineASCIIChars
+	}
+	content.WriteString(fmt.Sprintf(
+		"Last %d snapshots: %s\n\n", len(history), sparkline(values, chars),
+	))
+
+	// snapshots named by the time of the analysis don't need the name shown
+	names := make([]string, len(history))
+	nameWidth := 0
+	for i, usage := range history {
+		if usage.Snapshot.Name != usage.Snapshot.Time.Format(analyze.SnapshotTimeFormat) {
+			names[i] = usage.Snapshot.Name
+			nameWidth = max(nameWidth, len(names[i]))
+		}
+	}
+
+	for i, usage := range history {
+		content.WriteString(usage.Snapshot.Time.Format("2006-01-02 15:04"))
+		if nameWidth > 0 {
+			content.WriteString(fmt.Sprintf("  %-*s", nameWidth, tview.Escape(names[i])))
+		}
+		content.WriteString(fmt.Sprintf(" %15s", ui.formatSize(values[i], false, true)))
+		if i > 0 {
+			delta := values[i] - values[i-1]
+			content.WriteString(fmt.Sprintf(
+				" %s%16s[-::-]", ui.getDeltaColor(delta), ui.formatDelta(delta, false),
+			))
+		}
+		content.WriteString("\n")
+	}
+	return strings.TrimSuffix(content.String(), "\n")
+}
@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dundee/gdu/v5/internal/testapp"
+	"github.com/dundee/gdu/v5/internal/testdir"
+	"github.com/dundee/gdu/v5/pkg/analyze"
+	"github.com/dundee/gdu/v5/pkg/fs"
+)
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "", sparkline(nil, sparklineChars))
+	assert.Equal(t, "▁▁▁", sparkline([]int64{5, 5, 5}, sparklineChars))
+	assert.Equal(t, "▁▄█▁", sparkline([]int64{0, 50, 100, 0}, sparklineChars))
+	assert.Equal(t, "_#", sparkline([]int64{10, 20}, sparklineASCIIChars))
+}
+
+func TestShowTrendWithoutStorage(t *testing.T) {
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, true, true, true, false, false)
+	ui.currentDir = &analyze.Dir{File: &analyze.File{Name: "test_dir"}}
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'T', 0))
+
+	assert.False(t, ui.pages.HasPage("trend"))
+	assert.True(t, ui.pages.HasPage("error"))
+}
+
+func TestShowTrend(t *testing.T) {
+	fin := testdir.CreateTestDir()
+	defer fin()
+
+	storagePath := t.TempDir()
+	var dir *analyze.StoredDir
+	for _, name := range []string{"first", "second"} {
+		a := analyze.CreateStoredAnalyzer(storagePath)
+		a.SetSnapshotName(name)
+		dir = a.AnalyzeDir(
+			"test_dir", func(_, _ string) bool { return false }, false,
+		).(*analyze.StoredDir)
+		a.GetDone().Wait()
+		dir.UpdateStats(make(fs.HardLinkedItems))
+
+		err := os.WriteFile("test_dir/nested/"+name, make([]byte, 10000), 0o600)
+		assert.NoError(t, err)
+	}
+
+	simScreen := testapp.CreateSimScreen()
+	defer simScreen.Fini()
+
+	app := testapp.CreateMockedApp(true)
+	ui := CreateUI(app, simScreen, &bytes.Buffer{}, true, true, true, false, false)
+	ui.ShowApparentSize = true
+	ui.topDir = dir
+	ui.topDirPath = dir.GetPath()
+	ui.currentDir = dir
+	ui.showDir()
+	ui.table.Select(0, 0)
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'T', 0))
+	assert.True(t, ui.pages.HasPage("trend"))
+
+	_, page := ui.pages.GetFrontPage()
+	text := page.(*tview.Flex).GetItem(1).(*tview.Flex).GetItem(1).(*tview.TextView).GetText(true)
+	assert.Contains(t, text, "test_dir/nested")
+	assert.Contains(t, text, "Last 2 snapshots: ▁█")
+	assert.Contains(t, text, "first")
+	assert.Contains(t, text, "+9.8 KiB")
+
+	ui.keyPressed(tcell.NewEventKey(tcell.KeyRune, 'T', 0))
+	assert.False(t, ui.pages.HasPage("trend"))
+}
@@ -16,9 +16,12 @@ import (
 	"github.com/dundee/gdu/v5/internal/common"
 	"github.com/dundee/gdu/v5/pkg/analyze"
 	"github.com/dundee/gdu/v5/pkg/device"
+	"github.com/dundee/gdu/v5/pkg/diff"
 	"github.com/dundee/gdu/v5/pkg/fs"
 	"github.com/dundee/gdu/v5/pkg/remove"
+	"github.com/dundee/gdu/v5/pkg/theme"
 	"github.com/dundee/gdu/v5/pkg/timefilter"
+	"github.com/dundee/gdu/v5/report"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
@@ -31,6 +34,7 @@ type UI struct {
 	currentDir fs.Item
 	topDir     fs.Item
 	getter     device.DevicesInfoGetter
+	metadata   *report.Metadata
 	*common.UI
 	grid                    *tview.Grid
 	header                  *tview.TextView
@@ -66,6 +70,9 @@ type UI struct {
 	defaultSortBy           string
 	defaultSortOrder        string
 	exportName              string
+	exportFormat            string
+	exportScope             int
+	exportExcludeIgnored    bool
 	devices                 []*device.Device
 	selectedTextColor       tcell.Color
 	selectedBackgroundColor tcell.Color
@@ -83,10 +90,29 @@ type UI struct {
 	noDelete                bool
 	noSpawnShell            bool
 	deleteInBackground      bool
+	diffTable               *tview.Table
+	treemap                 *treemap
+	search                  *searchView
+	diffDir                 *diff.Item
+	hideUnchanged           bool
 	timeFilter              *timefilter.TimeFilter
 	timeFilterLoc           *time.Location
 	noDeleteWithFilter      bool
 	collapsePath            bool
+	treeView                bool
+	expandedDirs            map[string]struct{}
+	treeDepths              map[int]int
+	searchQuery             string
+	searchMode              string
+	previousDirPath         string
+	bookmarks               map[string]string
+	saveBookmarks           func(map[string]string) error
+	deleteToTrash           bool
+	jobs                    map[string]*backgroundJob
+	statusWorkerOnce        sync.Once
+	keymap                  *Keymap
+	inputPages              map[string]struct{}
+	theme                   *theme.Theme
 }
 
 type deleteQueueItem struct {
@@ -140,7 +166,14 @@ func CreateUI(
 		defaultSortOrder:        "desc",
 		ignoredRows:             make(map[int]struct{}),
 		markedRows:              make(map[int]struct{}),
+		expandedDirs:            make(map[string]struct{}),
+		jobs:                    make(map[string]*backgroundJob),
+		treeDepths:              make(map[int]int),
+		bookmarks:               make(map[string]string),
+		keymap:                  DefaultKeymap(),
+		inputPages:              make(map[string]struct{}),
 		exportName:              "export.json",
+		exportFormat:            report.FormatJSON,
 		noDelete:                false,
 		noSpawnShell:            false,
 		deleteQueue:             make(chan deleteQueueItem, 1000),
@@ -173,15 +206,7 @@ func CreateUI(
 	ui.table.SetBackgroundColor(tcell.ColorDefault)
 	ui.table.SetSelectedFunc(ui.fileItemSelected)
 
-	if ui.UseColors {
-		ui.table.SetSelectedStyle(tcell.Style{}.
-			Foreground(ui.selectedTextColor).
-			Background(ui.selectedBackgroundColor).Bold(true))
-	} else {
-		ui.table.SetSelectedStyle(tcell.Style{}.
-			Foreground(tcell.ColorWhite).
-			Background(tcell.ColorGray).Bold(true))
-	}
+	ui.table.SetSelectedStyle(ui.getSelectedStyle())
 
 	ui.footerLabel = tview.NewTextView().SetDynamicColors(true)
 	ui.footerLabel.SetTextColor(tcell.GetColor(ui.footerTextColor))
@@ -202,6 +227,18 @@ func CreateUI(
 	return ui
 }
 
+// getSelectedStyle returns style of the selected table row
+func (ui *UI) getSelectedStyle() tcell.Style {
+	if ui.UseColors {
+		return tcell.Style{}.
+			Foreground(ui.selectedTextColor).
+			Background(ui.selectedBackgroundColor).Bold(true)
+	}
+	return tcell.Style{}.
+		Foreground(tcell.ColorWhite).
+		Background(tcell.ColorGray).Bold(true)
+}
+
 // createGrid creates the main grid layout
 func (ui *UI) createGrid() {
 	if ui.headerHidden {
@@ -290,6 +327,17 @@ func (ui *UI) SetDeleteInParallel() {
 	ui.remover = remove.ItemFromDirParallel
 }
 
+// SetDeleteToTrash sets the flag to move deleted items to the trash instead of removing them
+func (ui *UI) SetDeleteToTrash() {
+	ui.deleteToTrash = true
+	ui.remover = remove.ItemToTrash
+}
+
+// SetKeymap sets key bindings of the actions
+func (ui *UI) SetKeymap(keymap *Keymap) {
+	ui.keymap = keymap
+}
+
 // StartUILoop starts tview application
 func (ui *UI) StartUILoop() error {
 	go func() {
@@ -352,7 +400,7 @@ func (ui *UI) SetDeleteInBackground() {
 	for i := 0; i < ui.deleteWorkersCount; i++ {
 		go ui.deleteWorker()
 	}
-	go ui.updateStatusWorker()
+	ui.runStatusWorker()
 }
 
 func (ui *UI) resetSorting() {
@@ -469,9 +517,7 @@ func (ui *UI) confirmDeletion(shouldEmpty bool) {
 			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
 				ui.pages.RemovePage("confirm")
 			})
-		if !ui.UseColors {
-			modal.SetBackgroundColor(tcell.ColorGray)
-		}
+		ui.styleModal(modal, tview.Styles.ContrastBackgroundColor)
 		ui.pages.AddPage("confirm", modal, true, true)
 		return
 	}
@@ -490,7 +536,7 @@ func (ui *UI) confirmDeletionSelected(shouldEmpty bool) {
 	if shouldEmpty {
 		action = "empty"
 	} else {
-		action = "delete"
+		action = ui.getDeleteAction()
 	}
 	modal := tview.NewModal().
 		SetText(
@@ -512,12 +558,8 @@ func (ui *UI) confirmDeletionSelected(shouldEmpty bool) {
 			ui.pages.RemovePage("confirm")
 		})
 
-	if !ui.UseColors {
-		modal.SetBackgroundColor(tcell.ColorGray)
-	} else {
-		modal.SetBackgroundColor(tcell.ColorBlack)
-	}
 	modal.SetBorderColor(tcell.ColorDefault)
+	ui.styleModal(modal, tcell.ColorBlack)
 
 	ui.pages.AddPage("confirm", modal, true, true)
 }
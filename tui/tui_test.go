@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -97,7 +98,7 @@ func TestHelp(t *testing.T) {
 
 	b, _, _ := simScreen.GetContents()
 
-	cells := b[507 : 507+9]
+	cells := b[456 : 456+9]
 
 	text := []byte("directory")
 	for i, r := range cells {
@@ -118,7 +119,7 @@ func TestHelpBw(t *testing.T) {
 
 	b, _, _ := simScreen.GetContents()
 
-	cells := b[507 : 507+9]
+	cells := b[456 : 456+9]
 
 	text := []byte("directory")
 	for i, r := range cells {
@@ -844,18 +845,22 @@ func getAnalyzedPathMockedApp(t *testing.T, useColors, apparentSize, mockedAnaly
 	app := testapp.CreateMockedApp(true)
 	ui := CreateUI(app, simScreen, &bytes.Buffer{}, useColors, apparentSize, false, false, false)
 
+	path := "test_dir"
 	if mockedAnalyzer {
 		ui.Analyzer = &testanalyze.MockedAnalyzer{}
+	} else {
+		// paths are made absolute before the analysis
+		var err error
+		path, err = filepath.Abs(path)
+		assert.Nil(t, err)
 	}
 	ui.done = make(chan struct{})
-	err := ui.AnalyzePath("test_dir", nil)
+	err := ui.AnalyzePath(path, nil)
 	assert.Nil(t, err)
 
 	<-ui.done // wait for analyzer
 
-	for _, f := range ui.app.(*testapp.MockedApp).GetUpdateDraws() {
-		f()
-	}
+	drawUpdates(ui)
 
 	assert.Equal(t, "test_dir", ui.currentDir.GetName())
 